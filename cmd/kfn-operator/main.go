@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,6 +16,12 @@ import (
 	clientset "github.com/dajac/kfn/pkg/client/clientset/versioned"
 	informers "github.com/dajac/kfn/pkg/client/informers/externalversions"
 	controller "github.com/dajac/kfn/pkg/controller/function"
+	pipelinecontroller "github.com/dajac/kfn/pkg/controller/pipeline"
+	"github.com/dajac/kfn/pkg/kafka"
+	"github.com/dajac/kfn/pkg/registry"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/dajac/kfn/pkg/topology"
+	"github.com/dajac/kfn/pkg/webhook"
 
 	customflag "github.com/dajac/kfn/pkg/flag"
 )
@@ -23,24 +30,48 @@ var (
 	masterURL  string
 	kubeconfig string
 
+	httpAddress string
+
+	webhookAddress  string
+	webhookCertFile string
+	webhookKeyFile  string
+
+	builtinImage  string
+	imageResolver string
+
+	lagCheckTimeout time.Duration
+
 	kafkaBoostrap         string
 	functionDefaultConfig customflag.Config
 	consumerDefaultConfig customflag.Config
 	producerDefaultConfig customflag.Config
+	variables             customflag.Properties
 )
 
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	flag.StringVar(&httpAddress, "http-address", ":8080", "The address of the HTTP server exposing the topology of the Functions. Empty to disable it.")
+
+	flag.StringVar(&webhookAddress, "webhook-address", "", "The address of the HTTPS server of the validating admission webhook of the Functions. Empty to disable it.")
+	flag.StringVar(&webhookCertFile, "webhook-cert-file", "/etc/kfn-operator/tls/tls.crt", "The certificate of the validating admission webhook.")
+	flag.StringVar(&webhookKeyFile, "webhook-key-file", "/etc/kfn-operator/tls/tls.key", "The private key of the validating admission webhook.")
 
 	functionDefaultConfig = customflag.Config{}
 	consumerDefaultConfig = customflag.Config{}
 	producerDefaultConfig = customflag.Config{}
+	variables = customflag.Properties{}
+
+	flag.StringVar(&builtinImage, "builtin-image", render.DefaultBuiltinImage, "The stock invoker image running the Functions written with builtin expressions.")
+	flag.StringVar(&imageResolver, "image-resolver", "registry", "How the Functions pinning their image digest resolve it: registry, which queries the registry of the image, or fake, which derives the digest from the tag for local clusters.")
+
+	flag.DurationVar(&lagCheckTimeout, "lag-check-timeout", 10*time.Second, "The timeout of the requests measuring the lag of the consumer groups during the canary rollouts.")
 
 	flag.StringVar(&kafkaBoostrap, "kafka", "", "The address of the Kafka cluster.")
 	flag.Var(&functionDefaultConfig, "function", "Set default configuration for all functions (key:value).")
 	flag.Var(&consumerDefaultConfig, "consumer", "Set default configuration for all functions (key:value).")
 	flag.Var(&producerDefaultConfig, "producer", "Set default configuration for all functions (key:value).")
+	flag.Var(&variables, "variable", "Set a variable which can be used as ${name} in the configuration of the functions (name=value).")
 }
 
 func main() {
@@ -69,25 +100,82 @@ func main() {
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
 	kfnInformerFactory := informers.NewSharedInformerFactory(kfnClient, time.Second*30)
 
-	functionDefaultConfig := controller.FunctionDefaultConfig{
+	functionDefaultConfig := render.FunctionDefaultConfig{
 		KafkaBoostrap: kafkaBoostrap,
 		Function:      functionDefaultConfig,
 		Consumer:      consumerDefaultConfig,
 		Producer:      producerDefaultConfig,
+		Variables:     variables,
+		BuiltinImage:  builtinImage,
 	}
 
 	controller := controller.NewController(
 		kubeClient,
 		kfnClient,
 		kubeInformerFactory.Apps().V1().Deployments(),
+		kubeInformerFactory.Apps().V1().StatefulSets(),
 		kubeInformerFactory.Core().V1().ConfigMaps(),
+		kubeInformerFactory.Core().V1().Secrets(),
+		kubeInformerFactory.Core().V1().Pods(),
+		kubeInformerFactory.Core().V1().Nodes(),
+		kubeInformerFactory.Core().V1().PersistentVolumeClaims(),
 		kfnInformerFactory.Kfn().V1alpha1().Functions(),
+		kfnInformerFactory.Kfn().V1alpha1().FunctionRevisions(),
+		kfnInformerFactory.Kfn().V1alpha1().FunctionProfiles(),
+		kfnInformerFactory.Kfn().V1alpha1().FunctionRuntimes(),
 		functionDefaultConfig,
 	)
 
+	switch imageResolver {
+	case "registry":
+		controller.SetImageResolver(registry.NewHTTPResolver(10 * time.Second))
+	case "fake":
+		controller.SetImageResolver(registry.NewFake(nil))
+	default:
+		glog.Fatalf("Unknown image resolver %q", imageResolver)
+	}
+
+	controller.SetLagChecker(kafka.NewLagChecker(lagCheckTimeout))
+
+	pipelineController := pipelinecontroller.NewController(
+		kfnClient,
+		kfnInformerFactory.Kfn().V1alpha1().Functions(),
+		kfnInformerFactory.Kfn().V1alpha1().Pipelines(),
+	)
+
 	go kubeInformerFactory.Start(stopCh)
 	go kfnInformerFactory.Start(stopCh)
 
+	if httpAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/topology", topology.NewHandler(kfnInformerFactory.Kfn().V1alpha1().Functions().Lister(), &functionDefaultConfig))
+
+		go func() {
+			glog.Infof("Starting HTTP server on %s", httpAddress)
+			if err := http.ListenAndServe(httpAddress, mux); err != nil {
+				glog.Fatalf("Error running HTTP server: %s", err.Error())
+			}
+		}()
+	}
+
+	if webhookAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/validate", webhook.NewHandler(controller.Validate))
+
+		go func() {
+			glog.Infof("Starting webhook server on %s", webhookAddress)
+			if err := http.ListenAndServeTLS(webhookAddress, webhookCertFile, webhookKeyFile, mux); err != nil {
+				glog.Fatalf("Error running webhook server: %s", err.Error())
+			}
+		}()
+	}
+
+	go func() {
+		if err := pipelineController.Run(2, stopCh); err != nil {
+			glog.Fatalf("Error running pipeline controller: %s", err.Error())
+		}
+	}()
+
 	if err = controller.Run(2, stopCh); err != nil {
 		glog.Fatalf("Error running controller: %s", err.Error())
 	}
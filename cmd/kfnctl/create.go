@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	customflag "github.com/dajac/kfn/pkg/flag"
+)
+
+var createCommand = &command{
+	usage:       "<name> --image <image> --class <class> --input <topic> --output <topic> [flags]",
+	description: "Create a Function from flags.",
+	run:         runCreate,
+}
+
+func runCreate(cli *cli, args []string) error {
+	fs := newFlagSet("create")
+
+	spec := kfnv1alpha1.FunctionSpec{}
+	functionConfig := customflag.Properties{}
+	consumerConfig := customflag.Properties{}
+	producerConfig := customflag.Properties{}
+	replicas := fs.Int("replicas", 1, "The number of replicas.")
+
+	fs.StringVar(&spec.Image, "image", "", "The Docker image of the Function.")
+	fs.StringVar(&spec.Class, "class", "", "The fully qualified class name of the Function.")
+	fs.StringVar(&spec.Input, "input", "", "The input topic.")
+	fs.StringVar(&spec.InputKeyDeserializer, "input-key-deserializer", "bytes", "The deserializer of the input keys.")
+	fs.StringVar(&spec.InputValueDeserializer, "input-value-deserializer", "bytes", "The deserializer of the input values.")
+	fs.StringVar(&spec.Output, "output", "", "The output topic.")
+	fs.StringVar(&spec.OutputKeySerializer, "output-key-serializer", "bytes", "The serializer of the output keys.")
+	fs.StringVar(&spec.OutoutValueSerializer, "output-value-serializer", "bytes", "The serializer of the output values.")
+	fs.Var(&functionConfig, "function", "Set a configuration of the Function (key=value). Can be repeated.")
+	fs.Var(&consumerConfig, "consumer", "Set a configuration of the consumer (key=value). Can be repeated.")
+	fs.Var(&producerConfig, "producer", "Set a configuration of the producer (key=value). Can be repeated.")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	for flagName, value := range map[string]string{
+		"image":  spec.Image,
+		"class":  spec.Class,
+		"input":  spec.Input,
+		"output": spec.Output,
+	} {
+		if value == "" {
+			return fmt.Errorf("--%s is required", flagName)
+		}
+	}
+
+	spec.Replicas = int32(*replicas)
+
+	if len(functionConfig) > 0 {
+		config := map[string]string(functionConfig)
+		spec.FunctionConfig = &config
+	}
+
+	if len(consumerConfig) > 0 {
+		config := map[string]string(consumerConfig)
+		spec.ConsumerConfig = &config
+	}
+
+	if len(producerConfig) > 0 {
+		config := map[string]string(producerConfig)
+		spec.ProducerConfig = &config
+	}
+
+	function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Create(&kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      args[0],
+			Namespace: cli.namespace,
+		},
+		Spec: spec,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cli.output != "table" {
+		return printObject(os.Stdout, withTypeMeta(function), cli.output)
+	}
+
+	fmt.Printf("function/%s created\n", function.Name)
+	return nil
+}
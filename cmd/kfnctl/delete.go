@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var deleteCommand = &command{
+	usage:       "<name>...",
+	description: "Delete Functions. The ConfigMap and the Deployment are garbage collected.",
+	run:         runDelete,
+}
+
+func runDelete(cli *cli, args []string) error {
+	fs := newFlagSet("delete")
+
+	args, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		return fmt.Errorf("delete expects at least 1 argument")
+	}
+
+	propagation := metav1.DeletePropagationForeground
+
+	for _, name := range args {
+		err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Delete(name, &metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("function/%s deleted\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+var describeCommand = &command{
+	usage:       "<name>",
+	description: "Show the spec, the status, the rendered properties, the Deployment and the pods of a Function.",
+	run:         runDescribe,
+}
+
+// functionDescription gathers everything known about a Function.
+type functionDescription struct {
+	Function    *kfnv1alpha1.Function     `json:"function"`
+	Properties  string                    `json:"properties,omitempty"`
+	Deployment  *appsv1.DeploymentStatus  `json:"deployment,omitempty"`
+	StatefulSet *appsv1.StatefulSetStatus `json:"statefulSet,omitempty"`
+	Pods        []podDescription          `json:"pods"`
+}
+
+type podDescription struct {
+	Name     string          `json:"name"`
+	Phase    corev1.PodPhase `json:"phase"`
+	Ready    string          `json:"ready"`
+	Restarts int32           `json:"restarts"`
+	Node     string          `json:"node,omitempty"`
+	Age      string          `json:"age"`
+}
+
+func runDescribe(cli *cli, args []string) error {
+	fs := newFlagSet("describe")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	description, err := describeFunction(cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	if cli.output != "table" {
+		return printObject(os.Stdout, description, cli.output)
+	}
+
+	printDescription(os.Stdout, description)
+	return nil
+}
+
+func describeFunction(cli *cli, name string) (*functionDescription, error) {
+	function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	description := &functionDescription{
+		Function: withTypeMeta(function),
+		Pods:     []podDescription{},
+	}
+
+	configMap, err := cli.kubeClient.CoreV1().ConfigMaps(cli.namespace).Get(name, metav1.GetOptions{})
+	if err == nil {
+		description.Properties = render.ConfigData(configMap)
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	deployment, err := cli.kubeClient.AppsV1().Deployments(cli.namespace).Get(name, metav1.GetOptions{})
+	if err == nil {
+		description.Deployment = &deployment.Status
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	statefulSet, err := cli.kubeClient.AppsV1().StatefulSets(cli.namespace).Get(name, metav1.GetOptions{})
+	if err == nil {
+		description.StatefulSet = &statefulSet.Status
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// The pods of a canary rollout and of the shadow have their own label
+	for _, set := range []labels.Set{{"function": name}, {"canary": name}, {"shadow": name}} {
+		pods, err := cli.kubeClient.CoreV1().Pods(cli.namespace).List(metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(set).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pod := range pods.Items {
+			description.Pods = append(description.Pods, describePod(&pod))
+		}
+	}
+
+	sort.Slice(description.Pods, func(i, j int) bool {
+		return description.Pods[i].Name < description.Pods[j].Name
+	})
+
+	return description, nil
+}
+
+func describePod(pod *corev1.Pod) podDescription {
+	ready := 0
+	restarts := int32(0)
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+
+	return podDescription{
+		Name:     pod.Name,
+		Phase:    pod.Status.Phase,
+		Ready:    fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		Restarts: restarts,
+		Node:     pod.Spec.NodeName,
+		Age:      age(pod.CreationTimestamp),
+	}
+}
+
+func printDescription(w io.Writer, d *functionDescription) {
+	function := d.Function
+
+	fmt.Fprintf(w, "Name:          %s\n", function.Name)
+	fmt.Fprintf(w, "Namespace:     %s\n", function.Namespace)
+	fmt.Fprintf(w, "Created:       %s ago\n", age(function.CreationTimestamp))
+	printMap(w, "Annotations:", function.Annotations)
+
+	fmt.Fprintf(w, "Spec:\n")
+	fmt.Fprintf(w, "  Image:       %s\n", function.Spec.Image)
+	fmt.Fprintf(w, "  Class:       %s\n", function.Spec.Class)
+	fmt.Fprintf(w, "  Replicas:    %d\n", function.Spec.Replicas)
+	if function.Spec.Runtime != "" {
+		fmt.Fprintf(w, "  Runtime:     %s\n", function.Spec.Runtime)
+	}
+	if render.IsHTTPInvocation(function) {
+		fmt.Fprintf(w, "  Invocation:  http %s (%s)\n", function.Spec.Invocation.HTTP.Image, render.HTTPURL(function))
+	}
+	if function.Spec.Profile != "" {
+		fmt.Fprintf(w, "  Profile:     %s\n", function.Spec.Profile)
+	}
+	if registry := function.Spec.SchemaRegistry; registry != nil {
+		fmt.Fprintf(w, "  Registry:    %s\n", registry.URL)
+	}
+	if function.Spec.ProcessingGuarantee != "" {
+		fmt.Fprintf(w, "  Guarantee:   %s\n", function.Spec.ProcessingGuarantee)
+	}
+	if function.Spec.WorkloadType != "" {
+		fmt.Fprintf(w, "  Workload:    %s\n", function.Spec.WorkloadType)
+	}
+	if state := function.Spec.State; state != nil {
+		size, _ := render.StateSize(function)
+		fmt.Fprintf(w, "  State:       %s at %s", size.String(), render.StateMountPath(function))
+		if state.StorageClassName != nil {
+			fmt.Fprintf(w, " (storage class: %s)", *state.StorageClassName)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if function.Spec.RackAwareness != nil {
+		fmt.Fprintf(w, "  Rack:        zone of the node (%s)\n", strings.Join(render.ZoneLabels(function), ", "))
+	}
+	if function.Spec.Suspend {
+		fmt.Fprintf(w, "  Suspend:     true\n")
+	}
+	fmt.Fprintf(w, "  Input:       %s (key: %s, value: %s)\n", function.Spec.Input, function.Spec.InputKeyDeserializer, function.Spec.InputValueDeserializer)
+	fmt.Fprintf(w, "  Output:      %s (key: %s, value: %s)\n", function.Spec.Output, function.Spec.OutputKeySerializer, function.Spec.OutoutValueSerializer)
+	for _, route := range function.Spec.Routes {
+		fmt.Fprintf(w, "  Route:       %s -> %s\n", route.Name, route.Output)
+	}
+	if function.Spec.FunctionConfig != nil {
+		printMap(w, "  Function:", *function.Spec.FunctionConfig)
+	}
+	if function.Spec.ConsumerConfig != nil {
+		printMap(w, "  Consumer:", *function.Spec.ConsumerConfig)
+	}
+	if function.Spec.ProducerConfig != nil {
+		printMap(w, "  Producer:", *function.Spec.ProducerConfig)
+	}
+
+	fmt.Fprintf(w, "Status:\n")
+	fmt.Fprintf(w, "  Observed Generation:  %d (generation: %d)\n", function.Status.ObservedGeneration, function.Generation)
+	fmt.Fprintf(w, "  Available Replicas:   %d\n", function.Status.AvailableReplicas)
+	fmt.Fprintf(w, "  Suspended:            %t\n", function.Status.Suspended)
+	if function.Status.ConfigError != "" {
+		fmt.Fprintf(w, "  Config Error:         %s\n", function.Status.ConfigError)
+	}
+	for _, warning := range function.Status.Warnings {
+		fmt.Fprintf(w, "  Warning:              %s\n", warning)
+	}
+	for _, condition := range function.Status.Conditions {
+		fmt.Fprintf(w, "  %-22s%s %s %s\n", condition.Type+":", condition.Status, condition.Reason, condition.Message)
+	}
+	if canary := function.Status.Canary; canary != nil {
+		fmt.Fprintf(w, "  Canary:               %s revision %s, step %d, %d replicas\n", canary.Phase, canary.Revision, canary.Step, canary.Replicas)
+		for _, step := range canary.History {
+			fmt.Fprintf(w, "    %s  %s\n", step.Time.Format("2006-01-02T15:04:05Z07:00"), step.Message)
+		}
+	}
+	if image := function.Status.Image; image != nil {
+		fmt.Fprintf(w, "  Image:                %s\n", image.Pinned)
+		if image.Error != "" {
+			fmt.Fprintf(w, "    Error: %s\n", image.Error)
+		}
+	}
+	if image := function.Status.ShadowImage; image != nil {
+		fmt.Fprintf(w, "  Shadow image:         %s\n", image.Pinned)
+		if image.Error != "" {
+			fmt.Fprintf(w, "    Error: %s\n", image.Error)
+		}
+	}
+	if state := function.Status.State; state != nil {
+		fmt.Fprintf(w, "  State:                %d volumes of %s\n", state.Claims, state.Size)
+		if state.Error != "" {
+			fmt.Fprintf(w, "    Error: %s\n", state.Error)
+		}
+	}
+	if shadow := function.Status.Shadow; shadow != nil {
+		fmt.Fprintf(w, "  Shadow:               %d available, consumer group %s, output %s\n", shadow.AvailableReplicas, shadow.ConsumerGroup, shadow.Output)
+		if shadow.Error != "" {
+			fmt.Fprintf(w, "    Error: %s\n", shadow.Error)
+		}
+	}
+
+	fmt.Fprintf(w, "Properties:\n")
+	if d.Properties == "" {
+		fmt.Fprintf(w, "  <none>\n")
+	}
+	for _, line := range strings.Split(strings.TrimRight(d.Properties, "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+
+	if d.StatefulSet != nil {
+		fmt.Fprintf(w, "StatefulSet:\n")
+		fmt.Fprintf(w, "  Replicas:    %d current / %d updated / %d ready\n",
+			d.StatefulSet.Replicas, d.StatefulSet.UpdatedReplicas, d.StatefulSet.ReadyReplicas)
+	} else if d.Deployment == nil {
+		fmt.Fprintf(w, "Deployment:\n")
+		fmt.Fprintf(w, "  <none>\n")
+	}
+
+	if d.Deployment != nil {
+		fmt.Fprintf(w, "Deployment:\n")
+		fmt.Fprintf(w, "  Replicas:    %d current / %d updated / %d ready / %d available / %d unavailable\n",
+			d.Deployment.Replicas, d.Deployment.UpdatedReplicas, d.Deployment.ReadyReplicas, d.Deployment.AvailableReplicas, d.Deployment.UnavailableReplicas)
+		for _, condition := range d.Deployment.Conditions {
+			fmt.Fprintf(w, "  %s=%s  %s\n", condition.Type, condition.Status, condition.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "Pods:\n")
+	if len(d.Pods) == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+		return
+	}
+
+	t := newTable(w, "  NAME", "PHASE", "READY", "RESTARTS", "NODE", "AGE")
+	for _, pod := range d.Pods {
+		t.row("  "+pod.Name, string(pod.Phase), pod.Ready, fmt.Sprint(pod.Restarts), pod.Node, pod.Age)
+	}
+	t.flush()
+}
+
+func printMap(w io.Writer, title string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n", title)
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	indent := strings.Repeat(" ", len(title)-len(strings.TrimLeft(title, " "))+2)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s=%s\n", indent, key, m[key])
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/diff"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+var diffCommand = &command{
+	usage:       "-f <file> [--kafka <bootstrap>] [flags]",
+	description: "Show the changes that applying Functions would make to their ConfigMap and Deployment.",
+	run:         runDiff,
+}
+
+func runDiff(cli *cli, args []string) error {
+	fs := newFlagSet("diff")
+	filename := fs.String("f", "", "The file containing the Functions, - for the standard input.")
+	defaultConfig := addDefaultConfigFlags(fs)
+
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("-f is required")
+	}
+
+	functions, err := readFunctions(*filename, cli.namespace)
+	if err != nil {
+		return err
+	}
+
+	// The user-defined profiles and runtimes are the ones of the cluster
+	profiles, err := cli.kfnClient.KfnV1alpha1().FunctionProfiles().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	runtimes, err := cli.kfnClient.KfnV1alpha1().FunctionRuntimes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	defaultConfig.Profiles = render.NewProfiles(profileItems(profiles))
+	defaultConfig.Runtimes = render.NewRuntimes(runtimeItems(runtimes))
+
+	reports := []*diff.Report{}
+
+	invalid := 0
+
+	for _, function := range functions {
+		// The changes of an invalid Function would not be applied by the
+		// operator
+		if err := render.ValidateConfig(defaultConfig, function); err != nil {
+			reports = append(reports, &diff.Report{Function: function.Namespace + "/" + function.Name, ConfigError: err.Error()})
+			invalid++
+			continue
+		}
+
+		// The owner references of the live resources point to the live Function
+		// and the live resources run the images pinned in its status
+		var image, shadowImage *kfnv1alpha1.ImageStatus
+		live, err := cli.kfnClient.KfnV1alpha1().Functions(function.Namespace).Get(function.Name, metav1.GetOptions{})
+		if err == nil {
+			function.UID = live.UID
+			image = live.Status.Image
+			shadowImage = live.Status.ShadowImage
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+
+		var configMap *corev1.ConfigMap
+		var deployement *appsv1.Deployment
+		var statefulSet *appsv1.StatefulSet
+
+		configMap, err = cli.kubeClient.CoreV1().ConfigMaps(function.Namespace).Get(function.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			configMap = nil
+		}
+
+		deployement, err = cli.kubeClient.AppsV1().Deployments(function.Namespace).Get(function.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			deployement = nil
+		}
+
+		statefulSet, err = cli.kubeClient.AppsV1().StatefulSets(function.Namespace).Get(function.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			statefulSet = nil
+		}
+
+		resolved, err := render.ResolveSources(function, &clientSources{cli})
+		if err != nil {
+			reports = append(reports, &diff.Report{Function: function.Namespace + "/" + function.Name, ConfigError: err.Error()})
+			invalid++
+			continue
+		}
+		resolved = render.ResolveImage(defaultConfig, resolved)
+
+		// The image is not resolved, a new image is compared by its tag
+		report, err := diff.Compute(render.Render(defaultConfig, render.PinnedFunction(resolved, image, shadowImage)), configMap, deployement, statefulSet)
+		if err != nil {
+			return err
+		}
+
+		reports = append(reports, report)
+	}
+
+	if cli.output != "table" {
+		if err := printObject(os.Stdout, reports, cli.output); err != nil {
+			return err
+		}
+		return invalidError(invalid, len(functions))
+	}
+
+	for _, report := range reports {
+		fmt.Printf("function/%s\n", report.Function)
+
+		if report.ConfigError != "" {
+			fmt.Printf("  config error: %s\n", report.ConfigError)
+			continue
+		}
+
+		if report.Empty() {
+			fmt.Printf("  no changes\n")
+			continue
+		}
+
+		fmt.Printf("  config hash changed: %t\n", report.ConfigHashChanged)
+		fmt.Printf("  rolling restart:     %t\n", report.RollingRestart)
+		for _, line := range report.Lines() {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	return invalidError(invalid, len(functions))
+}
+
+func profileItems(list *kfnv1alpha1.FunctionProfileList) []*kfnv1alpha1.FunctionProfile {
+	profiles := make([]*kfnv1alpha1.FunctionProfile, 0, len(list.Items))
+	for i := range list.Items {
+		profiles = append(profiles, &list.Items[i])
+	}
+	return profiles
+}
+
+func runtimeItems(list *kfnv1alpha1.FunctionRuntimeList) []*kfnv1alpha1.FunctionRuntime {
+	runtimes := make([]*kfnv1alpha1.FunctionRuntime, 0, len(list.Items))
+	for i := range list.Items {
+		runtimes = append(runtimes, &list.Items[i])
+	}
+	return runtimes
+}
+
+// clientSources reads the ConfigMaps and the Secrets of the Functions from
+// the cluster.
+type clientSources struct {
+	cli *cli
+}
+
+func (s *clientSources) ConfigMap(namespace string, name string) (*corev1.ConfigMap, error) {
+	return s.cli.kubeClient.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+}
+
+func (s *clientSources) Secret(namespace string, name string) (*corev1.Secret, error) {
+	return s.cli.kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+}
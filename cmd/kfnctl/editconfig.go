@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var editConfigCommand = &command{
+	usage:       "<name> <function|consumer|producer> key=value... key-...",
+	description: "Set (key=value) or remove (key-) entries of the function, consumer or producer configuration.",
+	run:         runEditConfig,
+}
+
+func runEditConfig(cli *cli, args []string) error {
+	fs := newFlagSet("edit-config")
+
+	args, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	if len(args) < 3 {
+		fs.Usage()
+		return fmt.Errorf("edit-config expects at least 3 arguments but got %d", len(args))
+	}
+
+	name, section, changes := args[0], args[1], args[2:]
+
+	switch section {
+	case "function", "consumer", "producer":
+	default:
+		return fmt.Errorf("unknown configuration %q, must be function, consumer or producer", section)
+	}
+
+	set := map[string]string{}
+	remove := []string{}
+
+	for _, change := range changes {
+		if strings.HasSuffix(change, "-") && !strings.Contains(change, "=") {
+			remove = append(remove, strings.TrimSuffix(change, "-"))
+			continue
+		}
+
+		res := strings.SplitN(change, "=", 2)
+		if len(res) != 2 || res[0] == "" {
+			return fmt.Errorf("invalid change %q, must be key=value or key-", change)
+		}
+		set[res[0]] = res[1]
+	}
+
+	err = updateFunction(cli, name, func(function *kfnv1alpha1.Function) {
+		var config **map[string]string
+
+		switch section {
+		case "function":
+			config = &function.Spec.FunctionConfig
+		case "consumer":
+			config = &function.Spec.ConsumerConfig
+		case "producer":
+			config = &function.Spec.ProducerConfig
+		}
+
+		if *config == nil {
+			*config = &map[string]string{}
+		}
+
+		for key, value := range set {
+			(**config)[key] = value
+		}
+
+		for _, key := range remove {
+			delete(**config, key)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("function/%s configured\n", name)
+	return nil
+}
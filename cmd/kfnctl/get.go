@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var getCommand = &command{
+	usage:       "[name]",
+	description: "List the Functions or get a single Function.",
+	run:         runGet,
+}
+
+func runGet(cli *cli, args []string) error {
+	fs := newFlagSet("get")
+	selector := fs.String("l", "", "Selector (label query) to filter on.")
+
+	args, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	var functions []kfnv1alpha1.Function
+
+	switch len(args) {
+	case 0:
+		list, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).List(metav1.ListOptions{
+			LabelSelector: *selector,
+		})
+		if err != nil {
+			return err
+		}
+		functions = list.Items
+	case 1:
+		function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Get(args[0], metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		functions = append(functions, *function)
+	default:
+		fs.Usage()
+		return fmt.Errorf("get expects at most 1 argument but got %d", len(args))
+	}
+
+	if cli.output != "table" {
+		if len(args) == 1 {
+			return printObject(os.Stdout, withTypeMeta(&functions[0]), cli.output)
+		}
+
+		for i := range functions {
+			withTypeMeta(&functions[i])
+		}
+
+		return printObject(os.Stdout, &kfnv1alpha1.FunctionList{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "List",
+			},
+			Items: functions,
+		}, cli.output)
+	}
+
+	t := newTable(os.Stdout, "NAME", "IMAGE", "CLASS", "INPUT", "OUTPUT", "DESIRED", "AVAILABLE", "SUSPENDED", "AGE")
+	for _, function := range functions {
+		t.row(
+			function.Name,
+			function.Spec.Image,
+			function.Spec.Class,
+			function.Spec.Input,
+			function.Spec.Output,
+			fmt.Sprint(function.Spec.Replicas),
+			fmt.Sprint(function.Status.AvailableReplicas),
+			fmt.Sprint(function.Status.Suspended),
+			age(function.CreationTimestamp),
+		)
+	}
+	return t.flush()
+}
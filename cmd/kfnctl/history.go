@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var historyCommand = &command{
+	usage:       "<name> [--revision <number>]",
+	description: "List the revisions of a Function or show one of them.",
+	run:         runHistory,
+}
+
+func runHistory(cli *cli, args []string) error {
+	fs := newFlagSet("history")
+	number := fs.Int64("revision", 0, "Show the spec and the properties of this revision.")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Get(args[0], metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	revisions, err := listRevisions(cli, function)
+	if err != nil {
+		return err
+	}
+
+	if *number != 0 {
+		revision := findRevision(revisions, *number)
+		if revision == nil {
+			return fmt.Errorf("revision %d of function %s does not exist", *number, function.Name)
+		}
+
+		if cli.output == "table" {
+			return printObject(os.Stdout, revision, "yaml")
+		}
+		return printObject(os.Stdout, revision, cli.output)
+	}
+
+	if cli.output != "table" {
+		return printObject(os.Stdout, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      revisions,
+		}, cli.output)
+	}
+
+	t := newTable(os.Stdout, "REVISION", "NAME", "HASH", "IMAGE", "CURRENT", "AGE")
+	for _, revision := range revisions {
+		current := ""
+		if revision.Name == function.Status.CurrentRevision {
+			current = "*"
+		}
+
+		t.row(
+			fmt.Sprint(revision.Spec.Revision),
+			revision.Name,
+			revision.Spec.Hash,
+			revision.Spec.Image,
+			current,
+			age(revision.CreationTimestamp),
+		)
+	}
+	return t.flush()
+}
+
+// listRevisions returns the FunctionRevisions of the Function ordered by
+// revision number.
+func listRevisions(cli *cli, function *kfnv1alpha1.Function) ([]*kfnv1alpha1.FunctionRevision, error) {
+	list, err := cli.kfnClient.KfnV1alpha1().FunctionRevisions(function.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"function": function.Name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []*kfnv1alpha1.FunctionRevision{}
+	for i := range list.Items {
+		revision := &list.Items[i]
+		if !metav1.IsControlledBy(revision, function) {
+			continue
+		}
+
+		revision.TypeMeta = metav1.TypeMeta{
+			APIVersion: kfnv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "FunctionRevision",
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Spec.Revision < revisions[j].Spec.Revision
+	})
+
+	return revisions, nil
+}
+
+func findRevision(revisions []*kfnv1alpha1.FunctionRevision, number int64) *kfnv1alpha1.FunctionRevision {
+	for _, revision := range revisions {
+		if revision.Spec.Revision == number {
+			return revision
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	clientset "github.com/dajac/kfn/pkg/client/clientset/versioned"
+)
+
+// command is a kfnctl subcommand.
+type command struct {
+	usage       string
+	description string
+	run         func(cli *cli, args []string) error
+
+	// offline commands do not connect to the cluster.
+	offline bool
+}
+
+var commands map[string]*command
+
+// cli holds the clients and the global options shared by all the commands.
+type cli struct {
+	kubeClient kubernetes.Interface
+	kfnClient  clientset.Interface
+	namespace  string
+	output     string
+}
+
+var (
+	masterURL  string
+	kubeconfig string
+	namespace  string
+	output     string
+)
+
+func init() {
+	commands = map[string]*command{
+		"create":      createCommand,
+		"get":         getCommand,
+		"describe":    describeCommand,
+		"diff":        diffCommand,
+		"scale":       scaleCommand,
+		"delete":      deleteCommand,
+		"restart":     restartCommand,
+		"suspend":     suspendCommand,
+		"resume":      resumeCommand,
+		"edit-config": editConfigCommand,
+		"history":     historyCommand,
+		"rollback":    rollbackCommand,
+		"render":      renderCommand,
+		"topology":    topologyCommand,
+	}
+
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to the standard kubeconfig loading rules.")
+	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig.")
+	flag.StringVar(&namespace, "n", "", "The namespace of the Functions. Defaults to the namespace of the current context.")
+	flag.StringVar(&output, "o", "table", "Output format: table, json or yaml.")
+
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: kfnctl [flags] <command> [args]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].description)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	cli, err := newCli(cmd.offline)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if err := cmd.run(cli, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func newCli(offline bool) (*cli, error) {
+	switch output {
+	case "table", "json", "yaml":
+	default:
+		return nil, fmt.Errorf("unknown output format %q", output)
+	}
+
+	if offline {
+		return &cli{
+			namespace: namespace,
+			output:    output,
+		}, nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.ClusterInfo.Server = masterURL
+	overrides.Context.Namespace = namespace
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %s", err.Error())
+	}
+
+	ns, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("error getting the namespace: %s", err.Error())
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubernetes clientset: %s", err.Error())
+	}
+
+	kfnClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building kfn clientset: %s", err.Error())
+	}
+
+	return &cli{
+		kubeClient: kubeClient,
+		kfnClient:  kfnClient,
+		namespace:  ns,
+		output:     output,
+	}, nil
+}
+
+// newFlagSet returns a FlagSet for the given command which prints the usage
+// of the command on error.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: kfnctl %s %s\n\n%s\n", name, commands[name].usage, commands[name].description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses the flags of a command and checks that it got the
+// expected number of positional arguments. Flags and positional arguments
+// can be interleaved.
+func parseArgs(fs *flag.FlagSet, args []string, nargs int) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if nargs >= 0 && len(positional) != nargs {
+		fs.Usage()
+		return nil, fmt.Errorf("%s expects %d argument(s) but got %d", fs.Name(), nargs, len(positional))
+	}
+
+	return positional, nil
+}
+
+// updateFunction applies mutate to the latest version of the Function and
+// updates it, retrying on conflicts.
+func updateFunction(cli *cli, name string, mutate func(function *kfnv1alpha1.Function)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		mutate(function)
+
+		_, err = cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Update(function)
+		return err
+	})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/client/clientset/versioned/fake"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		nargs      int
+		positional []string
+		replicas   int
+		err        bool
+	}{
+		{args: []string{"copy", "--replicas", "3"}, nargs: 1, positional: []string{"copy"}, replicas: 3},
+		{args: []string{"--replicas", "3", "copy"}, nargs: 1, positional: []string{"copy"}, replicas: 3},
+		{args: []string{"copy", "other"}, nargs: -1, positional: []string{"copy", "other"}, replicas: -1},
+		{args: []string{}, nargs: 1, err: true},
+		{args: []string{"copy", "other"}, nargs: 1, err: true},
+		{args: []string{"copy", "--unknown"}, nargs: 1, err: true},
+	}
+
+	for _, test := range tests {
+		fs := newFlagSet("scale")
+		fs.SetOutput(ioutil.Discard)
+		replicas := fs.Int("replicas", -1, "")
+
+		positional, err := parseArgs(fs, test.args, test.nargs)
+		if test.err {
+			if err == nil {
+				t.Errorf("%v: expected an error", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", test.args, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(positional, test.positional) || *replicas != test.replicas {
+			t.Errorf("%v: args = %v, replicas = %d, want %v, %d", test.args, positional, *replicas, test.positional, test.replicas)
+		}
+	}
+}
+
+func TestReadFunctions(t *testing.T) {
+	f, err := ioutil.TempFile("", "kfnctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	manifest := `apiVersion: kfn.dajac.io/v1alpha1
+kind: Function
+metadata:
+  name: first
+spec:
+  image: dajac/kfn-examples:0.1.0
+---
+---
+apiVersion: kfn.dajac.io/v1alpha1
+kind: Function
+metadata:
+  name: second
+  namespace: other
+spec:
+  image: dajac/kfn-examples:0.1.0
+`
+	if _, err := f.WriteString(manifest); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	functions, err := readFunctions(f.Name(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 2 {
+		t.Fatalf("got %d Functions, want 2", len(functions))
+	}
+	if functions[0].Namespace != "default" || functions[1].Namespace != "other" {
+		t.Errorf("namespaces = %s, %s, want default, other", functions[0].Namespace, functions[1].Namespace)
+	}
+
+	if err := ioutil.WriteFile(f.Name(), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFunctions(f.Name(), "default"); err == nil {
+		t.Errorf("a manifest which is not a Function must be rejected")
+	}
+
+	if _, err := readFunctions(f.Name()+".missing", "default"); err == nil {
+		t.Errorf("a missing file must be rejected")
+	}
+}
+
+func newTestCli(functions ...*kfnv1alpha1.Function) *cli {
+	objects := make([]runtime.Object, 0, len(functions))
+	for _, function := range functions {
+		objects = append(objects, function)
+	}
+	return &cli{kfnClient: fake.NewSimpleClientset(objects...), namespace: "default", output: "table"}
+}
+
+func newTestFunction(name string, labels map[string]string) *kfnv1alpha1.Function {
+	return &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+		Spec:       kfnv1alpha1.FunctionSpec{Image: "dajac/kfn-examples:0.1.0", Replicas: 1},
+	}
+}
+
+func getTestFunction(t *testing.T, cli *cli, name string) *kfnv1alpha1.Function {
+	function, err := cli.kfnClient.KfnV1alpha1().Functions("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return function
+}
+
+func TestRunScale(t *testing.T) {
+	cli := newTestCli(newTestFunction("copy", nil))
+
+	if err := runScale(cli, []string{"copy", "--replicas", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if replicas := getTestFunction(t, cli, "copy").Spec.Replicas; replicas != 3 {
+		t.Errorf("replicas = %d, want 3", replicas)
+	}
+
+	if err := runScale(cli, []string{"copy"}); err == nil {
+		t.Errorf("scale without --replicas must fail")
+	}
+	if err := runScale(cli, []string{"missing", "--replicas", "3"}); err == nil {
+		t.Errorf("scaling a missing Function must fail")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	customflag "github.com/dajac/kfn/pkg/flag"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// readFunctions reads the Functions defined in a YAML or JSON file. The file
+// may contain multiple documents. "-" reads from the standard input.
+func readFunctions(path string, namespace string) ([]*kfnv1alpha1.Function, error) {
+	var r io.Reader
+
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	functions := []*kfnv1alpha1.Function{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+
+	for {
+		function := &kfnv1alpha1.Function{}
+
+		if err := decoder.Decode(function); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error decoding %s: %s", path, err.Error())
+		}
+
+		// Skip empty documents
+		if function.Kind == "" && function.Name == "" {
+			continue
+		}
+
+		if function.Kind != "Function" {
+			return nil, fmt.Errorf("%s: expected kind Function but got %q", path, function.Kind)
+		}
+
+		if function.Namespace == "" {
+			function.Namespace = namespace
+		}
+
+		functions = append(functions, function)
+	}
+
+	return functions, nil
+}
+
+// addDefaultConfigFlags registers the flags of the default configuration
+// of the operator.
+func addDefaultConfigFlags(fs *flag.FlagSet) *render.FunctionDefaultConfig {
+	defaultConfig := &render.FunctionDefaultConfig{
+		Function:  customflag.Config{},
+		Consumer:  customflag.Config{},
+		Producer:  customflag.Config{},
+		Variables: customflag.Properties{},
+	}
+
+	fs.StringVar(&defaultConfig.KafkaBoostrap, "kafka", "", "The address of the Kafka cluster, as configured in the operator.")
+	fs.Var((*customflag.Config)(&defaultConfig.Function), "default-function", "Default configuration for all functions (key:value), as configured in the operator.")
+	fs.Var((*customflag.Config)(&defaultConfig.Consumer), "default-consumer", "Default consumer configuration for all functions (key:value), as configured in the operator.")
+	fs.Var((*customflag.Config)(&defaultConfig.Producer), "default-producer", "Default producer configuration for all functions (key:value), as configured in the operator.")
+	fs.Var((*customflag.Properties)(&defaultConfig.Variables), "variable", "Variable which can be used as ${name} in the configuration of the functions (name=value), as configured in the operator.")
+	fs.StringVar(&defaultConfig.BuiltinImage, "builtin-image", render.DefaultBuiltinImage, "The image running the builtin functions, as configured in the operator.")
+
+	return defaultConfig
+}
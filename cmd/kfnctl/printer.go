@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// printObject prints obj in the given structured format (json or yaml).
+func printObject(w io.Writer, obj interface{}, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// withTypeMeta sets the TypeMeta of the Function, which is not populated by
+// the typed client, so it can be printed as a valid manifest.
+func withTypeMeta(function *kfnv1alpha1.Function) *kfnv1alpha1.Function {
+	function.TypeMeta = metav1.TypeMeta{
+		APIVersion: kfnv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "Function",
+	}
+	return function
+}
+
+// table prints aligned columns.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(w io.Writer, headers ...string) *table {
+	t := &table{
+		w: tabwriter.NewWriter(w, 0, 8, 3, ' ', 0),
+	}
+	t.row(headers...)
+	return t
+}
+
+func (t *table) row(columns ...string) {
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.w.Flush()
+}
+
+func age(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.ShortHumanDuration(time.Since(timestamp.Time))
+}
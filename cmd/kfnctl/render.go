@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+var renderCommand = &command{
+	usage:       "-f <file> [--kafka <bootstrap>] [flags]",
+	description: "Render the ConfigMap, the Deployment and the properties of Functions without a cluster.",
+	run:         runRender,
+	offline:     true,
+}
+
+func runRender(cli *cli, args []string) error {
+	fs := newFlagSet("render")
+	filename := fs.String("f", "", "The file containing the Functions, - for the standard input.")
+	propertiesOnly := fs.Bool("properties", false, "Only print the rendered configuration file, function.properties or function.json.")
+	defaultConfig := addDefaultConfigFlags(fs)
+
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("-f is required")
+	}
+
+	namespace := cli.namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	functions, err := readFunctions(*filename, namespace)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is rendered unless all the Functions are valid so that the
+	// command can gate the changes in CI
+	invalid := 0
+	for _, function := range functions {
+		for _, warning := range render.ConfigWarnings(function) {
+			fmt.Fprintf(os.Stderr, "Warning: function %s/%s: %s\n", function.Namespace, function.Name, warning)
+		}
+
+		if err := validateOffline(defaultConfig, function); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: function %s/%s: %s\n", function.Namespace, function.Name, err.Error())
+			invalid++
+		}
+	}
+
+	if err := invalidError(invalid, len(functions)); err != nil {
+		return err
+	}
+
+	objects := []interface{}{}
+
+	for i, function := range functions {
+		resources := render.Render(defaultConfig, render.ResolveImage(defaultConfig, function))
+
+		if *propertiesOnly {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s/%s\n", function.Namespace, function.Name)
+			fmt.Print(render.ConfigData(resources.ConfigMap))
+			continue
+		}
+
+		if cli.output == "json" {
+			objects = append(objects, resources.Objects()...)
+			continue
+		}
+
+		for _, obj := range resources.Objects() {
+			fmt.Println("---")
+			if err := printObject(os.Stdout, obj, "yaml"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cli.output == "json" && !*propertiesOnly {
+		return printObject(os.Stdout, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+		}, "json")
+	}
+
+	return nil
+}
+
+// invalidError returns an error when some of the Functions are invalid so
+// that the command exits with a non-zero status.
+func invalidError(invalid int, total int) error {
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d Functions are invalid", invalid, total)
+	}
+	return nil
+}
+
+// validateOffline checks the Function like the operator does. The
+// FunctionProfiles and the FunctionRuntimes are not read from the cluster so
+// only the built-in profiles and runtimes are known.
+func validateOffline(defaultConfig *render.FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	err := render.ValidateConfig(defaultConfig, function)
+	if err == nil {
+		return nil
+	}
+
+	if render.ValidateProfile(defaultConfig, function) != nil || render.ValidateRuntime(defaultConfig, function) != nil {
+		return fmt.Errorf("%s (the FunctionProfiles and FunctionRuntimes of the cluster are not known offline, only the built-in ones)", err.Error())
+	}
+
+	return err
+}
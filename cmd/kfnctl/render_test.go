@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestValidateOffline(t *testing.T) {
+	tests := []struct {
+		name      string
+		runtime   string
+		profile   string
+		consumer  map[string]string
+		errString string
+	}{
+		{name: "valid"},
+		{name: "runtime", runtime: "doesnotexist", errString: "not known offline"},
+		{name: "profile", profile: "nope", errString: "not known offline"},
+		{name: "placeholder", consumer: map[string]string{"client.id": "${unknown.var}"}, errString: "unknown.var"},
+		{name: "value", consumer: map[string]string{"max.poll.records": "lots"}, errString: "max.poll.records"},
+	}
+
+	for _, test := range tests {
+		function := newTestFunction("copy", nil)
+		function.Spec.Runtime = test.runtime
+		function.Spec.Profile = test.profile
+		if test.consumer != nil {
+			function.Spec.ConsumerConfig = &test.consumer
+		}
+
+		err := validateOffline(&render.FunctionDefaultConfig{}, function)
+		if test.errString == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errString) {
+			t.Errorf("%s: error %v does not contain %q", test.name, err, test.errString)
+		}
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	f, err := ioutil.TempFile("", "kfnctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	manifest := `apiVersion: kfn.dajac.io/v1alpha1
+kind: Function
+metadata:
+  name: copy
+spec:
+  image: dajac/kfn-examples:0.1.0
+  runtime: doesnotexist
+`
+	if _, err := f.WriteString(manifest); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	err = runRender(&cli{output: "table"}, []string{"-f", f.Name(), "--properties"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 Functions are invalid") {
+		t.Errorf("an invalid Function must fail the command, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var restartCommand = &command{
+	usage:       "<name>",
+	description: "Trigger a rolling restart of a Function.",
+	run:         runRestart,
+}
+
+func runRestart(cli *cli, args []string) error {
+	fs := newFlagSet("restart")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	err = updateFunction(cli, args[0], func(function *kfnv1alpha1.Function) {
+		if function.Annotations == nil {
+			function.Annotations = map[string]string{}
+		}
+		function.Annotations[kfn.RestartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("function/%s restarted\n", args[0])
+	return nil
+}
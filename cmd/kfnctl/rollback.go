@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var rollbackCommand = &command{
+	usage:       "<name> [--to-revision <number>]",
+	description: "Restore the spec of a previous revision of a Function.",
+	run:         runRollback,
+}
+
+func runRollback(cli *cli, args []string) error {
+	fs := newFlagSet("rollback")
+	number := fs.Int64("to-revision", 0, "The revision to restore. Defaults to the revision preceding the current one.")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	function, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).Get(args[0], metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	revisions, err := listRevisions(cli, function)
+	if err != nil {
+		return err
+	}
+
+	var revision *kfnv1alpha1.FunctionRevision
+
+	if *number != 0 {
+		revision = findRevision(revisions, *number)
+		if revision == nil {
+			return fmt.Errorf("revision %d of function %s does not exist", *number, function.Name)
+		}
+	} else {
+		for _, r := range revisions {
+			if r.Name == function.Status.CurrentRevision {
+				break
+			}
+			revision = r
+		}
+		if revision == nil {
+			return fmt.Errorf("function %s has no previous revision", function.Name)
+		}
+	}
+
+	// The controller restores the revision so the rollback goes through
+	// the same path as the annotation set by hand
+	err = updateFunction(cli, function.Name, func(function *kfnv1alpha1.Function) {
+		if function.Annotations == nil {
+			function.Annotations = map[string]string{}
+		}
+		function.Annotations[kfn.RollbackToAnnotation] = fmt.Sprint(revision.Spec.Revision)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("function/%s rolled back to revision %d\n", function.Name, revision.Spec.Revision)
+	return nil
+}
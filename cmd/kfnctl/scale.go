@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var scaleCommand = &command{
+	usage:       "<name> --replicas <count>",
+	description: "Set the number of replicas of a Function.",
+	run:         runScale,
+}
+
+func runScale(cli *cli, args []string) error {
+	fs := newFlagSet("scale")
+	replicas := fs.Int("replicas", -1, "The new number of replicas.")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if *replicas < 0 {
+		return fmt.Errorf("--replicas is required and must be positive")
+	}
+
+	err = updateFunction(cli, args[0], func(function *kfnv1alpha1.Function) {
+		function.Spec.Replicas = int32(*replicas)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("function/%s scaled\n", args[0])
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+var suspendCommand = &command{
+	usage:       "<name> | -l <selector> | --all",
+	description: "Scale Functions to zero while keeping their replicas.",
+	run: func(cli *cli, args []string) error {
+		return runSuspend(cli, "suspend", true, args)
+	},
+}
+
+var resumeCommand = &command{
+	usage:       "<name> | -l <selector> | --all",
+	description: "Resume suspended Functions to their replicas.",
+	run: func(cli *cli, args []string) error {
+		return runSuspend(cli, "resume", false, args)
+	},
+}
+
+func runSuspend(cli *cli, name string, suspend bool, args []string) error {
+	fs := newFlagSet(name)
+	selector := fs.String("l", "", "Selector (label query) of the Functions.")
+	all := fs.Bool("all", false, "All the Functions of the namespace.")
+
+	args, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+
+	switch {
+	case len(args) == 1 && *selector == "" && !*all:
+		names = args
+	case len(args) == 0 && (*selector != "" || *all):
+		list, err := cli.kfnClient.KfnV1alpha1().Functions(cli.namespace).List(metav1.ListOptions{
+			LabelSelector: *selector,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, function := range list.Items {
+			if function.Spec.Suspend != suspend {
+				names = append(names, function.Name)
+			}
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("%s expects either a name, -l or --all", name)
+	}
+
+	for _, function := range names {
+		err := updateFunction(cli, function, func(function *kfnv1alpha1.Function) {
+			function.Spec.Suspend = suspend
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("function/%s %sd\n", function, name)
+	}
+
+	return nil
+}
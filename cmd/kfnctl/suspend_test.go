@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestRunSuspend(t *testing.T) {
+	cli := newTestCli(
+		newTestFunction("first", map[string]string{"team": "a"}),
+		newTestFunction("second", map[string]string{"team": "a"}),
+		newTestFunction("third", map[string]string{"team": "b"}),
+	)
+
+	if err := runSuspend(cli, "suspend", true, []string{"-l", "team=a"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, suspended := range map[string]bool{"first": true, "second": true, "third": false} {
+		if function := getTestFunction(t, cli, name); function.Spec.Suspend != suspended || function.Spec.Replicas != 1 {
+			t.Errorf("%s: suspend = %t, replicas = %d, want %t, 1", name, function.Spec.Suspend, function.Spec.Replicas, suspended)
+		}
+	}
+
+	if err := runSuspend(cli, "resume", false, []string{"--all"}); err != nil {
+		t.Fatal(err)
+	}
+	if getTestFunction(t, cli, "first").Spec.Suspend {
+		t.Errorf("the Functions must be resumed")
+	}
+
+	for _, args := range [][]string{{}, {"first", "--all"}, {"first", "second"}} {
+		if err := runSuspend(cli, "suspend", true, args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/topology"
+)
+
+var topologyCommand = &command{
+	usage:       "[--format dot|mermaid|json] [--namespaced] [flags]",
+	description: "Export the graph of the topics connected by the Functions.",
+	run:         runTopology,
+}
+
+func runTopology(cli *cli, args []string) error {
+	fs := newFlagSet("topology")
+	format := fs.String("format", "dot", "The format of the graph: "+strings.Join(topology.Formats, ", ")+".")
+	namespaced := fs.Bool("namespaced", false, "Only include the Functions of the namespace. By default, the Functions of all namespaces are included as they share the topics.")
+	defaultConfig := addDefaultConfigFlags(fs)
+
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	namespace := metav1.NamespaceAll
+	if *namespaced {
+		namespace = cli.namespace
+	}
+
+	list, err := cli.kfnClient.KfnV1alpha1().Functions(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	functions := make([]*kfnv1alpha1.Function, 0, len(list.Items))
+	for i := range list.Items {
+		functions = append(functions, &list.Items[i])
+	}
+
+	graph := topology.Build(defaultConfig, functions)
+
+	if cli.output != "table" {
+		return printObject(os.Stdout, graph, cli.output)
+	}
+
+	output, err := graph.Export(*format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output)
+
+	for _, cycle := range graph.Cycles {
+		fmt.Fprintf(os.Stderr, "Warning: cycle between %s\n", strings.Join(cycle, ", "))
+	}
+	for _, topic := range graph.DanglingInputs() {
+		fmt.Fprintf(os.Stderr, "Warning: topic %s is consumed but no Function produces to it\n", topic)
+	}
+	for _, topic := range graph.UnconsumedOutputs() {
+		fmt.Fprintf(os.Stderr, "Warning: topic %s is produced but no Function consumes it\n", topic)
+	}
+
+	return nil
+}
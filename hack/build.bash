@@ -2,7 +2,7 @@
 
 ROOT=${ROOT:-$(git rev-parse --show-toplevel)}
 
-NAME=kfn-operator
+NAMES="kfn-operator kfnctl"
 
 PLATFORM=$(go env GOOS)
 ARCH=$(go env GOARCH)
@@ -19,6 +19,8 @@ GO_BUILD_LDFLAGS=""
 
 mkdir -p "${ROOT}/bin"
 
-echo "Building for ${PLATFORM}/${ARCH}"
-GOARCH=${ARCH} GOOS=${PLATFORM} ${GO_BUILD_CMD} -ldflags "${GO_BUILD_LDFLAGS}" \
-    -o "${ROOT}/bin/${NAME}" ./cmd/${NAME}/
+for NAME in ${NAMES}; do
+  echo "Building ${NAME} for ${PLATFORM}/${ARCH}"
+  GOARCH=${ARCH} GOOS=${PLATFORM} ${GO_BUILD_CMD} -ldflags "${GO_BUILD_LDFLAGS}" \
+      -o "${ROOT}/bin/${NAME}" ./cmd/${NAME}/
+done
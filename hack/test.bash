@@ -1 +1,4 @@
-#!/usr/bin/env bash
\ No newline at end of file
+#!/usr/bin/env bash
+
+PKGS=$(go list ./... | grep -vF /vendor/)
+go test $PKGS
@@ -2,4 +2,33 @@ package kfn
 
 const (
 	GroupName = "kfn.dajac.io"
+
+	// RestartedAtAnnotation is set on a Function to trigger a rolling restart
+	// of its pods. The controller copies it to the pod template.
+	RestartedAtAnnotation = GroupName + "/restartedAt"
+
+	// DryRunAnnotation, when set to "true", makes the controller compute the
+	// changes to the resources of a Function and report them in its status
+	// without applying them.
+	DryRunAnnotation = GroupName + "/dry-run"
+
+	// RollbackToAnnotation is set on a Function to restore the spec of one
+	// of its FunctionRevisions, identified by its revision number. The
+	// controller removes it once handled.
+	RollbackToAnnotation = GroupName + "/rollback-to"
+
+	// RefreshDigestAnnotation is set on a Function pinning its image digest
+	// to resolve the tag again. Any new value triggers a resolution.
+	RefreshDigestAnnotation = GroupName + "/refresh-digest"
+
+	// CustomConfigAnnotation lists the consumer and producer properties of
+	// a Function which are not checked against the catalog of the Kafka
+	// clients, e.g. the properties of plugins. It is a comma separated list
+	// of keys or of prefixes followed by `*`. `*` disables the checks.
+	CustomConfigAnnotation = GroupName + "/custom-config"
+
+	// AllowCycleAnnotation, when set to "true", allows the output of a
+	// Function to eventually feed its own input, e.g. a retry loop. The
+	// Functions introducing a cycle are rejected otherwise.
+	AllowCycleAnnotation = GroupName + "/allow-cycle"
 )
@@ -31,6 +31,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Function{},
 		&FunctionList{},
+		&FunctionRevision{},
+		&FunctionRevisionList{},
+		&FunctionProfile{},
+		&FunctionProfileList{},
+		&FunctionRuntime{},
+		&FunctionRuntimeList{},
+		&Pipeline{},
+		&PipelineList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -19,7 +20,8 @@ type Function struct {
 // FunctionSpec is the specification of a KFn Function ressource
 type FunctionSpec struct {
 	// Image is the Docker image of the Function.
-	// Image must be based on dajac/kfn-invoker:x.x.x
+	// Image must be based on dajac/kfn-invoker:x.x.x. It must not be set
+	// with Builtin.
 	Image string `json:"image"`
 
 	// Replicas is the expected number of Function.
@@ -57,6 +59,11 @@ type FunctionSpec struct {
 	// See InputKeyDeserializer for details.
 	OutoutValueSerializer string `json:"outputValueSerializer"`
 
+	// Routes send the records matching a condition to other topics. The
+	// routes are evaluated in order and the first matching route wins.
+	// Output is the default route of the records matching none of them.
+	Routes []RouteSpec `json:"routes,omitempty"`
+
 	// FunctionConfig is a set of key-value pairs which will be passed to
 	// the Function via the `configure` method.
 	FunctionConfig *map[string]string `json:"function"`
@@ -68,12 +75,561 @@ type FunctionSpec struct {
 	// ProducerConfig is a set of key-value pairs which will be passed to
 	// the Kafka Producer.
 	ProducerConfig *map[string]string `json:"producer"`
+
+	// FunctionConfigFrom, ConsumerConfigFrom and ProducerConfigFrom are
+	// properties whose value is read from a key of a ConfigMap or a
+	// Secret of the namespace of the Function. They complete the
+	// FunctionConfig, the ConsumerConfig and the ProducerConfig, which
+	// can't set the same properties.
+	FunctionConfigFrom []ConfigFromSource `json:"functionConfigFrom,omitempty"`
+	ConsumerConfigFrom []ConfigFromSource `json:"consumerConfigFrom,omitempty"`
+	ProducerConfigFrom []ConfigFromSource `json:"producerConfigFrom,omitempty"`
+
+	// Files are the entries of ConfigMaps or Secrets mounted in the pods
+	// of the Function, e.g. a truststore or a file read by the Function.
+	Files []FileSpec `json:"files,omitempty"`
+
+	// Runtime is the name of the runtime running the Function: java, the
+	// default, python, node, native or the name of a FunctionRuntime.
+	Runtime string `json:"runtime,omitempty"`
+
+	// Invocation describes how the invoker calls the Function. It defaults
+	// to the Class loaded from the image.
+	Invocation *InvocationSpec `json:"invocation,omitempty"`
+
+	// Builtin is a declarative Function written as CEL expressions. It
+	// runs on the stock invoker image configured in the operator and
+	// replaces Image and Class.
+	Builtin *BuiltinSpec `json:"builtin,omitempty"`
+
+	// Profile is the name of a set of consumer and producer properties
+	// tuned for a workload: low-latency, high-throughput, balanced or the
+	// name of a FunctionProfile. The consumer and producer configurations
+	// of the Function take precedence over the profile.
+	Profile string `json:"profile,omitempty"`
+
+	// SchemaRegistry configures the Schema Registry used by the avro,
+	// json-schema and protobuf serializers and deserializers of both the
+	// consumer and the producer.
+	SchemaRegistry *SchemaRegistrySpec `json:"schemaRegistry,omitempty"`
+
+	// ProcessingGuarantee is at-least-once or exactly-once. With
+	// exactly-once, the records are produced and the offsets committed in
+	// Kafka transactions and only committed records are consumed. An
+	// exactly-once Function runs as a StatefulSet. Defaults to the behavior
+	// of the invoker.
+	ProcessingGuarantee string `json:"processingGuarantee,omitempty"`
+
+	// WorkloadType is the kind of workload running the Function, either
+	// Deployment or StatefulSet. Defaults to Deployment, or to StatefulSet
+	// with state or exactly-once processing. The pods of a
+	// StatefulSet have a stable identity used as `group.instance.id` so
+	// they are static members of the consumer group and a restart does not
+	// trigger a rebalance.
+	WorkloadType string `json:"workloadType,omitempty"`
+
+	// State gives each pod of the Function a persistent volume for its
+	// local state, e.g. RocksDB or caches, which survives restarts. The
+	// Function then runs as a StatefulSet.
+	State *StateSpec `json:"state,omitempty"`
+
+	// RackAwareness sets the client.rack of the consumer of each pod to
+	// the zone of its node so the consumer fetches from the closest
+	// replica. It requires Kafka 2.4 or later with a replica selector
+	// configured on the brokers.
+	RackAwareness *RackAwarenessSpec `json:"rackAwareness,omitempty"`
+
+	// Strategy describes how a new revision of the Function, i.e. a new
+	// image or a new configuration, is rolled out. It defaults to a rolling
+	// update of the Deployment.
+	Strategy *FunctionStrategy `json:"strategy,omitempty"`
+
+	// Shadow runs another version of the Function against the same input
+	// next to it. The shadow has its own consumer group and writes to its
+	// own topic so it does not affect the consumers of the output.
+	Shadow *ShadowSpec `json:"shadow,omitempty"`
+
+	// Suspend scales the Function to zero without changing Replicas, which
+	// are restored when the Function is resumed.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// PinImageDigest makes the operator resolve the tag of the image to
+	// its digest and run the digest. The tag is resolved again only when
+	// the image changes or when the kfn.dajac.io/refresh-digest annotation
+	// changes, so the pods of the Function always run the same build. The
+	// image of the shadow is pinned too.
+	PinImageDigest bool `json:"pinImageDigest,omitempty"`
+
+	// RevisionHistoryLimit is the number of FunctionRevisions kept for
+	// the Function. Defaults to 10.
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// SchemaRegistrySpec describes how to reach the Schema Registry.
+type SchemaRegistrySpec struct {
+	// URL is the URL of the Schema Registry.
+	URL string `json:"url"`
+
+	// AuthSecret is the name of a Secret with the `username` and the
+	// `password` used to authenticate to the Schema Registry. The
+	// credentials are only read by the pods.
+	AuthSecret string `json:"authSecret,omitempty"`
+
+	// SubjectNameStrategy is TopicNameStrategy, RecordNameStrategy,
+	// TopicRecordNameStrategy or the class name of a strategy. It applies
+	// to both keys and values. Defaults to the strategy of the serializers.
+	SubjectNameStrategy string `json:"subjectNameStrategy,omitempty"`
+}
+
+const (
+	// AtLeastOnce processes each record at least once.
+	AtLeastOnce = "at-least-once"
+
+	// ExactlyOnce processes each record exactly once.
+	ExactlyOnce = "exactly-once"
+)
+
+const (
+	// RetainState keeps the volumes when the Function is deleted.
+	RetainState = "Retain"
+
+	// DeleteState deletes the volumes with the Function.
+	DeleteState = "Delete"
+)
+
+// ConfigFromSource is a property whose value is read from a key of a
+// ConfigMap or a Secret. Exactly one of them must be set.
+type ConfigFromSource struct {
+	// Name is the name of the property.
+	Name string `json:"name"`
+
+	// ConfigMapKeyRef selects a key of a ConfigMap. The value is written in
+	// the properties file of the Function, a change of the value rolls
+	// the Function.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret. The value is not written in
+	// the ConfigMap of the Function: it is escaped and added to the
+	// properties when the container starts. A change of the value rolls
+	// the Function.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// FileSpec mounts the entries of a ConfigMap or a Secret in the pods of a
+// Function. Exactly one of them must be set. A change of the entries rolls
+// the Function.
+type FileSpec struct {
+	// MountPath is the directory holding the entries.
+	MountPath string `json:"mountPath"`
+
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	Secret    *corev1.SecretVolumeSource    `json:"secret,omitempty"`
+}
+
+// RackAwarenessSpec describes how the zone of the pods is found.
+type RackAwarenessSpec struct {
+	// NodeLabel is the label of the nodes holding their zone. Defaults to
+	// topology.kubernetes.io/zone, then
+	// failure-domain.beta.kubernetes.io/zone.
+	NodeLabel string `json:"nodeLabel,omitempty"`
+}
+
+// StateSpec describes the persistent volume of each pod of a Function.
+type StateSpec struct {
+	// StorageClassName is the storage class of the volumes. Defaults to
+	// the default storage class of the cluster.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the size of each volume, e.g. 10Gi. Defaults to 1Gi. It
+	// can only be increased, provided that the storage class allows
+	// volume expansion.
+	Size string `json:"size,omitempty"`
+
+	// MountPath is the path of the volume in the container. Defaults to
+	// /var/lib/kfn/state. It is passed to the Function as `state.dir`.
+	MountPath string `json:"mountPath,omitempty"`
+
+	// RetentionPolicy is Retain or Delete. Defaults to Retain.
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+}
+
+// ShadowSpec describes the version of a Function run in shadow mode. The
+// fields which are not set are inherited from the Function.
+type ShadowSpec struct {
+	// Image is the Docker image of the shadow.
+	Image string `json:"image,omitempty"`
+
+	// Class is the fully qualified class name of the shadow.
+	Class string `json:"class,omitempty"`
+
+	// Replicas is the expected number of shadows. Defaults to 1.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Output is the name of the topic written by the shadow. Defaults to
+	// the output of the Function suffixed by `.shadow`.
+	Output string `json:"output,omitempty"`
+
+	// ConsumerGroup is the consumer group of the shadow. Defaults to
+	// `<namespace>_<function>_shadow`, which can't be the default consumer
+	// group of a Function. It can't be the consumer group of the Function.
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+
+	// FunctionConfig is merged into the FunctionConfig of the Function.
+	FunctionConfig *map[string]string `json:"function,omitempty"`
+
+	// ConsumerConfig is merged into the ConsumerConfig of the Function.
+	ConsumerConfig *map[string]string `json:"consumer,omitempty"`
+
+	// ProducerConfig is merged into the ProducerConfig of the Function.
+	ProducerConfig *map[string]string `json:"producer,omitempty"`
+}
+
+const (
+	// DeploymentWorkload runs the Function with a Deployment.
+	DeploymentWorkload = "Deployment"
+
+	// StatefulSetWorkload runs the Function with a StatefulSet.
+	StatefulSetWorkload = "StatefulSet"
+)
+
+const (
+	// ClassInvocation calls the Class loaded from the image of the Function.
+	ClassInvocation = "class"
+
+	// HTTPInvocation forwards the records to a container of the pod over
+	// HTTP.
+	HTTPInvocation = "http"
+)
+
+// InvocationSpec describes how the invoker calls the Function.
+type InvocationSpec struct {
+	// Type is either class or http.
+	Type string `json:"type"`
+
+	// HTTP configures the http invocation.
+	HTTP *HTTPInvocationSpec `json:"http,omitempty"`
+}
+
+// HTTPInvocationSpec describes the container of a Function invoked over HTTP.
+// The invoker sends each record, or each batch of records, to the
+// container over localhost and produces the records of the response.
+type HTTPInvocationSpec struct {
+	// Image is the Docker image of the container of the Function. It can
+	// be written in any language.
+	Image string `json:"image"`
+
+	// Port is the port the container listens on. Defaults to 8080.
+	Port int32 `json:"port,omitempty"`
+
+	// Path is the path the records are posted to. Defaults to /.
+	Path string `json:"path,omitempty"`
+
+	// TimeoutMillis is the time the invoker waits for a response before
+	// retrying the request. Defaults to 30000.
+	TimeoutMillis *int32 `json:"timeoutMillis,omitempty"`
+
+	// Concurrency is the maximum number of requests in flight per pod.
+	// Defaults to 1, which preserves the order of the records.
+	Concurrency *int32 `json:"concurrency,omitempty"`
+
+	// BatchSize is the maximum number of records sent in a request.
+	// Defaults to 1, one record per request.
+	BatchSize *int32 `json:"batchSize,omitempty"`
+
+	// Env are the environment variables of the container.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// RouteSpec sends the records matching its condition to a topic. Exactly
+// one of Header, KeyPattern and Expression must be set.
+type RouteSpec struct {
+	// Name identifies the route. It must be unique in the Function.
+	Name string `json:"name"`
+
+	// Header matches the records by the value of a header.
+	Header *HeaderMatch `json:"header,omitempty"`
+
+	// KeyPattern is a regular expression matching the whole key of the
+	// records, read as a string. The operator checks it with the RE2
+	// syntax of Go while the invoker evaluates it with java.util.regex, so
+	// it must only use the syntax common to both: no backreferences,
+	// lookarounds or possessive quantifiers, which RE2 rejects, and no
+	// (?P<name>) groups, which Java rejects.
+	KeyPattern string `json:"keyPattern,omitempty"`
+
+	// Expression is a boolean CEL expression evaluated against the record,
+	// see BuiltinSpec.
+	Expression string `json:"expression,omitempty"`
+
+	// Output is the name of the topic of the route. The operator only
+	// checks that it is a valid topic name, not that the topic exists.
+	Output string `json:"output"`
+
+	// OutputKeySerializer and OutputValueSerializer are the serializers
+	// of the route. See InputKeyDeserializer for details. They default to
+	// the serializers of the Function.
+	OutputKeySerializer   string `json:"outputKeySerializer,omitempty"`
+	OutputValueSerializer string `json:"outputValueSerializer,omitempty"`
+}
+
+// HeaderMatch matches the records by the value of a header.
+type HeaderMatch struct {
+	// Name is the name of the header.
+	Name string `json:"name"`
+
+	// Value is the value of the header, read as a UTF-8 string. An empty
+	// value matches all the records with the header.
+	Value string `json:"value,omitempty"`
+}
+
+// BuiltinSpec describes a declarative Function. The expressions are written
+// in CEL and evaluated against each consumed record, which is available as
+// key, value, headers, topic, partition, offset and timestamp. At least one
+// of them must be set.
+type BuiltinSpec struct {
+	// Filter is a boolean expression. Only the records for which it is
+	// true are produced.
+	Filter string `json:"filter,omitempty"`
+
+	// Map replaces the key and the value of the produced records.
+	Map *BuiltinMap `json:"map,omitempty"`
+
+	// Route is a string expression returning the topic each record is
+	// produced to. Defaults to Output.
+	Route string `json:"route,omitempty"`
+}
+
+// BuiltinMap holds the expressions of the key and the value of the produced
+// records. Their type must match the serializers of the Function.
+type BuiltinMap struct {
+	// Key is the expression of the key. Defaults to the consumed key.
+	Key string `json:"key,omitempty"`
+
+	// Value is the expression of the value. Defaults to the consumed
+	// value.
+	Value string `json:"value,omitempty"`
+}
+
+const (
+	// RollingUpdateStrategy replaces the pods of the Deployment.
+	RollingUpdateStrategy = "RollingUpdate"
+
+	// CanaryStrategy runs the new revision in a second Deployment sharing
+	// the consumer group and shifts the replicas to it step by step. It is
+	// only supported by the Deployment workload: it is rejected for the
+	// Functions running as a StatefulSet, i.e. with the StatefulSet
+	// workload, a state or the exactly-once processing guarantee.
+	CanaryStrategy = "Canary"
+)
+
+// FunctionStrategy describes how a new revision of a Function is rolled out.
+type FunctionStrategy struct {
+	// Type is either RollingUpdate or Canary.
+	Type string `json:"type"`
+
+	// Canary configures the Canary strategy.
+	Canary *CanaryConfig `json:"canary,omitempty"`
+}
+
+// CanaryConfig configures a canary rollout.
+type CanaryConfig struct {
+	// Steps are the numbers of replicas running the new revision at each
+	// step. The new revision gets all the replicas after the last step.
+	// Defaults to a single step with one replica.
+	Steps []int32 `json:"steps,omitempty"`
+
+	// IntervalSeconds is the time spent at each step before checking the
+	// health of the new revision. Defaults to 60 seconds.
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// MaxRestarts is the number of container restarts of the new revision
+	// above which the rollout is rolled back. Defaults to 0.
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+
+	// MaxLag is the lag of the consumer group on the input topic above
+	// which the rollout is rolled back. It is checked at the end of each
+	// step by the operator, which connects to the bootstrap servers of the
+	// Function, so the consumer must use a PLAINTEXT listener.
+	MaxLag *int64 `json:"maxLag,omitempty"`
 }
 
 // FunctionStatus describes the status of a KFn Function
 type FunctionStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration"`
 	AvailableReplicas  int32 `json:"availableReplicas"`
+
+	// ConfigError explains why the configuration of the Function is
+	// rejected. The resources of the Function are not updated until it
+	// is fixed.
+	ConfigError string `json:"configError,omitempty"`
+
+	// Warnings are the consumer and producer properties of the Function
+	// which are unknown to the Kafka clients and ignored by them.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Suspended is true when the Function has been scaled to zero by
+	// Suspend.
+	Suspended bool `json:"suspended"`
+
+	// Canary is the state of the last canary rollout.
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
+	// Shadow is the state of the shadow. It is only set when the Function
+	// has one.
+	Shadow *ShadowStatus `json:"shadow,omitempty"`
+
+	// Image is the image pinned by digest. It is only set when
+	// PinImageDigest is enabled.
+	Image *ImageStatus `json:"image,omitempty"`
+
+	// ShadowImage is the image of the shadow pinned by digest. It is only
+	// set when PinImageDigest is enabled and the shadow has its own image.
+	ShadowImage *ImageStatus `json:"shadowImage,omitempty"`
+
+	// State is the state of the volumes. It is only set when the Function
+	// has state.
+	State *StateStatus `json:"state,omitempty"`
+
+	// CurrentRevision is the name of the FunctionRevision matching the
+	// spec of the Function.
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// DryRun is the result of the last dry run. It is only set when
+	// the Function has the kfn.dajac.io/dry-run annotation.
+	DryRun *DryRunResult `json:"dryRun,omitempty"`
+
+	// Conditions are the latest observations of the Function.
+	Conditions []FunctionCondition `json:"conditions,omitempty"`
+}
+
+const (
+	// InCycleCondition is true when the output of the Function
+	// eventually feeds its own input.
+	InCycleCondition = "InCycle"
+)
+
+// FunctionCondition describes an observation of a Function.
+type FunctionCondition struct {
+	// Type is the type of the condition, e.g. InCycle.
+	Type string `json:"type"`
+
+	// Status is True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Reason is a CamelCase reason for the last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable explanation of the condition.
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the last time the status changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DryRunResult describes the changes which would be applied to the
+// resources of a Function.
+type DryRunResult struct {
+	// ConfigHashChanged is true when the rendered properties change.
+	ConfigHashChanged bool `json:"configHashChanged"`
+
+	// RollingRestart is true when the pods would be restarted.
+	RollingRestart bool `json:"rollingRestart"`
+
+	// Differences lists the field-level differences between the live
+	// and the desired resources.
+	Differences []string `json:"differences,omitempty"`
+
+	// ConfigError is the error which the controller would report when
+	// applying the changes. Nothing is compared when it is set.
+	ConfigError string `json:"configError,omitempty"`
+}
+
+const (
+	// CanaryProgressing means that the replicas are being shifted.
+	CanaryProgressing = "Progressing"
+
+	// CanarySucceeded means that the new revision has been promoted.
+	CanarySucceeded = "Succeeded"
+
+	// CanaryRolledBack means that the new revision has been removed. It
+	// is not rolled out again until the Function changes.
+	CanaryRolledBack = "RolledBack"
+)
+
+// CanaryStatus describes a canary rollout.
+type CanaryStatus struct {
+	// Phase is Progressing, Succeeded or RolledBack.
+	Phase string `json:"phase"`
+
+	// Revision identifies the image and the configuration rolled out.
+	Revision string `json:"revision"`
+
+	// Step is the index of the current step.
+	Step int32 `json:"step"`
+
+	// Replicas is the number of replicas running the new revision.
+	Replicas int32 `json:"replicas"`
+
+	// LastTransitionTime is the time of the last step.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// Message explains the last transition.
+	Message string `json:"message,omitempty"`
+
+	// History records the steps of the rollout.
+	History []CanaryStep `json:"history,omitempty"`
+}
+
+// CanaryStep is a step of a canary rollout.
+type CanaryStep struct {
+	Step     int32       `json:"step"`
+	Replicas int32       `json:"replicas"`
+	Time     metav1.Time `json:"time"`
+	Message  string      `json:"message"`
+}
+
+// StateStatus describes the volumes of a Function.
+type StateStatus struct {
+	// Size is the size requested for each volume.
+	Size string `json:"size,omitempty"`
+
+	// Claims is the number of PersistentVolumeClaims of the Function.
+	Claims int32 `json:"claims"`
+
+	// Error explains why the volumes could not be updated.
+	Error string `json:"error,omitempty"`
+}
+
+// ImageStatus describes the resolution of the image of a Function.
+type ImageStatus struct {
+	// Image is the image of the spec which has been resolved.
+	Image string `json:"image"`
+
+	// Digest is the digest of the image.
+	Digest string `json:"digest,omitempty"`
+
+	// Pinned is the image reference run by the pods.
+	Pinned string `json:"pinned,omitempty"`
+
+	// ResolvedAt is the time of the resolution.
+	ResolvedAt metav1.Time `json:"resolvedAt,omitempty"`
+
+	// Refresh is the value of the kfn.dajac.io/refresh-digest annotation
+	// at the time of the resolution.
+	Refresh string `json:"refresh,omitempty"`
+
+	// Error explains why the image could not be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// ShadowStatus describes the shadow of a Function.
+type ShadowStatus struct {
+	ConsumerGroup     string `json:"consumerGroup"`
+	Output            string `json:"output"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+
+	// Error explains why the shadow is not running.
+	Error string `json:"error,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -85,3 +641,284 @@ type FunctionList struct {
 
 	Items []Function `json:"items"`
 }
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionRevision is an immutable snapshot of a KFn Function. The
+// controller creates one each time the spec or the rendered properties of
+// the Function change.
+type FunctionRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionRevisionSpec `json:"spec"`
+}
+
+// FunctionRevisionSpec is the content of a FunctionRevision
+type FunctionRevisionSpec struct {
+	// Function is the name of the Function.
+	Function string `json:"function"`
+
+	// Revision is the sequence number of the revision for the Function.
+	Revision int64 `json:"revision"`
+
+	// Hash identifies the spec and the rendered properties.
+	Hash string `json:"hash"`
+
+	// Image is the Docker image of the Function.
+	Image string `json:"image"`
+
+	// Properties is the rendered function.properties.
+	Properties string `json:"properties"`
+
+	// FunctionSpec is the spec of the Function.
+	FunctionSpec FunctionSpec `json:"functionSpec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionRevisionList is a list of FunctionRevision
+type FunctionRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FunctionRevision `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionProfile is a cluster wide set of consumer and producer properties
+// which Functions refer to by name with their profile. A FunctionProfile
+// named after a built-in profile replaces it.
+type FunctionProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionProfileSpec `json:"spec"`
+}
+
+// FunctionProfileSpec is the content of a FunctionProfile
+type FunctionProfileSpec struct {
+	// Description explains the workload the profile is tuned for.
+	Description string `json:"description,omitempty"`
+
+	// ConsumerConfig is a set of key-value pairs which will be passed to
+	// the Kafka Consumer.
+	ConsumerConfig *map[string]string `json:"consumer,omitempty"`
+
+	// ProducerConfig is a set of key-value pairs which will be passed to
+	// the Kafka Producer.
+	ProducerConfig *map[string]string `json:"producer,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionProfileList is a list of FunctionProfile
+type FunctionProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FunctionProfile `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionRuntime describes how the invoker of a runtime is started. The
+// Functions refer to it by name with their runtime. A FunctionRuntime named
+// after a built-in runtime replaces it.
+type FunctionRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionRuntimeSpec `json:"spec"`
+}
+
+// FunctionRuntimeSpec is the content of a FunctionRuntime
+type FunctionRuntimeSpec struct {
+	// Description describes the runtime.
+	Description string `json:"description,omitempty"`
+
+	// Command starts the invoker. The path of the configuration file is
+	// appended to it. It is started by /bin/sh, which adds the properties
+	// only known in the pod, so the images must provide a shell.
+	Command []string `json:"command"`
+
+	// ConfigFormat is the format of the configuration file: properties,
+	// the default, or json.
+	ConfigFormat string `json:"configFormat,omitempty"`
+
+	// ConfigPath is the directory where the configuration file is
+	// mounted. Defaults to /etc/kfn.
+	ConfigPath string `json:"configPath,omitempty"`
+
+	// WorkPath is the writable directory where the configuration file is
+	// completed with the properties only known in the pod. Defaults to
+	// /var/run/kfn.
+	WorkPath string `json:"workPath,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionRuntimeList is a list of FunctionRuntime
+type FunctionRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FunctionRuntime `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Pipeline describes a chain of KFn Functions connected through
+// intermediate topics
+type Pipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PipelineSpec   `json:"spec"`
+	Status PipelineStatus `json:"status"`
+}
+
+// PipelineInput can be used in PipelineStep.From to consume the input
+// topic of the Pipeline.
+const PipelineInput = "input"
+
+// PipelineSpec is the specification of a KFn Pipeline ressource
+type PipelineSpec struct {
+	// Input is the name of the input topic of the Pipeline.
+	Input string `json:"input"`
+
+	// InputKeyDeserializer is the name of the deserializer used to
+	// deserialize the key of the messages of the input topic.
+	// See FunctionSpec.InputKeyDeserializer for details.
+	InputKeyDeserializer string `json:"inputKeyDeserializer"`
+
+	// InputValueDeserializer is the name of the deserializer used to
+	// deserialize the value of the messages of the input topic.
+	// See FunctionSpec.InputKeyDeserializer for details.
+	InputValueDeserializer string `json:"inputValueDeserializer"`
+
+	// Output is the name of the output topic of the Pipeline. The steps
+	// which are not consumed by any other step write to it, unless they
+	// define their own output.
+	Output string `json:"output"`
+
+	// OutputKeySerializer is the name of the serializer used to
+	// serialize the key of the messages of the output topic.
+	// See FunctionSpec.InputKeyDeserializer for details.
+	OutputKeySerializer string `json:"outputKeySerializer"`
+
+	// OutputValueSerializer is the name of the serializer used to
+	// serialize the value of the messages of the output topic.
+	// See FunctionSpec.InputKeyDeserializer for details.
+	OutputValueSerializer string `json:"outputValueSerializer"`
+
+	// IntermediateKeyType is the type of the keys written to the
+	// intermediate topics. It is used for both the serializer of the
+	// producing step and the deserializer of the consuming steps so it
+	// must be one of the types supported by the Functions (bytes, string,
+	// double, float, int, long, short, avro, json-schema, protobuf). The
+	// avro, json-schema and protobuf types require schema.registry.url in
+	// the consumer and producer of the steps. Defaults to bytes.
+	IntermediateKeyType string `json:"intermediateKeyType,omitempty"`
+
+	// IntermediateValueType is the type of the values written to the
+	// intermediate topics. See IntermediateKeyType for details.
+	IntermediateValueType string `json:"intermediateValueType,omitempty"`
+
+	// TopicPrefix is the prefix of the intermediate topics which are
+	// named <prefix>.<step>. Defaults to the name of the Pipeline.
+	TopicPrefix string `json:"topicPrefix,omitempty"`
+
+	// Steps are the Functions of the Pipeline.
+	Steps []PipelineStep `json:"steps"`
+}
+
+// PipelineStep is a Function of a Pipeline
+type PipelineStep struct {
+	// Name is the name of the step. The Function is named
+	// <pipeline>-<step>.
+	Name string `json:"name"`
+
+	// From are the names of the steps consumed by this step, or "input"
+	// for the input topic of the Pipeline. Defaults to the previous step,
+	// or to the input topic for the first step. Several steps can consume
+	// the same step and a step can consume several steps (fan-in): they
+	// all write to the intermediate topic of the step consuming them, so
+	// a step merged into another one can't be consumed by other steps and
+	// the input of the Pipeline can't be merged with steps.
+	From []string `json:"from,omitempty"`
+
+	// Output overrides the output topic of the Pipeline for this step. It
+	// can only be set on steps which are not consumed by other steps.
+	Output string `json:"output,omitempty"`
+
+	// Image is the Docker image of the Function.
+	Image string `json:"image"`
+
+	// Replicas is the expected number of Function. Defaults to 1 when the
+	// Function is created, the Function can then be scaled on its own.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Class is the fully qualified class name of the Function.
+	Class string `json:"class"`
+
+	// FunctionConfig is a set of key-value pairs which will be passed to
+	// the Function via the `configure` method.
+	FunctionConfig *map[string]string `json:"function,omitempty"`
+
+	// ConsumerConfig is a set of key-value pairs which will be passed to
+	// the Kafka Consumer.
+	ConsumerConfig *map[string]string `json:"consumer,omitempty"`
+
+	// ProducerConfig is a set of key-value pairs which will be passed to
+	// the Kafka Producer.
+	ProducerConfig *map[string]string `json:"producer,omitempty"`
+}
+
+// PipelineStatus describes the status of a KFn Pipeline
+type PipelineStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// Steps is the number of steps of the Pipeline.
+	Steps int32 `json:"steps"`
+
+	// ReadySteps is the number of steps whose Function is ready.
+	ReadySteps int32 `json:"readySteps"`
+
+	// Error is set when the Pipeline is invalid.
+	Error string `json:"error,omitempty"`
+
+	// StepStatuses describes the Function of each step.
+	StepStatuses []PipelineStepStatus `json:"stepStatuses,omitempty"`
+}
+
+// PipelineStepStatus describes the Function of a step
+type PipelineStepStatus struct {
+	Name              string `json:"name"`
+	Function          string `json:"function"`
+	Input             string `json:"input"`
+	Output            string `json:"output"`
+	Replicas          int32  `json:"replicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+	Ready             bool   `json:"ready"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PipelineList is a list of Pipeline
+type PipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Pipeline `json:"items"`
+}
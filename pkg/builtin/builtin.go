@@ -0,0 +1,83 @@
+// Package builtin type-checks the CEL expressions of the builtin Functions
+// before they are evaluated by the invoker.
+package builtin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Record holds the types of the key and of the value of the records the
+// expressions are evaluated against.
+type Record struct {
+	Key   *cel.Type
+	Value *cel.Type
+}
+
+// Variables are the variables of the expressions, by name. key and value
+// are typed by the Record.
+var Variables = []string{"key", "value", "headers", "topic", "partition", "offset", "timestamp"}
+
+// SerdeType returns the type of the keys or the values read by a
+// deserializer, or written by a serializer, named as in the Function. The
+// records of the schema based and of the custom serializers are dynamic,
+// e.g. the fields of an Avro record are read as a map.
+func SerdeType(name string) *cel.Type {
+	switch name {
+	case "bytes":
+		return cel.BytesType
+	case "string":
+		return cel.StringType
+	case "double", "float":
+		return cel.DoubleType
+	case "int", "long", "short":
+		return cel.IntType
+	default:
+		return cel.DynType
+	}
+}
+
+// NewEnv returns the environment of the expressions evaluated against the
+// records.
+func NewEnv(record Record) (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("key", record.Key),
+		cel.Variable("value", record.Value),
+		cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("topic", cel.StringType),
+		cel.Variable("partition", cel.IntType),
+		cel.Variable("offset", cel.IntType),
+		cel.Variable("timestamp", cel.TimestampType),
+	)
+}
+
+// Check compiles the expression and checks that its result has the
+// expected type. A dynamic result is only known when the expression is
+// evaluated so it is accepted.
+func Check(env *cel.Env, expression string, expected *cel.Type) error {
+	if strings.TrimSpace(expression) == "" {
+		return fmt.Errorf("empty expression")
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		messages := []string{}
+		for _, e := range issues.Errors() {
+			messages = append(messages, fmt.Sprintf("%d:%d: %s", e.Location.Line(), e.Location.Column()+1, e.Message))
+		}
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+
+	output := ast.OutputType()
+	if output.String() == cel.DynType.String() {
+		return nil
+	}
+
+	if !expected.IsAssignableType(output) {
+		return fmt.Errorf("returns %s, must return %s", output, expected)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestSerdeType(t *testing.T) {
+	tests := map[string]*cel.Type{
+		"bytes":                        cel.BytesType,
+		"string":                       cel.StringType,
+		"float":                        cel.DoubleType,
+		"short":                        cel.IntType,
+		"avro":                         cel.DynType,
+		"com.example.CustomSerializer": cel.DynType,
+	}
+
+	for name, expected := range tests {
+		if actual := SerdeType(name); actual.String() != expected.String() {
+			t.Errorf("%s: type = %s, want %s", name, actual, expected)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	env, err := NewEnv(Record{Key: cel.StringType, Value: cel.DynType})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   *cel.Type
+		err        string
+	}{
+		{`key.startsWith("a") && partition < 3`, cel.BoolType, ""},
+		{`timestamp > timestamp("2024-01-01T00:00:00Z")`, cel.BoolType, ""},
+		{`headers["region"]`, cel.StringType, ""},
+		// The type of a dynamic value is only known at evaluation
+		{`value.amount`, cel.BoolType, ""},
+		{`offset + 1`, cel.BoolType, "returns int, must return bool"},
+		{`  `, cel.BoolType, "empty expression"},
+		{`key >`, cel.BoolType, "1:"},
+		{`record.key`, cel.StringType, "undeclared reference to 'record'"},
+	}
+
+	for _, test := range tests {
+		err := Check(env, test.expression, test.expected)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %q", test.expression, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: expected an error", test.expression)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: error %q does not contain %q", test.expression, err.Error(), test.err)
+		}
+	}
+}
@@ -32,6 +32,22 @@ func (c *FakeKfnV1alpha1) Functions(namespace string) v1alpha1.FunctionInterface
 	return &FakeFunctions{c, namespace}
 }
 
+func (c *FakeKfnV1alpha1) FunctionProfiles() v1alpha1.FunctionProfileInterface {
+	return &FakeFunctionProfiles{c}
+}
+
+func (c *FakeKfnV1alpha1) FunctionRevisions(namespace string) v1alpha1.FunctionRevisionInterface {
+	return &FakeFunctionRevisions{c, namespace}
+}
+
+func (c *FakeKfnV1alpha1) FunctionRuntimes() v1alpha1.FunctionRuntimeInterface {
+	return &FakeFunctionRuntimes{c}
+}
+
+func (c *FakeKfnV1alpha1) Pipelines(namespace string) v1alpha1.PipelineInterface {
+	return &FakePipelines{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKfnV1alpha1) RESTClient() rest.Interface {
@@ -19,3 +19,11 @@ limitations under the License.
 package v1alpha1
 
 type FunctionExpansion interface{}
+
+type FunctionProfileExpansion interface{}
+
+type FunctionRevisionExpansion interface{}
+
+type FunctionRuntimeExpansion interface{}
+
+type PipelineExpansion interface{}
@@ -28,6 +28,10 @@ import (
 type KfnV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	FunctionsGetter
+	FunctionProfilesGetter
+	FunctionRevisionsGetter
+	FunctionRuntimesGetter
+	PipelinesGetter
 }
 
 // KfnV1alpha1Client is used to interact with features provided by the kfn.dajac.io group.
@@ -39,6 +43,22 @@ func (c *KfnV1alpha1Client) Functions(namespace string) FunctionInterface {
 	return newFunctions(c, namespace)
 }
 
+func (c *KfnV1alpha1Client) FunctionProfiles() FunctionProfileInterface {
+	return newFunctionProfiles(c)
+}
+
+func (c *KfnV1alpha1Client) FunctionRevisions(namespace string) FunctionRevisionInterface {
+	return newFunctionRevisions(c, namespace)
+}
+
+func (c *KfnV1alpha1Client) FunctionRuntimes() FunctionRuntimeInterface {
+	return newFunctionRuntimes(c)
+}
+
+func (c *KfnV1alpha1Client) Pipelines(namespace string) PipelineInterface {
+	return newPipelines(c, namespace)
+}
+
 // NewForConfig creates a new KfnV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*KfnV1alpha1Client, error) {
 	config := *c
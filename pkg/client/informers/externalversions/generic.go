@@ -55,6 +55,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=kfn.dajac.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("functions"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kfn().V1alpha1().Functions().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("functionprofiles"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kfn().V1alpha1().FunctionProfiles().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("functionrevisions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kfn().V1alpha1().FunctionRevisions().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("functionruntimes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kfn().V1alpha1().FunctionRuntimes().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pipelines"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kfn().V1alpha1().Pipelines().Informer()}, nil
 
 	}
 
@@ -26,6 +26,14 @@ import (
 type Interface interface {
 	// Functions returns a FunctionInformer.
 	Functions() FunctionInformer
+	// FunctionProfiles returns a FunctionProfileInformer.
+	FunctionProfiles() FunctionProfileInformer
+	// FunctionRevisions returns a FunctionRevisionInformer.
+	FunctionRevisions() FunctionRevisionInformer
+	// FunctionRuntimes returns a FunctionRuntimeInformer.
+	FunctionRuntimes() FunctionRuntimeInformer
+	// Pipelines returns a PipelineInformer.
+	Pipelines() PipelineInformer
 }
 
 type version struct {
@@ -43,3 +51,23 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) Functions() FunctionInformer {
 	return &functionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// FunctionProfiles returns a FunctionProfileInformer.
+func (v *version) FunctionProfiles() FunctionProfileInformer {
+	return &functionProfileInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// FunctionRevisions returns a FunctionRevisionInformer.
+func (v *version) FunctionRevisions() FunctionRevisionInformer {
+	return &functionRevisionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// FunctionRuntimes returns a FunctionRuntimeInformer.
+func (v *version) FunctionRuntimes() FunctionRuntimeInformer {
+	return &functionRuntimeInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// Pipelines returns a PipelineInformer.
+func (v *version) Pipelines() PipelineInformer {
+	return &pipelineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
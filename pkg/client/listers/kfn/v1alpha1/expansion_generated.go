@@ -25,3 +25,27 @@ type FunctionListerExpansion interface{}
 // FunctionNamespaceListerExpansion allows custom methods to be added to
 // FunctionNamespaceLister.
 type FunctionNamespaceListerExpansion interface{}
+
+// FunctionProfileListerExpansion allows custom methods to be added to
+// FunctionProfileLister.
+type FunctionProfileListerExpansion interface{}
+
+// FunctionRevisionListerExpansion allows custom methods to be added to
+// FunctionRevisionLister.
+type FunctionRevisionListerExpansion interface{}
+
+// FunctionRevisionNamespaceListerExpansion allows custom methods to be added to
+// FunctionRevisionNamespaceLister.
+type FunctionRevisionNamespaceListerExpansion interface{}
+
+// FunctionRuntimeListerExpansion allows custom methods to be added to
+// FunctionRuntimeLister.
+type FunctionRuntimeListerExpansion interface{}
+
+// PipelineListerExpansion allows custom methods to be added to
+// PipelineLister.
+type PipelineListerExpansion interface{}
+
+// PipelineNamespaceListerExpansion allows custom methods to be added to
+// PipelineNamespaceLister.
+type PipelineNamespaceListerExpansion interface{}
@@ -0,0 +1,352 @@
+package function
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+const (
+	defaultCanaryInterval = 60 * time.Second
+
+	// maxCanaryHistory is the number of steps kept in the status.
+	maxCanaryHistory = 20
+)
+
+// LagChecker measures the lag of a consumer group on its input topics. The
+// MaxLag of the canary rollouts is only checked when the controller has
+// one.
+type LagChecker interface {
+	Lag(bootstrap string, group string, topics []string) (int64, error)
+}
+
+// SetLagChecker sets the LagChecker used by the canary rollouts.
+func (c *Controller) SetLagChecker(lagChecker LagChecker) {
+	c.lagChecker = lagChecker
+}
+
+// isCanary returns true if the Function is rolled out with the Canary
+// strategy. A StatefulSet is always rolled out by itself, ValidateStrategy
+// rejects the Canary strategy for it.
+func isCanary(function *kfnv1alpha1.Function) bool {
+	return function.Spec.Strategy != nil && function.Spec.Strategy.Type == kfnv1alpha1.CanaryStrategy && !render.IsStatefulSet(function)
+}
+
+// canaryName is the name of the ConfigMap and the Deployment running the
+// new revision during a canary rollout.
+func canaryName(function *kfnv1alpha1.Function) string {
+	return function.Name + "-canary"
+}
+
+// canaryLabels are the labels of the pods of the new revision. They must not
+// match the selector of the stable Deployment.
+func canaryLabels(function *kfnv1alpha1.Function) map[string]string {
+	return map[string]string{
+		"canary": function.Name,
+	}
+}
+
+// revision identifies what a pod template runs: the image, the properties
+// and the last restart.
+func revision(deployement *appsv1.Deployment) string {
+	return templateRevision(deployement.Spec.Template.ObjectMeta, deployement.Spec.Template.Spec)
+}
+
+func templateRevision(meta metav1.ObjectMeta, spec corev1.PodSpec) string {
+	h := sha256.New()
+	if len(spec.Containers) > 0 {
+		h.Write([]byte(spec.Containers[0].Image))
+	}
+	h.Write([]byte("\n" + meta.Annotations[render.ConfigHashAnnotation]))
+	h.Write([]byte("\n" + meta.Annotations[kfn.RestartedAtAnnotation]))
+	return hex.EncodeToString(h.Sum(nil))[:10]
+}
+
+// syncCanary rolls out a new revision of the Function next to the stable
+// one. The stable Deployment keeps running the previous revision while the
+// canary Deployment, which shares its consumer group, gets more and more
+// replicas. The new revision is promoted when it is healthy after the last
+// step and rolled back as soon as it is not.
+func (c *Controller) syncCanary(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig, status *kfnv1alpha1.FunctionStatus) (int32, error) {
+	newConfigMap := render.NewConfigMap(function, functionConfig)
+	newDeployement := render.NewDeployement(function, functionConfig, newConfigMap)
+	newRevision := revision(newDeployement)
+
+	stable, err := c.deployementLister.Deployments(function.Namespace).Get(function.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Nothing runs yet so there is nothing to protect
+			return c.syncRollingUpdate(function, functionConfig)
+		}
+		return 0, err
+	}
+
+	canary := status.Canary
+
+	if revision(stable) == newRevision {
+		if canary != nil && canary.Phase == kfnv1alpha1.CanaryProgressing {
+			transition(canary, kfnv1alpha1.CanaryRolledBack, "the function has been reverted to the stable revision")
+		}
+		return c.syncStable(function, stable)
+	}
+
+	if canary != nil && canary.Revision == newRevision && canary.Phase == kfnv1alpha1.CanaryRolledBack {
+		return c.syncStable(function, stable)
+	}
+
+	config := canaryConfig(function)
+
+	if canary == nil || canary.Revision != newRevision || canary.Phase != kfnv1alpha1.CanaryProgressing {
+		glog.Infof("Start canary rollout of revision %s for %s/%s", newRevision, function.Namespace, function.Name)
+
+		canary = &kfnv1alpha1.CanaryStatus{
+			Revision: newRevision,
+		}
+		status.Canary = canary
+		transition(canary, kfnv1alpha1.CanaryProgressing, fmt.Sprintf("started the rollout of revision %s", newRevision))
+		canary.Replicas = stepReplicas(function, config, 0)
+		record(canary)
+	}
+
+	canaryDeployement, err := c.syncCanaryResources(function, functionConfig, canary.Replicas)
+	if err != nil {
+		return 0, err
+	}
+
+	restarts, err := c.canaryRestarts(function, newRevision)
+	if err != nil {
+		return 0, err
+	}
+
+	if restarts > config.MaxRestarts {
+		return c.rollback(function, stable, canary, fmt.Sprintf("the new revision restarted %d times", restarts))
+	}
+
+	interval := defaultCanaryInterval
+	if config.IntervalSeconds != nil {
+		interval = time.Duration(*config.IntervalSeconds) * time.Second
+	}
+
+	if elapsed := time.Since(canary.LastTransitionTime.Time); elapsed < interval {
+		c.enqueueFunctionAfter(function, interval-elapsed)
+		return c.scaleStable(function, stable, canaryDeployement, canary.Replicas)
+	}
+
+	if canaryDeployement.Status.ReadyReplicas < canary.Replicas {
+		return c.rollback(function, stable, canary, fmt.Sprintf("%d/%d replicas of the new revision are ready after %s",
+			canaryDeployement.Status.ReadyReplicas, canary.Replicas, interval))
+	}
+
+	if config.MaxLag != nil && c.lagChecker != nil {
+		lag, err := c.lagChecker.Lag(functionConfig.Consumer["bootstrap.servers"], functionConfig.Consumer["group.id"], []string{function.Spec.Input})
+		if err != nil {
+			return 0, fmt.Errorf("can't measure the lag of %s/%s: %s", function.Namespace, function.Name, err.Error())
+		}
+
+		if lag > *config.MaxLag {
+			return c.rollback(function, stable, canary, fmt.Sprintf("the lag is %d", lag))
+		}
+	}
+
+	if int(canary.Step)+1 >= len(config.Steps) {
+		return c.promote(function, functionConfig, canary)
+	}
+
+	canary.Step++
+	canary.Replicas = stepReplicas(function, config, canary.Step)
+	transition(canary, kfnv1alpha1.CanaryProgressing, fmt.Sprintf("step %d: %d replicas run revision %s", canary.Step, canary.Replicas, newRevision))
+	record(canary)
+
+	glog.Infof("Canary rollout of %s/%s: %s", function.Namespace, function.Name, canary.Message)
+
+	canaryDeployement, err = c.syncCanaryResources(function, functionConfig, canary.Replicas)
+	if err != nil {
+		return 0, err
+	}
+
+	c.enqueueFunctionAfter(function, interval)
+
+	return c.scaleStable(function, stable, canaryDeployement, canary.Replicas)
+}
+
+// syncCanaryResources creates or updates the ConfigMap and the Deployment of
+// the new revision.
+func (c *Controller) syncCanaryResources(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig, replicas int32) (*appsv1.Deployment, error) {
+	configmap, err := c.syncConfigMap(function, render.NewNamedConfigMap(function, functionConfig, canaryName(function)))
+	if err != nil {
+		return nil, err
+	}
+
+	deployement := render.NewNamedDeployement(function, functionConfig, configmap, canaryName(function), canaryLabels(function))
+	deployement.Spec.Replicas = &replicas
+
+	return c.syncDeployement(function, deployement)
+}
+
+// promote rolls the new revision out to the stable Deployment and removes
+// the canary Deployment.
+func (c *Controller) promote(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig, canary *kfnv1alpha1.CanaryStatus) (int32, error) {
+	glog.Infof("Promote revision %s of %s/%s", canary.Revision, function.Namespace, function.Name)
+
+	transition(canary, kfnv1alpha1.CanarySucceeded, fmt.Sprintf("promoted revision %s", canary.Revision))
+	canary.Replicas = function.Spec.Replicas
+	record(canary)
+
+	return c.syncRollingUpdate(function, functionConfig)
+}
+
+// rollback removes the canary Deployment and gives all the replicas back to
+// the stable one.
+func (c *Controller) rollback(function *kfnv1alpha1.Function, stable *appsv1.Deployment, canary *kfnv1alpha1.CanaryStatus, reason string) (int32, error) {
+	glog.Infof("Roll back revision %s of %s/%s: %s", canary.Revision, function.Namespace, function.Name, reason)
+
+	transition(canary, kfnv1alpha1.CanaryRolledBack, "rolled back: "+reason)
+	canary.Replicas = 0
+	record(canary)
+
+	return c.syncStable(function, stable)
+}
+
+// syncStable removes the canary Deployment and scales the stable one to the
+// replicas of the Function.
+func (c *Controller) syncStable(function *kfnv1alpha1.Function, stable *appsv1.Deployment) (int32, error) {
+	if err := c.deleteCanary(function); err != nil {
+		return 0, err
+	}
+
+	return c.scaleStable(function, stable, nil, 0)
+}
+
+// scaleStable gives the replicas which don't run the new revision to the
+// stable Deployment.
+func (c *Controller) scaleStable(function *kfnv1alpha1.Function, stable *appsv1.Deployment, canaryDeployement *appsv1.Deployment, canaryReplicas int32) (int32, error) {
+	replicas := function.Spec.Replicas - canaryReplicas
+	if replicas < 0 {
+		replicas = 0
+	}
+
+	if *stable.Spec.Replicas != replicas {
+		glog.Infof("Scale Deployement %s/%s to %d replicas", stable.Namespace, stable.Name, replicas)
+
+		stable = stable.DeepCopy()
+		stable.Spec.Replicas = &replicas
+
+		var err error
+		stable, err = c.kubeClient.AppsV1().Deployments(stable.Namespace).Update(stable)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	available := stable.Status.AvailableReplicas
+	if canaryDeployement != nil {
+		available += canaryDeployement.Status.AvailableReplicas
+	}
+
+	return available, nil
+}
+
+// deleteCanary deletes the ConfigMap and the Deployment of the new revision
+// if they exist.
+func (c *Controller) deleteCanary(function *kfnv1alpha1.Function) error {
+	return c.deleteResources(function, canaryName(function))
+}
+
+// canaryRestarts returns the number of container restarts of the pods
+// running the revision.
+func (c *Controller) canaryRestarts(function *kfnv1alpha1.Function, rev string) (int32, error) {
+	pods, err := c.podLister.Pods(function.Namespace).List(labels.SelectorFromSet(canaryLabels(function)))
+	if err != nil {
+		return 0, err
+	}
+
+	var restarts int32
+	for _, pod := range pods {
+		if templateRevision(pod.ObjectMeta, pod.Spec) != rev {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+
+	return restarts, nil
+}
+
+// canaryConfig returns the canary configuration of the Function with its
+// defaults.
+func canaryConfig(function *kfnv1alpha1.Function) *kfnv1alpha1.CanaryConfig {
+	config := &kfnv1alpha1.CanaryConfig{}
+	if function.Spec.Strategy.Canary != nil {
+		config = function.Spec.Strategy.Canary.DeepCopy()
+	}
+
+	if len(config.Steps) == 0 {
+		config.Steps = []int32{1}
+	}
+
+	return config
+}
+
+// stepReplicas returns the replicas of the new revision at the step.
+func stepReplicas(function *kfnv1alpha1.Function, config *kfnv1alpha1.CanaryConfig, step int32) int32 {
+	replicas := config.Steps[step]
+	if replicas > function.Spec.Replicas {
+		replicas = function.Spec.Replicas
+	}
+	if replicas < 0 {
+		replicas = 0
+	}
+	return replicas
+}
+
+// resumeCanary restarts the current step of a rollout interrupted by a
+// suspension as its replicas have not run during the suspension.
+func resumeCanary(canary *kfnv1alpha1.CanaryStatus) {
+	if canary == nil || canary.Phase != kfnv1alpha1.CanaryProgressing {
+		return
+	}
+
+	transition(canary, kfnv1alpha1.CanaryProgressing, fmt.Sprintf("step %d: resumed", canary.Step))
+	record(canary)
+}
+
+func transition(canary *kfnv1alpha1.CanaryStatus, phase string, message string) {
+	canary.Phase = phase
+	canary.Message = message
+	canary.LastTransitionTime = metav1.Now()
+}
+
+func record(canary *kfnv1alpha1.CanaryStatus) {
+	canary.History = append(canary.History, kfnv1alpha1.CanaryStep{
+		Step:     canary.Step,
+		Replicas: canary.Replicas,
+		Time:     canary.LastTransitionTime,
+		Message:  canary.Message,
+	})
+
+	if len(canary.History) > maxCanaryHistory {
+		canary.History = canary.History[len(canary.History)-maxCanaryHistory:]
+	}
+}
+
+func (c *Controller) enqueueFunctionAfter(function *kfnv1alpha1.Function, delay time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(function)
+	if err != nil {
+		return
+	}
+	c.workqueue.AddAfter(key, delay)
+}
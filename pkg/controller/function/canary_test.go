@@ -0,0 +1,191 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func newCanaryFunction() *kfnv1alpha1.Function {
+	interval := int32(60)
+
+	function := newTestFunction("copy")
+	function.Spec.Replicas = 3
+	function.Spec.Strategy = &kfnv1alpha1.FunctionStrategy{
+		Type: kfnv1alpha1.CanaryStrategy,
+		Canary: &kfnv1alpha1.CanaryConfig{
+			Steps:           []int32{1, 2},
+			IntervalSeconds: &interval,
+		},
+	}
+	return function
+}
+
+// elapse ends the interval of the current step of the rollout.
+func (f *fixture) elapse(name string) *kfnv1alpha1.Function {
+	function := f.function(name)
+	function.Status.Canary.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+
+	function, err := f.kfnClient.KfnV1alpha1().Functions(function.Namespace).UpdateStatus(function)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.refresh()
+	return function
+}
+
+// ready sets the ready replicas of the Deployment.
+func (f *fixture) ready(name string, replicas int32) {
+	deployment := f.deployment(name)
+	deployment.Status.ReadyReplicas = replicas
+	deployment.Status.AvailableReplicas = replicas
+
+	if _, err := f.kubeClient.AppsV1().Deployments(deployment.Namespace).UpdateStatus(deployment); err != nil {
+		f.t.Fatal(err)
+	}
+	f.refresh()
+}
+
+func (f *fixture) checkReplicas(name string, expected int32) {
+	if replicas := *f.deployment(name).Spec.Replicas; replicas != expected {
+		f.t.Errorf("%s: replicas = %d, want %d", name, replicas, expected)
+	}
+}
+
+func (f *fixture) checkCanary(phase string, step int32, replicas int32) {
+	canary := f.function("copy").Status.Canary
+	if canary == nil || canary.Phase != phase || canary.Step != step || canary.Replicas != replicas {
+		f.t.Errorf("canary = %+v, want phase %s, step %d, %d replicas", canary, phase, step, replicas)
+	}
+}
+
+func TestCanaryPromote(t *testing.T) {
+	function := newCanaryFunction()
+	f := newFixture(t, render.FunctionDefaultConfig{}, function)
+
+	// The first revision is rolled out at once
+	f.sync(function)
+	f.checkReplicas("copy", 3)
+	f.ready("copy", 3)
+
+	function = f.function("copy")
+	function.Spec.Image = "dajac/kfn-examples:0.2.0"
+	function = f.update(function)
+
+	f.sync(function)
+	f.checkCanary(kfnv1alpha1.CanaryProgressing, 0, 1)
+	f.checkReplicas("copy", 2)
+	f.checkReplicas("copy-canary", 1)
+	if image := f.deployment("copy").Spec.Template.Spec.Containers[0].Image; image != "dajac/kfn-examples:0.1.0" {
+		t.Errorf("the stable Deployment must keep running the previous image, got %s", image)
+	}
+
+	// The step lasts for the interval
+	f.sync(f.function("copy"))
+	f.checkCanary(kfnv1alpha1.CanaryProgressing, 0, 1)
+
+	f.ready("copy-canary", 1)
+	f.sync(f.elapse("copy"))
+	f.checkCanary(kfnv1alpha1.CanaryProgressing, 1, 2)
+	f.checkReplicas("copy", 1)
+	f.checkReplicas("copy-canary", 2)
+
+	f.ready("copy-canary", 2)
+	f.sync(f.elapse("copy"))
+	f.checkCanary(kfnv1alpha1.CanarySucceeded, 1, 3)
+	f.checkReplicas("copy", 3)
+	if image := f.deployment("copy").Spec.Template.Spec.Containers[0].Image; image != "dajac/kfn-examples:0.2.0" {
+		t.Errorf("the new image must be promoted, got %s", image)
+	}
+	if _, err := f.kubeClient.AppsV1().Deployments("default").Get("copy-canary", metav1.GetOptions{}); err == nil {
+		t.Errorf("the canary Deployment must be deleted")
+	}
+}
+
+func TestCanaryRollback(t *testing.T) {
+	function := newCanaryFunction()
+	f := newFixture(t, render.FunctionDefaultConfig{}, function)
+	f.sync(function)
+
+	function = f.function("copy")
+	function.Spec.Image = "dajac/kfn-examples:0.2.0"
+	function = f.update(function)
+	f.sync(function)
+	f.checkReplicas("copy-canary", 1)
+
+	// The canary is not ready at the end of the step
+	f.sync(f.elapse("copy"))
+	f.checkCanary(kfnv1alpha1.CanaryRolledBack, 0, 0)
+	f.checkReplicas("copy", 3)
+	if _, err := f.kubeClient.AppsV1().Deployments("default").Get("copy-canary", metav1.GetOptions{}); err == nil {
+		t.Errorf("the canary Deployment must be deleted")
+	}
+
+	// The rolled back revision is not retried
+	f.sync(f.function("copy"))
+	f.checkCanary(kfnv1alpha1.CanaryRolledBack, 0, 0)
+	if image := f.deployment("copy").Spec.Template.Spec.Containers[0].Image; image != "dajac/kfn-examples:0.1.0" {
+		t.Errorf("the stable Deployment must keep running the previous image, got %s", image)
+	}
+}
+
+// fakeLagChecker reports the same lag for all the consumer groups.
+type fakeLagChecker struct {
+	lag    int64
+	topics []string
+}
+
+func (l *fakeLagChecker) Lag(bootstrap string, group string, topics []string) (int64, error) {
+	l.topics = topics
+	return l.lag, nil
+}
+
+func TestCanaryMaxLag(t *testing.T) {
+	maxLag := int64(10)
+
+	function := newCanaryFunction()
+	function.Spec.Strategy.Canary.MaxLag = &maxLag
+	f := newFixture(t, render.FunctionDefaultConfig{}, function)
+
+	lagChecker := &fakeLagChecker{lag: 100}
+	f.controller.SetLagChecker(lagChecker)
+
+	f.sync(function)
+	f.ready("copy", 3)
+
+	function = f.function("copy")
+	function.Spec.Image = "dajac/kfn-examples:0.2.0"
+	function = f.update(function)
+	f.sync(function)
+
+	// The canary is ready but lags behind
+	f.ready("copy-canary", 1)
+	f.sync(f.elapse("copy"))
+	f.checkCanary(kfnv1alpha1.CanaryRolledBack, 0, 0)
+	if message := f.function("copy").Status.Canary.Message; message != "rolled back: the lag is 100" {
+		t.Errorf("message = %s", message)
+	}
+	if len(lagChecker.topics) != 1 || lagChecker.topics[0] != "copy.input" {
+		t.Errorf("the lag must be measured on the input topic, got %v", lagChecker.topics)
+	}
+}
+
+func TestStepReplicas(t *testing.T) {
+	function := newCanaryFunction()
+	config := &kfnv1alpha1.CanaryConfig{Steps: []int32{1, 5, -1}}
+
+	for step, expected := range []int32{1, 3, 0} {
+		if replicas := stepReplicas(function, config, int32(step)); replicas != expected {
+			t.Errorf("step %d: replicas = %d, want %d", step, replicas, expected)
+		}
+	}
+
+	function.Spec.Strategy.Canary = nil
+	if steps := canaryConfig(function).Steps; len(steps) != 1 || steps[0] != 1 {
+		t.Errorf("steps = %v, want a single step with one replica", steps)
+	}
+}
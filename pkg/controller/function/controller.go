@@ -4,21 +4,33 @@ import (
 	"fmt"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	appslisters "k8s.io/client-go/listers/apps/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	eventrecord "k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
 	clientset "github.com/dajac/kfn/pkg/client/clientset/versioned"
+	kfnscheme "github.com/dajac/kfn/pkg/client/clientset/versioned/scheme"
 	informers "github.com/dajac/kfn/pkg/client/informers/externalversions/kfn/v1alpha1"
 	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/registry"
+	"github.com/dajac/kfn/pkg/render"
 	"github.com/golang/glog"
 )
 
@@ -27,35 +39,93 @@ type Controller struct {
 	kfnClient         clientset.Interface
 	deployementLister appslisters.DeploymentLister
 	deployementSynced cache.InformerSynced
+	statefulSetLister appslisters.StatefulSetLister
+	statefulSetSynced cache.InformerSynced
 	configMapLister   corelisters.ConfigMapLister
 	configMapSynched  cache.InformerSynced
+	secretLister      corelisters.SecretLister
+	secretSynced      cache.InformerSynced
+	podLister         corelisters.PodLister
+	podSynced         cache.InformerSynced
+	nodeLister        corelisters.NodeLister
+	nodeSynced        cache.InformerSynced
+	claimLister       corelisters.PersistentVolumeClaimLister
+	claimSynced       cache.InformerSynced
+	revisionLister    listers.FunctionRevisionLister
+	revisionSynced    cache.InformerSynced
+	profileLister     listers.FunctionProfileLister
+	profileSynced     cache.InformerSynced
+	runtimeLister     listers.FunctionRuntimeLister
+	runtimeSynced     cache.InformerSynced
 	functionLister    listers.FunctionLister
 	functionSynced    cache.InformerSynced
 
-	functionDefaultConfig FunctionDefaultConfig
+	functionDefaultConfig render.FunctionDefaultConfig
+
+	lagChecker    LagChecker
+	imageResolver registry.Resolver
 
 	workqueue workqueue.RateLimitingInterface
+
+	// zoneQueue holds the pods of the rack aware Functions waiting for
+	// their zone.
+	zoneQueue workqueue.RateLimitingInterface
+
+	// recorder records the events of the Functions.
+	recorder eventrecord.EventRecorder
 }
 
 func NewController(
 	kubeClient kubernetes.Interface,
 	kfnClient clientset.Interface,
 	deployementInformer appsinformers.DeploymentInformer,
+	statefulSetInformer appsinformers.StatefulSetInformer,
 	configMapInformer coreinformers.ConfigMapInformer,
+	secretInformer coreinformers.SecretInformer,
+	podInformer coreinformers.PodInformer,
+	nodeInformer coreinformers.NodeInformer,
+	claimInformer coreinformers.PersistentVolumeClaimInformer,
 	functionInformer informers.FunctionInformer,
-	functionBaseConfig FunctionDefaultConfig) *Controller {
+	revisionInformer informers.FunctionRevisionInformer,
+	profileInformer informers.FunctionProfileInformer,
+	runtimeInformer informers.FunctionRuntimeInformer,
+	functionBaseConfig render.FunctionDefaultConfig) *Controller {
+
+	// The events of the Functions reference them with their kind
+	kfnscheme.AddToScheme(scheme.Scheme)
+	eventBroadcaster := eventrecord.NewBroadcaster()
+	eventBroadcaster.StartLogging(glog.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 
 	controller := &Controller{
 		kubeClient:            kubeClient,
 		kfnClient:             kfnClient,
 		deployementLister:     deployementInformer.Lister(),
 		deployementSynced:     deployementInformer.Informer().HasSynced,
+		statefulSetLister:     statefulSetInformer.Lister(),
+		statefulSetSynced:     statefulSetInformer.Informer().HasSynced,
 		configMapLister:       configMapInformer.Lister(),
 		configMapSynched:      configMapInformer.Informer().HasSynced,
+		secretLister:          secretInformer.Lister(),
+		secretSynced:          secretInformer.Informer().HasSynced,
+		podLister:             podInformer.Lister(),
+		podSynced:             podInformer.Informer().HasSynced,
+		nodeLister:            nodeInformer.Lister(),
+		nodeSynced:            nodeInformer.Informer().HasSynced,
+		claimLister:           claimInformer.Lister(),
+		claimSynced:           claimInformer.Informer().HasSynced,
+		revisionLister:        revisionInformer.Lister(),
+		revisionSynced:        revisionInformer.Informer().HasSynced,
+		profileLister:         profileInformer.Lister(),
+		profileSynced:         profileInformer.Informer().HasSynced,
+		runtimeLister:         runtimeInformer.Lister(),
+		runtimeSynced:         runtimeInformer.Informer().HasSynced,
 		functionLister:        functionInformer.Lister(),
 		functionSynced:        functionInformer.Informer().HasSynced,
 		functionDefaultConfig: functionBaseConfig,
 		workqueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Functions"),
+		zoneQueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Zones"),
+		recorder:              eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "kfn-operator"}),
 	}
 
 	deployementInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -66,6 +136,14 @@ func NewController(
 		DeleteFunc: controller.handleObject,
 	})
 
+	statefulSetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+
 	configMapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.handleObject,
 		UpdateFunc: func(old, new interface{}) {
@@ -74,6 +152,43 @@ func NewController(
 		DeleteFunc: controller.handleObject,
 	})
 
+	// The Functions reading a ConfigMap or a Secret are rolled when it
+	// changes
+	configMapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.handleSource,
+		UpdateFunc: controller.updateSource,
+		DeleteFunc: controller.handleSource,
+	})
+
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.handleSource,
+		UpdateFunc: controller.updateSource,
+		DeleteFunc: controller.handleSource,
+	})
+
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handlePod,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handlePod(new)
+		},
+	})
+
+	profileInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleProfile,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleProfile(new)
+		},
+		DeleteFunc: controller.handleProfile,
+	})
+
+	runtimeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleRuntime,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleRuntime(new)
+		},
+		DeleteFunc: controller.handleRuntime,
+	})
+
 	functionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.enqueueFunction,
 		UpdateFunc: func(old, new interface{}) {
@@ -88,17 +203,19 @@ func NewController(
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer runtime.HandleCrash()
 	defer c.workqueue.ShutDown()
+	defer c.zoneQueue.ShutDown()
 
 	glog.Info("Starting Function controller")
 
 	glog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.deployementSynced, c.configMapSynched, c.functionSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, c.deployementSynced, c.statefulSetSynced, c.configMapSynched, c.secretSynced, c.podSynced, c.nodeSynced, c.claimSynced, c.revisionSynced, c.profileSynced, c.runtimeSynced, c.functionSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
 	glog.Info("Starting workers")
 	for i := 0; i < threadiness; i++ {
 		go wait.Until(c.runWorker, time.Second, stopCh)
+		go wait.Until(c.runZoneWorker, time.Second, stopCh)
 	}
 
 	glog.Info("Started workers")
@@ -167,60 +284,310 @@ func (c *Controller) syncHandler(key string) error {
 		return err
 	}
 
-	functionConfig := newFunctionConfig(&c.functionDefaultConfig, function)
+	if _, ok := function.Annotations[kfn.RollbackToAnnotation]; ok {
+		return c.rollbackTo(function)
+	}
 
-	configmap, err := c.configMapLister.ConfigMaps(namespace).Get(name)
+	if function.Annotations[kfn.DryRunAnnotation] == "true" {
+		return c.dryRun(function)
+	}
+
+	defaultConfig, err := c.defaultConfig()
 	if err != nil {
-		if errors.IsNotFound(err) {
-			glog.Info("Create ConfigMap for %s/%s", namespace, name)
-			configmap, err = c.kubeClient.CoreV1().ConfigMaps(namespace).Create(newConfigMap(function, functionConfig))
+		return err
+	}
+
+	newFunction := function.DeepCopy()
+	newFunction.Status.ObservedGeneration = function.Generation
+	newFunction.Status.DryRun = nil
+	newFunction.Status.ConfigError = ""
+	newFunction.Status.Warnings = render.ConfigWarnings(function)
+
+	if !equality.Semantic.DeepEqual(function.Status.Warnings, newFunction.Status.Warnings) {
+		for _, warning := range newFunction.Status.Warnings {
+			glog.Infof("Configuration of %s/%s: %s", namespace, name, warning)
 		}
-	} else {
-		newConfigMap := newConfigMap(function, functionConfig)
+	}
+
+	if err := c.syncCycle(function, &newFunction.Status); err != nil {
+		return err
+	}
+
+	if err := render.ValidateConfig(defaultConfig, function); err != nil {
+		glog.Infof("Configuration of %s/%s is invalid: %s", namespace, name, err.Error())
+		newFunction.Status.ConfigError = err.Error()
+		return c.updateFunctionStatus(function, newFunction)
+	}
+
+	// The workloads read the properties of the ConfigMaps and the Secrets
+	resolved, err := render.ResolveSources(function, c.sources())
+	if err != nil {
+		glog.Infof("Can't read the sources of %s/%s: %s", namespace, name, err.Error())
+		newFunction.Status.ConfigError = err.Error()
+		return c.updateFunctionStatus(function, newFunction)
+	}
+
+	resolved = render.ResolveImage(defaultConfig, resolved)
+
+	functionConfig := render.NewFunctionConfig(defaultConfig, resolved)
+
+	// The workloads run the pinned image while the revisions record the
+	// spec of the Function
+	pinned := c.pinImage(resolved, &newFunction.Status)
+	if pinned == nil {
+		return c.updateFunctionStatus(function, newFunction)
+	}
+
+	if err := render.ValidateState(function); err != nil {
+		glog.Infof("State of %s/%s is invalid: %s", namespace, name, err.Error())
+		newFunction.Status.State = &kfnv1alpha1.StateStatus{Error: err.Error()}
+		return c.updateFunctionStatus(function, newFunction)
+	}
 
-		curHash := hash(configmap)
-		newHash := hash(newConfigMap)
+	if function.Spec.Suspend {
+		newFunction.Status.Suspended = true
+		newFunction.Status.AvailableReplicas, err = c.suspend(pinned, functionConfig)
+		if err != nil {
+			return err
+		}
+
+		if err := c.syncState(function, &newFunction.Status); err != nil {
+			return err
+		}
 
-		if curHash != newHash {
-			glog.Info("Update ConfigMap for %s/%s", namespace, name)
-			configmap, err = c.kubeClient.CoreV1().ConfigMaps(namespace).Update(newConfigMap)
+		if err := c.syncRevisions(function, functionConfig, &newFunction.Status); err != nil {
+			return err
 		}
+
+		return c.updateFunctionStatus(function, newFunction)
+	}
+
+	if function.Status.Suspended {
+		glog.Infof("Resume %s/%s", namespace, name)
+		newFunction.Status.Suspended = false
+		resumeCanary(newFunction.Status.Canary)
+	}
+
+	var availableReplicas int32
+	if isCanary(pinned) {
+		availableReplicas, err = c.syncCanary(pinned, functionConfig, &newFunction.Status)
+	} else {
+		newFunction.Status.Canary = nil
+		availableReplicas, err = c.syncRollingUpdate(pinned, functionConfig)
 	}
 
 	if err != nil {
 		return err
 	}
 
+	newFunction.Status.AvailableReplicas = availableReplicas
+
+	if err := c.syncShadow(pinned, defaultConfig, functionConfig, &newFunction.Status); err != nil {
+		return err
+	}
+
+	if err := c.syncState(function, &newFunction.Status); err != nil {
+		return err
+	}
+
+	if err := c.syncRevisions(function, functionConfig, &newFunction.Status); err != nil {
+		return err
+	}
+
+	return c.updateFunctionStatus(function, newFunction)
+}
+
+// syncRollingUpdate applies the resources of the Function. A new revision
+// is rolled out by the Deployment or the StatefulSet itself.
+func (c *Controller) syncRollingUpdate(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig) (int32, error) {
+	configmap, err := c.syncConfigMap(function, render.NewConfigMap(function, functionConfig))
+	if err != nil {
+		return 0, err
+	}
+
+	// Leftover of a canary rollout when the strategy has been changed
+	if err := c.deleteCanary(function); err != nil {
+		return 0, err
+	}
+
+	if render.IsStatefulSet(function) {
+		statefulSet, err := c.syncStatefulSet(function, render.NewStatefulSet(function, functionConfig, configmap))
+		if err != nil {
+			return 0, err
+		}
+
+		// The workload type has been changed
+		if err := c.deleteDeployement(function, function.Name); err != nil {
+			return 0, err
+		}
+
+		return statefulSet.Status.ReadyReplicas, nil
+	}
+
+	deployement, err := c.syncDeployement(function, render.NewDeployement(function, functionConfig, configmap))
+	if err != nil {
+		return 0, err
+	}
+
+	// The workload type has been changed
+	if err := c.deleteStatefulSet(function); err != nil {
+		return 0, err
+	}
+
+	return deployement.Status.AvailableReplicas, nil
+}
+
+// syncConfigMap creates the ConfigMap or updates it when its properties differ.
+func (c *Controller) syncConfigMap(function *kfnv1alpha1.Function, newConfigMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	namespace, name := newConfigMap.Namespace, newConfigMap.Name
+
+	configmap, err := c.configMapLister.ConfigMaps(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Infof("Create ConfigMap %s/%s", namespace, name)
+			return c.kubeClient.CoreV1().ConfigMaps(namespace).Create(newConfigMap)
+		}
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(configmap, function) {
+		return nil, fmt.Errorf("ConfigMap '%s/%s' already exists and is not owned by the function", namespace, name)
+	}
+
+	if render.Hash(configmap) == render.Hash(newConfigMap) {
+		return configmap, nil
+	}
+
+	glog.Infof("Update ConfigMap %s/%s", namespace, name)
+	return c.kubeClient.CoreV1().ConfigMaps(namespace).Update(newConfigMap)
+}
+
+// syncDeployement creates the Deployment or updates it when its replicas or
+// its pod template differ.
+func (c *Controller) syncDeployement(function *kfnv1alpha1.Function, newDeployement *appsv1.Deployment) (*appsv1.Deployment, error) {
+	namespace, name := newDeployement.Namespace, newDeployement.Name
+
 	deployement, err := c.deployementLister.Deployments(namespace).Get(name)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			glog.Info("Create Deployement for %s/%s", namespace, name)
-			deployement, err = c.kubeClient.AppsV1().Deployments(namespace).Create(newDeployement(function, configmap))
+			glog.Infof("Create Deployement %s/%s", namespace, name)
+			return c.kubeClient.AppsV1().Deployments(namespace).Create(newDeployement)
 		}
-	} else {
-		newDeployement := newDeployement(function, configmap)
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(deployement, function) {
+		return nil, fmt.Errorf("Deployment '%s/%s' already exists and is not owned by the function", namespace, name)
+	}
+
+	if *newDeployement.Spec.Replicas == *deployement.Spec.Replicas && revision(newDeployement) == revision(deployement) {
+		return deployement, nil
+	}
+
+	glog.Infof("Update Deployement %s/%s", namespace, name)
+	return c.kubeClient.AppsV1().Deployments(namespace).Update(newDeployement)
+}
+
+// deleteResources deletes the ConfigMap and the Deployment with the given
+// name if they exist and are owned by the Function.
+func (c *Controller) deleteResources(function *kfnv1alpha1.Function, name string) error {
+	if err := c.deleteDeployement(function, name); err != nil {
+		return err
+	}
 
-		if *newDeployement.Spec.Replicas != *deployement.Spec.Replicas || newDeployement.Spec.Template.Spec.Containers[0].Image != deployement.Spec.Template.Spec.Containers[0].Image || newDeployement.Spec.Template.Annotations["kfn.dajac.io/config-hash"] != deployement.Spec.Template.Annotations["kfn.dajac.io/config-hash"] {
-			glog.Info("Update Deployement for %s/%s", namespace, name)
-			deployement, err = c.kubeClient.AppsV1().Deployments(namespace).Update(newDeployement)
+	if configmap, err := c.configMapLister.ConfigMaps(function.Namespace).Get(name); err == nil && metav1.IsControlledBy(configmap, function) {
+		glog.Infof("Delete ConfigMap %s/%s", function.Namespace, name)
+
+		err := c.kubeClient.CoreV1().ConfigMaps(function.Namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
 		}
 	}
 
+	return nil
+}
+
+// syncStatefulSet creates the StatefulSet or updates it when its replicas
+// or its pod template differ.
+func (c *Controller) syncStatefulSet(function *kfnv1alpha1.Function, newStatefulSet *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	namespace, name := newStatefulSet.Namespace, newStatefulSet.Name
+
+	statefulSet, err := c.statefulSetLister.StatefulSets(namespace).Get(name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Infof("Create StatefulSet %s/%s", namespace, name)
+			return c.kubeClient.AppsV1().StatefulSets(namespace).Create(newStatefulSet)
+		}
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(statefulSet, function) {
+		return nil, fmt.Errorf("StatefulSet '%s/%s' already exists and is not owned by the function", namespace, name)
+	}
+
+	if claimTemplatesChanged(statefulSet, newStatefulSet) {
+		// The StatefulSet is created again with the new templates by the
+		// sync triggered by its deletion
+		return statefulSet, c.orphanStatefulSet(statefulSet)
+	}
+
+	if *newStatefulSet.Spec.Replicas == *statefulSet.Spec.Replicas &&
+		templateRevision(newStatefulSet.Spec.Template.ObjectMeta, newStatefulSet.Spec.Template.Spec) == templateRevision(statefulSet.Spec.Template.ObjectMeta, statefulSet.Spec.Template.Spec) {
+		return statefulSet, nil
+	}
+
+	// The selector, the service name and the volume claim templates of a
+	// StatefulSet can't be updated
+	statefulSet = statefulSet.DeepCopy()
+	statefulSet.Spec.Replicas = newStatefulSet.Spec.Replicas
+	statefulSet.Spec.Template = newStatefulSet.Spec.Template
+
+	glog.Infof("Update StatefulSet %s/%s", namespace, name)
+	return c.kubeClient.AppsV1().StatefulSets(namespace).Update(statefulSet)
+}
+
+// deleteDeployement deletes the Deployment with the given name if it exists
+// and is owned by the Function.
+func (c *Controller) deleteDeployement(function *kfnv1alpha1.Function, name string) error {
+	deployement, err := c.deployementLister.Deployments(function.Namespace).Get(name)
+	if err != nil || !metav1.IsControlledBy(deployement, function) {
+		return nil
+	}
+
+	glog.Infof("Delete Deployement %s/%s", function.Namespace, name)
+
+	err = c.kubeClient.AppsV1().Deployments(function.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
 
-	newFunction := function.DeepCopy()
-	newFunction.Status.ObservedGeneration = function.Generation
-	newFunction.Status.AvailableReplicas = deployement.Status.AvailableReplicas
+	return nil
+}
+
+// deleteStatefulSet deletes the StatefulSet of the Function if it exists.
+func (c *Controller) deleteStatefulSet(function *kfnv1alpha1.Function) error {
+	statefulSet, err := c.statefulSetLister.StatefulSets(function.Namespace).Get(function.Name)
+	if err != nil || !metav1.IsControlledBy(statefulSet, function) {
+		return nil
+	}
+
+	glog.Infof("Delete StatefulSet %s/%s", function.Namespace, function.Name)
+
+	err = c.kubeClient.AppsV1().StatefulSets(function.Namespace).Delete(function.Name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
 
+func (c *Controller) updateFunctionStatus(function *kfnv1alpha1.Function, newFunction *kfnv1alpha1.Function) error {
 	// Update the status only if it has changed
-	if function.Status.AvailableReplicas == newFunction.Status.AvailableReplicas &&
-		function.Status.ObservedGeneration == newFunction.Status.ObservedGeneration {
+	if equality.Semantic.DeepEqual(function.Status, newFunction.Status) {
 		return nil
 	}
 
-	_, err = c.kfnClient.Kfn().Functions(namespace).UpdateStatus(newFunction)
+	_, err := c.kfnClient.Kfn().Functions(function.Namespace).UpdateStatus(newFunction)
 
 	if err != nil {
 		return err
@@ -239,21 +606,112 @@ func (c *Controller) enqueueFunction(obj interface{}) {
 	c.workqueue.AddRateLimited(key)
 }
 
-func (c *Controller) handleObject(obj interface{}) {
-	var object metav1.Object
-	var ok bool
-	if object, ok = obj.(metav1.Object); !ok {
-		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
-		if !ok {
-			runtime.HandleError(fmt.Errorf("error decoding object, invalid type"))
-			return
+// Validate checks the configuration of the Function and that it does not
+// introduce a cycle. It is used to reject invalid Functions on admission.
+func (c *Controller) Validate(function *kfnv1alpha1.Function) error {
+	defaultConfig, err := c.defaultConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := render.ValidateConfig(defaultConfig, function); err != nil {
+		return err
+	}
+
+	return c.validateCycle(function)
+}
+
+// defaultConfig returns the default configuration of the Functions with
+// the user-defined profiles and runtimes.
+func (c *Controller) defaultConfig() (*render.FunctionDefaultConfig, error) {
+	profiles, err := c.profileLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	runtimes, err := c.runtimeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	defaultConfig := c.functionDefaultConfig
+	defaultConfig.Profiles = render.NewProfiles(profiles)
+	defaultConfig.Runtimes = render.NewRuntimes(runtimes)
+	return &defaultConfig, nil
+}
+
+// handleProfile enqueues the Functions using the FunctionProfile.
+func (c *Controller) handleProfile(obj interface{}) {
+	object, ok := objectFromEvent(obj)
+	if !ok {
+		return
+	}
+
+	functions, err := c.functionLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	for _, function := range functions {
+		if function.Spec.Profile == object.GetName() {
+			c.enqueueFunction(function)
 		}
-		object, ok = tombstone.Obj.(metav1.Object)
-		if !ok {
-			runtime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
-			return
+	}
+}
+
+// handleRuntime enqueues the Functions using the FunctionRuntime. A
+// FunctionRuntime may override a built-in runtime, including the default
+// one.
+func (c *Controller) handleRuntime(obj interface{}) {
+	object, ok := objectFromEvent(obj)
+	if !ok {
+		return
+	}
+
+	functions, err := c.functionLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	for _, function := range functions {
+		name := function.Spec.Runtime
+		if name == "" {
+			name = render.DefaultRuntime
+		}
+		if name == object.GetName() {
+			c.enqueueFunction(function)
 		}
-		glog.V(4).Infof("Recovered deleted object '%s' from tombstone", object.GetName())
+	}
+}
+
+// objectFromEvent returns the object of an event handler, recovering it
+// from the tombstone of a deletion missed by the informer. It returns false
+// when the object can't be decoded.
+func objectFromEvent(obj interface{}) (metav1.Object, bool) {
+	if object, ok := obj.(metav1.Object); ok {
+		return object, true
+	}
+
+	tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+		return nil, false
+	}
+	object, ok := tombstone.Obj.(metav1.Object)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+		return nil, false
+	}
+	glog.V(4).Infof("Recovered deleted object '%s' from tombstone", object.GetName())
+	return object, true
+}
+
+func (c *Controller) handleObject(obj interface{}) {
+	object, ok := objectFromEvent(obj)
+	if !ok {
+		return
 	}
 
 	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil {
@@ -0,0 +1,297 @@
+package function
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	eventrecord "k8s.io/client-go/tools/record"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/client/clientset/versioned/fake"
+	informers "github.com/dajac/kfn/pkg/client/informers/externalversions"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// fixture is a controller whose caches are filled by hand from fake
+// clients.
+type fixture struct {
+	t *testing.T
+
+	controller *Controller
+	kubeClient *k8sfake.Clientset
+	kfnClient  *fake.Clientset
+
+	kubeInformers kubeinformers.SharedInformerFactory
+	kfnInformers  informers.SharedInformerFactory
+}
+
+func newFixture(t *testing.T, defaultConfig render.FunctionDefaultConfig, objects ...runtime.Object) *fixture {
+	kubeObjects := []runtime.Object{}
+	kfnObjects := []runtime.Object{}
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *kfnv1alpha1.Function, *kfnv1alpha1.FunctionRevision, *kfnv1alpha1.FunctionProfile, *kfnv1alpha1.FunctionRuntime:
+			kfnObjects = append(kfnObjects, obj)
+		default:
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+
+	f := &fixture{
+		t:          t,
+		kubeClient: k8sfake.NewSimpleClientset(kubeObjects...),
+		kfnClient:  fake.NewSimpleClientset(kfnObjects...),
+	}
+	f.kubeInformers = kubeinformers.NewSharedInformerFactory(f.kubeClient, 0)
+	f.kfnInformers = informers.NewSharedInformerFactory(f.kfnClient, 0)
+
+	f.controller = NewController(
+		f.kubeClient,
+		f.kfnClient,
+		f.kubeInformers.Apps().V1().Deployments(),
+		f.kubeInformers.Apps().V1().StatefulSets(),
+		f.kubeInformers.Core().V1().ConfigMaps(),
+		f.kubeInformers.Core().V1().Secrets(),
+		f.kubeInformers.Core().V1().Pods(),
+		f.kubeInformers.Core().V1().Nodes(),
+		f.kubeInformers.Core().V1().PersistentVolumeClaims(),
+		f.kfnInformers.Kfn().V1alpha1().Functions(),
+		f.kfnInformers.Kfn().V1alpha1().FunctionRevisions(),
+		f.kfnInformers.Kfn().V1alpha1().FunctionProfiles(),
+		f.kfnInformers.Kfn().V1alpha1().FunctionRuntimes(),
+		defaultConfig,
+	)
+	f.controller.recorder = eventrecord.NewFakeRecorder(100)
+
+	for _, obj := range objects {
+		f.add(obj)
+	}
+
+	return f
+}
+
+// indexer returns the cache of the type of the object.
+func (f *fixture) indexer(obj runtime.Object) cache.Indexer {
+	switch obj.(type) {
+	case *kfnv1alpha1.Function:
+		return f.kfnInformers.Kfn().V1alpha1().Functions().Informer().GetIndexer()
+	case *kfnv1alpha1.FunctionRevision:
+		return f.kfnInformers.Kfn().V1alpha1().FunctionRevisions().Informer().GetIndexer()
+	case *kfnv1alpha1.FunctionProfile:
+		return f.kfnInformers.Kfn().V1alpha1().FunctionProfiles().Informer().GetIndexer()
+	case *kfnv1alpha1.FunctionRuntime:
+		return f.kfnInformers.Kfn().V1alpha1().FunctionRuntimes().Informer().GetIndexer()
+	case *appsv1.Deployment:
+		return f.kubeInformers.Apps().V1().Deployments().Informer().GetIndexer()
+	case *appsv1.StatefulSet:
+		return f.kubeInformers.Apps().V1().StatefulSets().Informer().GetIndexer()
+	case *corev1.ConfigMap:
+		return f.kubeInformers.Core().V1().ConfigMaps().Informer().GetIndexer()
+	case *corev1.Secret:
+		return f.kubeInformers.Core().V1().Secrets().Informer().GetIndexer()
+	case *corev1.Pod:
+		return f.kubeInformers.Core().V1().Pods().Informer().GetIndexer()
+	case *corev1.Node:
+		return f.kubeInformers.Core().V1().Nodes().Informer().GetIndexer()
+	case *corev1.PersistentVolumeClaim:
+		return f.kubeInformers.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	default:
+		f.t.Fatalf("unexpected object %T", obj)
+		return nil
+	}
+}
+
+// add adds the objects to the caches only.
+func (f *fixture) add(objects ...runtime.Object) {
+	for _, obj := range objects {
+		if err := f.indexer(obj).Add(obj); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+}
+
+// refresh replaces the caches of the objects written by the controller
+// with the content of the fake clients.
+func (f *fixture) refresh() {
+	options := metav1.ListOptions{}
+
+	functions, _ := f.kfnClient.KfnV1alpha1().Functions("").List(options)
+	revisions, _ := f.kfnClient.KfnV1alpha1().FunctionRevisions("").List(options)
+	deployments, _ := f.kubeClient.AppsV1().Deployments("").List(options)
+	statefulSets, _ := f.kubeClient.AppsV1().StatefulSets("").List(options)
+	configMaps, _ := f.kubeClient.CoreV1().ConfigMaps("").List(options)
+	claims, _ := f.kubeClient.CoreV1().PersistentVolumeClaims("").List(options)
+
+	replace := func(obj runtime.Object, items []interface{}) {
+		if err := f.indexer(obj).Replace(items, ""); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+
+	items := []interface{}{}
+	for i := range functions.Items {
+		items = append(items, &functions.Items[i])
+	}
+	replace(&kfnv1alpha1.Function{}, items)
+
+	items = []interface{}{}
+	for i := range revisions.Items {
+		items = append(items, &revisions.Items[i])
+	}
+	replace(&kfnv1alpha1.FunctionRevision{}, items)
+
+	items = []interface{}{}
+	for i := range deployments.Items {
+		items = append(items, &deployments.Items[i])
+	}
+	replace(&appsv1.Deployment{}, items)
+
+	items = []interface{}{}
+	for i := range statefulSets.Items {
+		items = append(items, &statefulSets.Items[i])
+	}
+	replace(&appsv1.StatefulSet{}, items)
+
+	items = []interface{}{}
+	for i := range configMaps.Items {
+		items = append(items, &configMaps.Items[i])
+	}
+	replace(&corev1.ConfigMap{}, items)
+
+	items = []interface{}{}
+	for i := range claims.Items {
+		items = append(items, &claims.Items[i])
+	}
+	replace(&corev1.PersistentVolumeClaim{}, items)
+}
+
+// sync runs the controller on the Function and refreshes the caches.
+func (f *fixture) sync(function *kfnv1alpha1.Function) {
+	key, _ := cache.MetaNamespaceKeyFunc(function)
+	if err := f.controller.syncHandler(key); err != nil {
+		f.t.Fatal(err)
+	}
+	f.refresh()
+}
+
+func (f *fixture) function(name string) *kfnv1alpha1.Function {
+	function, err := f.kfnClient.KfnV1alpha1().Functions("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return function
+}
+
+func (f *fixture) deployment(name string) *appsv1.Deployment {
+	deployment, err := f.kubeClient.AppsV1().Deployments("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return deployment
+}
+
+// update updates the Function in the client and in the cache.
+func (f *fixture) update(function *kfnv1alpha1.Function) *kfnv1alpha1.Function {
+	updated, err := f.kfnClient.KfnV1alpha1().Functions(function.Namespace).Update(function)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.refresh()
+	return updated
+}
+
+func newTestFunction(name string) *kfnv1alpha1.Function {
+	return &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name)},
+		Spec: kfnv1alpha1.FunctionSpec{
+			Image:                  "dajac/kfn-examples:0.1.0",
+			Replicas:               2,
+			Class:                  "io.dajac.kfn.examples.Copy",
+			Input:                  name + ".input",
+			InputKeyDeserializer:   "string",
+			InputValueDeserializer: "string",
+			Output:                 name + ".output",
+			OutputKeySerializer:    "string",
+			OutoutValueSerializer:  "string",
+		},
+	}
+}
+
+func TestSyncHandler(t *testing.T) {
+	function := newTestFunction("copy")
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+
+	f.sync(function)
+
+	configMap, err := f.kubeClient.CoreV1().ConfigMaps("default").Get("copy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment := f.deployment("copy")
+	if *deployment.Spec.Replicas != 2 {
+		t.Errorf("replicas = %d, want 2", *deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Template.Annotations[render.ConfigHashAnnotation] != render.Hash(configMap) {
+		t.Errorf("the pods must be annotated with the hash of the properties")
+	}
+
+	status := f.function("copy").Status
+	if status.ConfigError != "" || status.CurrentRevision == "" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// A new output updates the ConfigMap and rolls the pods
+	function = f.function("copy")
+	function.Spec.Output = "copy.other"
+	function = f.update(function)
+	f.sync(function)
+
+	if hash := f.deployment("copy").Spec.Template.Annotations[render.ConfigHashAnnotation]; hash == render.Hash(configMap) {
+		t.Errorf("the pods must be rolled")
+	}
+	if revisions, _ := f.controller.listRevisions(function); len(revisions) != 2 {
+		t.Errorf("got %d revisions, want 2", len(revisions))
+	}
+
+	// An invalid configuration is reported in the status
+	function = f.function("copy")
+	function.Spec.Profile = "unknown"
+	function = f.update(function)
+	f.sync(function)
+
+	if status := f.function("copy").Status; status.ConfigError == "" {
+		t.Errorf("the configuration error must be reported")
+	}
+}
+
+func TestObjectFromEvent(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "copy"}}
+
+	tests := []struct {
+		name string
+		obj  interface{}
+		ok   bool
+	}{
+		{name: "object", obj: configMap, ok: true},
+		{name: "tombstone", obj: cache.DeletedFinalStateUnknown{Key: "default/copy", Obj: configMap}, ok: true},
+		{name: "invalid tombstone", obj: cache.DeletedFinalStateUnknown{Key: "default/copy", Obj: "copy"}},
+		{name: "invalid type", obj: "copy"},
+	}
+
+	for _, test := range tests {
+		object, ok := objectFromEvent(test.obj)
+		if ok != test.ok {
+			t.Errorf("%s: ok = %t, want %t", test.name, ok, test.ok)
+		}
+		if ok && object.GetName() != "copy" {
+			t.Errorf("%s: name = %s, want copy", test.name, object.GetName())
+		}
+	}
+}
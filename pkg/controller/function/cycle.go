@@ -0,0 +1,174 @@
+package function
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/dajac/kfn/pkg/topology"
+	"github.com/golang/glog"
+)
+
+const (
+	// reasonCycleDetected is the reason of the InCycle condition and of
+	// the event of the Functions entering a cycle.
+	reasonCycleDetected = "CycleDetected"
+
+	// reasonCycleAllowed is the reason of the InCycle condition of the
+	// Functions allowing the cycle.
+	reasonCycleAllowed = "CycleAllowed"
+
+	reasonNoCycle = "NoCycle"
+)
+
+// allowsCycle returns true if the Function opts in to be part of a cycle.
+func allowsCycle(function *kfnv1alpha1.Function) bool {
+	return function.Annotations[kfn.AllowCycleAnnotation] == "true"
+}
+
+// cycleOf returns the Functions, by namespace/name, of the cycle the
+// Function is part of with its spec and the specs of the other Functions.
+// It returns nil if the Function is not part of a cycle. Only the topics of
+// the Functions are read, they are not rendered.
+func cycleOf(function *kfnv1alpha1.Function, functions []*kfnv1alpha1.Function) []string {
+	key := function.Namespace + "/" + function.Name
+
+	// The spec of the Function replaces the one of the cache, if any
+	graph := []*kfnv1alpha1.Function{function}
+	for _, f := range functions {
+		if f.Namespace+"/"+f.Name != key {
+			graph = append(graph, f)
+		}
+	}
+
+	return topology.Cycle(graph, key)
+}
+
+// sameTopics returns true if the Functions consume and produce to the same
+// topics, in which case they are part of the same cycles.
+func sameTopics(function *kfnv1alpha1.Function, other *kfnv1alpha1.Function) bool {
+	return function.Spec.Input == other.Spec.Input && reflect.DeepEqual(render.Outputs(function), render.Outputs(other))
+}
+
+// validateCycle rejects the Function if its spec introduces a cycle. The
+// Functions already part of a cycle are only flagged so they can still be
+// changed, e.g. to break the cycle.
+func (c *Controller) validateCycle(function *kfnv1alpha1.Function) error {
+	if allowsCycle(function) {
+		return nil
+	}
+
+	functions, err := c.functionLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	cycle := cycleOf(function, functions)
+	if cycle == nil {
+		return nil
+	}
+
+	live, err := c.functionLister.Functions(function.Namespace).Get(function.Name)
+	if err == nil {
+		// The live Function already is in the cycle if it has the same
+		// topics
+		if sameTopics(function, live) || cycleOf(live, functions) != nil {
+			return nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	return fmt.Errorf("the output of the Function feeds its own input through %s: set the %s annotation to \"true\" to allow the cycle", strings.Join(cycle, ", "), kfn.AllowCycleAnnotation)
+}
+
+// syncCycle sets the InCycle condition of the Function. An event is
+// recorded when a Function which does not allow it enters a cycle and the
+// other Functions of the cycle are synced so they are flagged too. The
+// Functions leaving a cycle are updated by the next resync.
+func (c *Controller) syncCycle(function *kfnv1alpha1.Function, status *kfnv1alpha1.FunctionStatus) error {
+	functions, err := c.functionLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	cycle := cycleOf(function, functions)
+
+	if cycle == nil {
+		setCondition(status, kfnv1alpha1.FunctionCondition{
+			Type:   kfnv1alpha1.InCycleCondition,
+			Status: corev1.ConditionFalse,
+			Reason: reasonNoCycle,
+		})
+		return nil
+	}
+
+	message := fmt.Sprintf("The output of the Function feeds its own input through %s", strings.Join(cycle, ", "))
+
+	if allowsCycle(function) {
+		setCondition(status, kfnv1alpha1.FunctionCondition{
+			Type:    kfnv1alpha1.InCycleCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  reasonCycleAllowed,
+			Message: message,
+		})
+		return nil
+	}
+
+	if !isConditionTrue(function.Status.Conditions, kfnv1alpha1.InCycleCondition) {
+		glog.Infof("Function %s/%s is part of a cycle: %s", function.Namespace, function.Name, strings.Join(cycle, ", "))
+		c.recorder.Event(function, corev1.EventTypeWarning, reasonCycleDetected, message)
+
+		key := function.Namespace + "/" + function.Name
+		for _, k := range cycle {
+			if k != key {
+				c.workqueue.Add(k)
+			}
+		}
+	}
+
+	setCondition(status, kfnv1alpha1.FunctionCondition{
+		Type:    kfnv1alpha1.InCycleCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reasonCycleDetected,
+		Message: message,
+	})
+
+	return nil
+}
+
+// setCondition sets the condition in the status. The last transition time
+// is kept when the status of the condition does not change.
+func setCondition(status *kfnv1alpha1.FunctionStatus, condition kfnv1alpha1.FunctionCondition) {
+	conditions := []kfnv1alpha1.FunctionCondition{}
+	condition.LastTransitionTime = metav1.Now()
+
+	for _, existing := range status.Conditions {
+		if existing.Type != condition.Type {
+			conditions = append(conditions, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+
+	status.Conditions = append(conditions, condition)
+}
+
+func isConditionTrue(conditions []kfnv1alpha1.FunctionCondition, conditionType string) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package function
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	eventrecord "k8s.io/client-go/tools/record"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// condition returns the InCycle condition of the Function.
+func (f *fixture) condition(name string) *kfnv1alpha1.FunctionCondition {
+	for _, condition := range f.function(name).Status.Conditions {
+		if condition.Type == kfnv1alpha1.InCycleCondition {
+			return &condition
+		}
+	}
+	return nil
+}
+
+func TestValidateCycle(t *testing.T) {
+	parse := newTestFunction("parse")
+	parse.Spec.Input = "raw"
+	parse.Spec.Output = "orders"
+	f := newFixture(t, render.FunctionDefaultConfig{}, parse)
+
+	loop := newTestFunction("loop")
+	loop.Spec.Input = "orders"
+	loop.Spec.Output = "raw"
+
+	err := f.controller.Validate(loop)
+	if err == nil || !strings.Contains(err.Error(), "feeds its own input through default/loop, default/parse") {
+		t.Errorf("the cycle must be rejected, got %v", err)
+	}
+
+	loop.Annotations = map[string]string{kfn.AllowCycleAnnotation: "true"}
+	if err := f.controller.Validate(loop); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+
+	// A Function already part of a cycle can still be changed
+	loop.Annotations = nil
+	f.add(loop)
+	loop = loop.DeepCopy()
+	loop.Spec.Replicas = 3
+	if err := f.controller.Validate(loop); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+}
+
+func TestSyncCycle(t *testing.T) {
+	parse := newTestFunction("parse")
+	parse.Spec.Input = "raw"
+	parse.Spec.Output = "orders"
+	loop := newTestFunction("loop")
+	loop.Spec.Input = "orders"
+	loop.Spec.Output = "raw"
+	f := newFixture(t, render.FunctionDefaultConfig{}, parse, loop)
+
+	f.sync(loop)
+	condition := f.condition("loop")
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != reasonCycleDetected {
+		t.Fatalf("unexpected condition %+v", condition)
+	}
+
+	// The event is only recorded when the Function enters the cycle and
+	// the other Functions of the cycle are synced
+	recorder := f.controller.recorder.(*eventrecord.FakeRecorder)
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning CycleDetected") {
+		t.Errorf("unexpected event %q", event)
+	}
+	if f.controller.workqueue.Len() != 1 {
+		t.Errorf("the other Function of the cycle must be enqueued")
+	}
+	transition := condition.LastTransitionTime
+
+	f.sync(f.function("loop"))
+	if len(recorder.Events) != 0 {
+		t.Errorf("the event must be recorded once")
+	}
+	if condition := f.condition("loop"); !condition.LastTransitionTime.Equal(&transition) {
+		t.Errorf("the last transition time must be kept")
+	}
+
+	// The allowed cycles are only flagged
+	function := f.function("loop")
+	function.Annotations = map[string]string{kfn.AllowCycleAnnotation: "true"}
+	f.sync(f.update(function))
+	if condition := f.condition("loop"); condition.Status != corev1.ConditionTrue || condition.Reason != reasonCycleAllowed {
+		t.Errorf("unexpected condition %+v", condition)
+	}
+
+	// The cycle is broken
+	function = f.function("loop")
+	function.Spec.Output = "orders.audit"
+	f.sync(f.update(function))
+	if condition := f.condition("loop"); condition.Status != corev1.ConditionFalse || condition.Reason != reasonNoCycle {
+		t.Errorf("unexpected condition %+v", condition)
+	}
+}
@@ -0,0 +1,91 @@
+package function
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/golang/glog"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/diff"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// dryRun computes the changes which would be applied to the resources of
+// the Function and reports them in its status. Nothing else is written.
+func (c *Controller) dryRun(function *kfnv1alpha1.Function) error {
+	var configMap *corev1.ConfigMap
+	var deployement *appsv1.Deployment
+	var statefulSet *appsv1.StatefulSet
+	var err error
+
+	configMap, err = c.configMapLister.ConfigMaps(function.Namespace).Get(function.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		configMap = nil
+	}
+
+	deployement, err = c.deployementLister.Deployments(function.Namespace).Get(function.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		deployement = nil
+	}
+
+	statefulSet, err = c.statefulSetLister.StatefulSets(function.Namespace).Get(function.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		statefulSet = nil
+	}
+
+	defaultConfig, err := c.defaultConfig()
+	if err != nil {
+		return err
+	}
+
+	newFunction := function.DeepCopy()
+
+	// A configuration rejected by the controller is reported instead of the
+	// changes
+	if err := render.ValidateConfig(defaultConfig, function); err != nil {
+		glog.Infof("Dry run for %s/%s: configuration is invalid: %s", function.Namespace, function.Name, err.Error())
+		newFunction.Status.DryRun = &kfnv1alpha1.DryRunResult{ConfigError: err.Error()}
+		return c.updateFunctionStatus(function, newFunction)
+	}
+
+	resolved, err := render.ResolveSources(function, c.sources())
+	if err != nil {
+		glog.Infof("Dry run for %s/%s: can't read the sources: %s", function.Namespace, function.Name, err.Error())
+		newFunction.Status.DryRun = &kfnv1alpha1.DryRunResult{ConfigError: err.Error()}
+		return c.updateFunctionStatus(function, newFunction)
+	}
+	resolved = render.ResolveImage(defaultConfig, resolved)
+
+	// The image is not resolved during a dry run
+	report, err := diff.Compute(render.Render(defaultConfig, render.PinnedFunction(resolved, function.Status.Image, function.Status.ShadowImage)), configMap, deployement, statefulSet)
+	if err != nil {
+		return err
+	}
+
+	differences := report.Lines()
+
+	glog.Infof("Dry run for %s/%s: config hash changed: %t, rolling restart: %t, %d difference(s)",
+		function.Namespace, function.Name, report.ConfigHashChanged, report.RollingRestart, len(differences))
+	for _, difference := range differences {
+		glog.V(4).Infof("Dry run for %s/%s: %s", function.Namespace, function.Name, difference)
+	}
+
+	newFunction.Status.DryRun = &kfnv1alpha1.DryRunResult{
+		ConfigHashChanged: report.ConfigHashChanged,
+		RollingRestart:    report.RollingRestart,
+		Differences:       differences,
+	}
+
+	return c.updateFunctionStatus(function, newFunction)
+}
@@ -0,0 +1,77 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestDryRun(t *testing.T) {
+	function := newTestFunction("copy")
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+
+	deployment := f.deployment("copy")
+
+	function = f.function("copy")
+	function.Annotations = map[string]string{kfn.DryRunAnnotation: "true"}
+	function.Spec.Output = "copy.other"
+	function.Spec.Replicas = 3
+	function = f.update(function)
+	f.sync(function)
+
+	dryRun := f.function("copy").Status.DryRun
+	if dryRun == nil || !dryRun.ConfigHashChanged || !dryRun.RollingRestart {
+		t.Fatalf("the new output must be reported, got %+v", dryRun)
+	}
+
+	expected := map[string]bool{
+		`properties: function.output: "copy.output" -> "copy.other"`: true,
+		`deployment: spec.replicas: 2 -> 3`:                          true,
+	}
+	for _, difference := range dryRun.Differences {
+		delete(expected, difference)
+	}
+	if len(expected) > 0 {
+		t.Errorf("differences = %v, missing %v", dryRun.Differences, expected)
+	}
+
+	// Nothing is applied
+	if live := f.deployment("copy"); *live.Spec.Replicas != 2 || live.Spec.Template.Annotations[render.ConfigHashAnnotation] != deployment.Spec.Template.Annotations[render.ConfigHashAnnotation] {
+		t.Errorf("the Deployment must not be updated during a dry run")
+	}
+
+	// The result is cleared once the changes are applied
+	function = f.function("copy")
+	delete(function.Annotations, kfn.DryRunAnnotation)
+	function = f.update(function)
+	f.sync(function)
+
+	if f.function("copy").Status.DryRun != nil {
+		t.Errorf("the dry run must be cleared")
+	}
+	if live := f.deployment("copy"); *live.Spec.Replicas != 3 {
+		t.Errorf("replicas = %d, want 3", *live.Spec.Replicas)
+	}
+}
+
+func TestDryRunInvalid(t *testing.T) {
+	function := newTestFunction("copy")
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+
+	function = f.function("copy")
+	function.Annotations = map[string]string{kfn.DryRunAnnotation: "true"}
+	function.Spec.Profile = "unknown"
+	function = f.update(function)
+	f.sync(function)
+
+	dryRun := f.function("copy").Status.DryRun
+	if dryRun == nil || dryRun.ConfigError == "" {
+		t.Fatalf("the configuration error must be reported, got %+v", dryRun)
+	}
+	if dryRun.RollingRestart || dryRun.ConfigHashChanged || len(dryRun.Differences) > 0 {
+		t.Errorf("no change must be reported for an invalid configuration, got %+v", dryRun)
+	}
+}
@@ -0,0 +1,106 @@
+package function
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/registry"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+// SetImageResolver sets the Resolver used by the Functions pinning their
+// image digest.
+func (c *Controller) SetImageResolver(resolver registry.Resolver) {
+	c.imageResolver = resolver
+}
+
+// pinImage returns the Function with its image, and the image of its
+// shadow, pinned to their digest when PinImageDigest is enabled. The digest
+// recorded in the status is reused unless the image or the
+// kfn.dajac.io/refresh-digest annotation changed, so the Deployment only
+// rolls when the digest changes. It returns nil when the image has never
+// been resolved and can't be. The shadow is not synced while its image
+// can't be resolved, see syncShadow.
+func (c *Controller) pinImage(function *kfnv1alpha1.Function, status *kfnv1alpha1.FunctionStatus) *kfnv1alpha1.Function {
+	if !function.Spec.PinImageDigest {
+		status.Image = nil
+		status.ShadowImage = nil
+		return function
+	}
+
+	refresh := function.Annotations[kfn.RefreshDigestAnnotation]
+
+	status.Image = c.pinnedImage(function, function.Spec.Image, refresh, status.Image)
+	if status.Image.Pinned == "" {
+		return nil
+	}
+
+	// A shadow without image runs the pinned image of the Function
+	if shadow := function.Spec.Shadow; shadow != nil && shadow.Image != "" {
+		status.ShadowImage = c.pinnedImage(function, shadow.Image, refresh, status.ShadowImage)
+	} else {
+		status.ShadowImage = nil
+	}
+
+	return render.PinnedFunction(function, status.Image, status.ShadowImage)
+}
+
+// pinnedImage returns the status of the image, resolved again unless the
+// current status already pins it. Its Pinned reference is empty when the
+// image has never been resolved and can't be.
+func (c *Controller) pinnedImage(function *kfnv1alpha1.Function, image string, refresh string, current *kfnv1alpha1.ImageStatus) *kfnv1alpha1.ImageStatus {
+	if current != nil && current.Image == image && current.Refresh == refresh && current.Digest != "" {
+		return current
+	}
+
+	digest, pinned, err := c.resolveImage(image)
+	if err != nil {
+		glog.Infof("Can't resolve image %s of %s/%s: %s", image, function.Namespace, function.Name, err.Error())
+
+		if current != nil && current.Image == image && current.Digest != "" {
+			// Keep running the previous digest, the refresh is retried
+			// at the next sync
+			current.Error = err.Error()
+			return current
+		}
+
+		return &kfnv1alpha1.ImageStatus{
+			Image: image,
+			Error: err.Error(),
+		}
+	}
+
+	if current == nil || current.Digest != digest {
+		glog.Infof("Pin image %s of %s/%s to %s", image, function.Namespace, function.Name, digest)
+	}
+
+	return &kfnv1alpha1.ImageStatus{
+		Image:      image,
+		Digest:     digest,
+		Pinned:     pinned,
+		ResolvedAt: metav1.Now(),
+		Refresh:    refresh,
+	}
+}
+
+func (c *Controller) resolveImage(image string) (string, string, error) {
+	if c.imageResolver == nil {
+		return "", "", fmt.Errorf("the operator has no image resolver")
+	}
+
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return "", "", err
+	}
+
+	digest, err := c.imageResolver.Resolve(image)
+	if err != nil {
+		return "", "", err
+	}
+
+	return digest, ref.Pinned(digest), nil
+}
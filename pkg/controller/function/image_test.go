@@ -0,0 +1,159 @@
+package function
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/registry"
+)
+
+const (
+	testImage  = "dajac/kfn-examples:0.1.0"
+	testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testPinned = "docker.io/dajac/kfn-examples@" + testDigest
+)
+
+type failingResolver struct{}
+
+func (failingResolver) Resolve(image string) (string, error) {
+	return "", fmt.Errorf("registry unavailable")
+}
+
+// knownResolver only resolves the images it knows.
+type knownResolver map[string]string
+
+func (r knownResolver) Resolve(image string) (string, error) {
+	if digest, ok := r[image]; ok {
+		return digest, nil
+	}
+	return "", fmt.Errorf("unknown image %s", image)
+}
+
+func newPinnedFunction() *kfnv1alpha1.Function {
+	function := newTestFunction("function")
+	function.Spec.PinImageDigest = true
+	return function
+}
+
+func TestPinImageDisabled(t *testing.T) {
+	c := &Controller{imageResolver: registry.NewFake(map[string]string{testImage: testDigest})}
+	function := newPinnedFunction()
+	function.Spec.PinImageDigest = false
+	status := &kfnv1alpha1.FunctionStatus{Image: &kfnv1alpha1.ImageStatus{Image: testImage, Digest: testDigest, Pinned: testPinned}}
+
+	if pinned := c.pinImage(function, status); pinned != function {
+		t.Errorf("the Function must run its image")
+	}
+	if status.Image != nil {
+		t.Errorf("the image status must be cleared, got %+v", status.Image)
+	}
+}
+
+func TestPinImage(t *testing.T) {
+	resolver := registry.NewFake(map[string]string{testImage: testDigest})
+	c := &Controller{imageResolver: resolver}
+	function := newPinnedFunction()
+	status := &kfnv1alpha1.FunctionStatus{}
+
+	pinned := c.pinImage(function, status)
+	if pinned == nil || pinned.Spec.Image != testPinned {
+		t.Fatalf("pinned image = %v, want %s", pinned, testPinned)
+	}
+	if function.Spec.Image != testImage {
+		t.Errorf("the spec of the Function must not be modified")
+	}
+	if status.Image == nil || status.Image.Image != testImage || status.Image.Digest != testDigest {
+		t.Fatalf("unexpected image status %+v", status.Image)
+	}
+
+	// The digest of the status is reused until the refresh annotation
+	// changes
+	resolver.Digests[testImage] = "sha256:new"
+	if pinned := c.pinImage(function, status); pinned.Spec.Image != testPinned {
+		t.Errorf("pinned image = %s, want the digest of the status", pinned.Spec.Image)
+	}
+
+	function.Annotations = map[string]string{kfn.RefreshDigestAnnotation: "1"}
+	if pinned := c.pinImage(function, status); pinned.Spec.Image != "docker.io/dajac/kfn-examples@sha256:new" {
+		t.Errorf("pinned image = %s, want the refreshed digest", pinned.Spec.Image)
+	}
+	if status.Image.Refresh != "1" {
+		t.Errorf("refresh = %q, want 1", status.Image.Refresh)
+	}
+
+	// A new image is resolved
+	function.Spec.Image = "dajac/kfn-examples:0.2.0"
+	pinned = c.pinImage(function, status)
+	if status.Image.Image != "dajac/kfn-examples:0.2.0" || pinned.Spec.Image != status.Image.Pinned {
+		t.Errorf("the new image must be resolved, got %+v", status.Image)
+	}
+}
+
+func TestPinImageError(t *testing.T) {
+	c := &Controller{imageResolver: failingResolver{}}
+	function := newPinnedFunction()
+
+	// The previous digest keeps running
+	status := &kfnv1alpha1.FunctionStatus{Image: &kfnv1alpha1.ImageStatus{Image: testImage, Digest: testDigest, Pinned: testPinned}}
+	function.Annotations = map[string]string{kfn.RefreshDigestAnnotation: "1"}
+
+	if pinned := c.pinImage(function, status); pinned == nil || pinned.Spec.Image != testPinned {
+		t.Errorf("pinned image = %v, want the previous digest", pinned)
+	}
+	if status.Image.Error == "" {
+		t.Errorf("the error must be reported in the status")
+	}
+
+	// The image has never been resolved
+	status = &kfnv1alpha1.FunctionStatus{}
+	if pinned := c.pinImage(function, status); pinned != nil {
+		t.Errorf("pinned image = %s, want none", pinned.Spec.Image)
+	}
+	if status.Image == nil || status.Image.Error == "" {
+		t.Errorf("the error must be reported in the status, got %+v", status.Image)
+	}
+
+	// Without resolver
+	c = &Controller{}
+	status = &kfnv1alpha1.FunctionStatus{}
+	if pinned := c.pinImage(function, status); pinned != nil {
+		t.Errorf("pinned image = %s, want none", pinned.Spec.Image)
+	}
+}
+
+func TestPinShadowImage(t *testing.T) {
+	shadowImage := "dajac/kfn-examples:0.2.0"
+	shadowDigest := "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+
+	resolver := knownResolver{testImage: testDigest}
+	c := &Controller{imageResolver: resolver}
+	function := newPinnedFunction()
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{Image: shadowImage}
+	status := &kfnv1alpha1.FunctionStatus{}
+
+	// The Function runs while the image of the shadow can't be resolved
+	pinned := c.pinImage(function, status)
+	if pinned == nil || pinned.Spec.Image != testPinned {
+		t.Fatalf("pinned image = %v, want %s", pinned, testPinned)
+	}
+	if status.ShadowImage == nil || status.ShadowImage.Pinned != "" || status.ShadowImage.Error == "" {
+		t.Errorf("the error must be reported in the status, got %+v", status.ShadowImage)
+	}
+
+	resolver[shadowImage] = shadowDigest
+	pinned = c.pinImage(function, status)
+	if pinned.Spec.Shadow.Image != "docker.io/dajac/kfn-examples@"+shadowDigest {
+		t.Errorf("shadow image = %s, want the digest of the shadow", pinned.Spec.Shadow.Image)
+	}
+	if function.Spec.Shadow.Image != shadowImage {
+		t.Errorf("the spec of the Function must not be modified")
+	}
+
+	// A shadow without image runs the pinned image of the Function
+	function.Spec.Shadow.Image = ""
+	if pinned = c.pinImage(function, status); status.ShadowImage != nil || pinned.Spec.Shadow.Image != "" {
+		t.Errorf("shadow image = %s, status %+v, want the image of the Function", pinned.Spec.Shadow.Image, status.ShadowImage)
+	}
+}
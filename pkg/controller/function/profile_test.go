@@ -0,0 +1,44 @@
+package function
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/properties"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSyncProfile(t *testing.T) {
+	consumer := map[string]string{"max.poll.records": "7"}
+	profile := &kfnv1alpha1.FunctionProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+		Spec:       kfnv1alpha1.FunctionProfileSpec{ConsumerConfig: &consumer},
+	}
+
+	function := newTestFunction("copy")
+	function.Spec.Profile = "custom"
+	other := newTestFunction("other")
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function, other, profile)
+	f.sync(function)
+
+	configMap, err := f.kubeClient.CoreV1().ConfigMaps("default").Get("copy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := properties.Parse(render.ConfigData(configMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["consumer.max.poll.records"] != "7" {
+		t.Errorf("max.poll.records = %q, want 7", props["consumer.max.poll.records"])
+	}
+
+	// Only the Functions using the profile are enqueued when it changes.
+	// They are added after a delay so the requeues are counted instead
+	f.controller.handleProfile(profile)
+	if f.controller.workqueue.NumRequeues("default/copy") != 1 || f.controller.workqueue.NumRequeues("default/other") != 0 {
+		t.Errorf("only default/copy must be enqueued")
+	}
+}
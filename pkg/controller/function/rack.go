@@ -0,0 +1,122 @@
+package function
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+// handlePod enqueues the scheduled pods of the rack aware Functions whose
+// zone is not known yet.
+func (c *Controller) handlePod(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || !needsZone(pod) {
+		return
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(pod)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	c.zoneQueue.Add(key)
+}
+
+func needsZone(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[render.ZoneLabelsAnnotation]; !ok {
+		return false
+	}
+
+	if _, ok := pod.Annotations[render.ZoneAnnotation]; ok {
+		return false
+	}
+
+	return pod.Spec.NodeName != ""
+}
+
+func (c *Controller) runZoneWorker() {
+	for c.processNextZoneItem() {
+	}
+}
+
+func (c *Controller) processNextZoneItem() bool {
+	obj, shutdown := c.zoneQueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	defer c.zoneQueue.Done(obj)
+
+	if err := c.syncZone(obj.(string)); err != nil {
+		runtime.HandleError(fmt.Errorf("error setting the zone of pod '%s': %s", obj, err.Error()))
+		c.zoneQueue.AddRateLimited(obj)
+		return true
+	}
+
+	c.zoneQueue.Forget(obj)
+
+	return true
+}
+
+// syncZone annotates the pod with the zone of its node. The container
+// reads the annotation with the downward API.
+func (c *Controller) syncZone(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil
+	}
+
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !needsZone(pod) {
+		return nil
+	}
+
+	node, err := c.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+
+	labels := strings.Split(pod.Annotations[render.ZoneLabelsAnnotation], ",")
+	zone := render.NodeZone(node, labels)
+
+	if zone == render.NoZone {
+		glog.Infof("Node %s of pod %s/%s has none of the labels %s, the pod has no zone", node.Name, namespace, name, strings.Join(labels, ", "))
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				render.ZoneAnnotation: zone,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	glog.V(4).Infof("Setting the zone of pod %s/%s to %s", namespace, name, zone)
+
+	_, err = c.kubeClient.CoreV1().Pods(namespace).Patch(name, types.MergePatchType, patch)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
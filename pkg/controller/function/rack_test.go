@@ -0,0 +1,63 @@
+package function
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func newZonePod(name string, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{render.ZoneLabelsAnnotation: "example.com/zone"},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+	}
+}
+
+func TestSyncZone(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"example.com/zone": "eu-west-1a"},
+		},
+	}
+	unlabelled := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}
+	pending := newZonePod("copy-2", "")
+
+	f := newFixture(t, render.FunctionDefaultConfig{}, node, unlabelled, newZonePod("copy-0", "node-1"), newZonePod("copy-1", "node-2"), pending)
+
+	expected := map[string]string{"copy-0": "eu-west-1a", "copy-1": render.NoZone}
+	for name, zone := range expected {
+		if err := f.controller.syncZone("default/" + name); err != nil {
+			t.Fatal(err)
+		}
+
+		pod, err := f.kubeClient.CoreV1().Pods("default").Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := pod.Annotations[render.ZoneAnnotation]; actual != zone {
+			t.Errorf("%s: zone = %q, want %q", name, actual, zone)
+		}
+	}
+
+	// The zone is only known once the pod is scheduled
+	f.controller.handlePod(pending)
+	if f.controller.zoneQueue.Len() != 0 {
+		t.Errorf("the pending pod must not be enqueued")
+	}
+	f.controller.handlePod(newZonePod("copy-3", "node-1"))
+	if f.controller.zoneQueue.Len() != 1 {
+		t.Errorf("the scheduled pod must be enqueued")
+	}
+
+	if err := f.controller.syncZone("default/unknown"); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+}
@@ -0,0 +1,224 @@
+package function
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+const defaultRevisionHistoryLimit = 10
+
+const (
+	// reasonRolledBack is the reason of the event of the Functions rolled
+	// back to a revision.
+	reasonRolledBack = "RolledBack"
+
+	// reasonRollbackFailed is the reason of the event of the Functions
+	// whose rollback-to annotation names no revision.
+	reasonRollbackFailed = "RollbackFailed"
+)
+
+// revisionHash identifies the spec of the Function and its rendered
+// properties. Scaling, suspending or resuming the Function does not create
+// a new revision.
+func revisionHash(function *kfnv1alpha1.Function, configMap *corev1.ConfigMap) string {
+	spec := function.Spec.DeepCopy()
+	spec.Replicas = 0
+	spec.Suspend = false
+
+	data, _ := json.Marshal(spec)
+
+	h := sha256.New()
+	h.Write(data)
+	h.Write([]byte(render.ConfigData(configMap)))
+	return hex.EncodeToString(h.Sum(nil))[:10]
+}
+
+// listRevisions returns the FunctionRevisions of the Function ordered by
+// revision number.
+func (c *Controller) listRevisions(function *kfnv1alpha1.Function) ([]*kfnv1alpha1.FunctionRevision, error) {
+	all, err := c.revisionLister.FunctionRevisions(function.Namespace).List(labels.SelectorFromSet(labels.Set{
+		"function": function.Name,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []*kfnv1alpha1.FunctionRevision{}
+	for _, revision := range all {
+		if metav1.IsControlledBy(revision, function) {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Spec.Revision < revisions[j].Spec.Revision
+	})
+
+	return revisions, nil
+}
+
+// syncRevisions records a new FunctionRevision when the spec or the
+// rendered properties of the Function have changed since the last one and
+// deletes the revisions above the history limit.
+func (c *Controller) syncRevisions(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig, status *kfnv1alpha1.FunctionStatus) error {
+	revisions, err := c.listRevisions(function)
+	if err != nil {
+		return err
+	}
+
+	configMap := render.NewConfigMap(function, functionConfig)
+	hash := revisionHash(function, configMap)
+
+	if n := len(revisions); n == 0 || revisions[n-1].Spec.Hash != hash {
+		number := int64(1)
+		if n > 0 {
+			number = revisions[n-1].Spec.Revision + 1
+		}
+
+		revision, err := c.createRevision(function, configMap, hash, number)
+		if err != nil {
+			return err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	status.CurrentRevision = revisions[len(revisions)-1].Name
+
+	limit := defaultRevisionHistoryLimit
+	if function.Spec.RevisionHistoryLimit != nil {
+		limit = int(*function.Spec.RevisionHistoryLimit)
+	}
+
+	// The current revision is always kept
+	if limit < 1 {
+		limit = 1
+	}
+
+	for _, revision := range revisions[:maxInt(len(revisions)-limit, 0)] {
+		glog.Infof("Delete FunctionRevision %s/%s", revision.Namespace, revision.Name)
+
+		err := c.kfnClient.KfnV1alpha1().FunctionRevisions(revision.Namespace).Delete(revision.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) createRevision(function *kfnv1alpha1.Function, configMap *corev1.ConfigMap, hash string, number int64) (*kfnv1alpha1.FunctionRevision, error) {
+	revision := &kfnv1alpha1.FunctionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", function.Name, number),
+			Namespace: function.Namespace,
+			Labels: map[string]string{
+				"function": function.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(function, schema.GroupVersionKind{
+					Group:   kfnv1alpha1.SchemeGroupVersion.Group,
+					Version: kfnv1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Function",
+				}),
+			},
+		},
+		Spec: kfnv1alpha1.FunctionRevisionSpec{
+			Function:     function.Name,
+			Revision:     number,
+			Hash:         hash,
+			Image:        function.Spec.Image,
+			Properties:   render.ConfigData(configMap),
+			FunctionSpec: *function.Spec.DeepCopy(),
+		},
+	}
+
+	glog.Infof("Create FunctionRevision %s/%s", revision.Namespace, revision.Name)
+
+	created, err := c.kfnClient.KfnV1alpha1().FunctionRevisions(function.Namespace).Create(revision)
+	if errors.IsAlreadyExists(err) {
+		// The lister has not seen the revision created by a previous sync yet
+		existing, getErr := c.kfnClient.KfnV1alpha1().FunctionRevisions(function.Namespace).Get(revision.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return nil, getErr
+		}
+
+		if existing.Spec.Hash != hash || !metav1.IsControlledBy(existing, function) {
+			return nil, fmt.Errorf("FunctionRevision '%s/%s' already exists", revision.Namespace, revision.Name)
+		}
+
+		return existing, nil
+	}
+
+	return created, err
+}
+
+// rollbackTo restores the spec of the revision given by the
+// kfn.dajac.io/rollback-to annotation, except the replicas and the
+// suspension, and removes the annotation. The controller then records the
+// restored spec as a new revision. An annotation naming no revision is
+// removed as well, after a warning event.
+func (c *Controller) rollbackTo(function *kfnv1alpha1.Function) error {
+	value := function.Annotations[kfn.RollbackToAnnotation]
+
+	newFunction := function.DeepCopy()
+	delete(newFunction.Annotations, kfn.RollbackToAnnotation)
+
+	revision, err := c.findRevision(function, value)
+	if err != nil {
+		glog.Errorf("Can't roll back %s/%s: %s", function.Namespace, function.Name, err.Error())
+		c.recorder.Eventf(function, corev1.EventTypeWarning, reasonRollbackFailed, "Can't roll back: %s", err.Error())
+	} else {
+		glog.Infof("Roll back %s/%s to revision %d", function.Namespace, function.Name, revision.Spec.Revision)
+		c.recorder.Eventf(function, corev1.EventTypeNormal, reasonRolledBack, "Rolled back to revision %d", revision.Spec.Revision)
+
+		newFunction.Spec = *revision.Spec.FunctionSpec.DeepCopy()
+		newFunction.Spec.Replicas = function.Spec.Replicas
+		newFunction.Spec.Suspend = function.Spec.Suspend
+	}
+
+	_, err = c.kfnClient.KfnV1alpha1().Functions(function.Namespace).Update(newFunction)
+	return err
+}
+
+func (c *Controller) findRevision(function *kfnv1alpha1.Function, value string) (*kfnv1alpha1.FunctionRevision, error) {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid revision %q", value)
+	}
+
+	revisions, err := c.listRevisions(function)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Spec.Revision == number {
+			return revision, nil
+		}
+	}
+
+	return nil, fmt.Errorf("revision %d does not exist", number)
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
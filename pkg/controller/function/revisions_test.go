@@ -0,0 +1,138 @@
+package function
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	eventrecord "k8s.io/client-go/tools/record"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/client/clientset/versioned/fake"
+	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func testRevisionHash(function *kfnv1alpha1.Function) string {
+	config := render.NewFunctionConfig(&render.FunctionDefaultConfig{}, function)
+	return revisionHash(function, render.NewConfigMap(function, config))
+}
+
+func TestRevisionHash(t *testing.T) {
+	function := newTestFunction("function")
+	hash := testRevisionHash(function)
+
+	scaled := function.DeepCopy()
+	scaled.Spec.Replicas = 5
+	if testRevisionHash(scaled) != hash {
+		t.Errorf("scaling the Function must not change its revision")
+	}
+
+	suspended := function.DeepCopy()
+	suspended.Spec.Suspend = true
+	if testRevisionHash(suspended) != hash {
+		t.Errorf("suspending the Function must not change its revision")
+	}
+
+	updated := function.DeepCopy()
+	updated.Spec.Image = "dajac/kfn-examples:0.2.0"
+	if testRevisionHash(updated) == hash {
+		t.Errorf("a new image must change the revision")
+	}
+}
+
+// newRevisionController returns a controller whose cache and API server
+// hold the Function and its revisions.
+func newRevisionController(function *kfnv1alpha1.Function, revisions ...*kfnv1alpha1.FunctionRevision) (*Controller, *fake.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	objects := []runtime.Object{function}
+	for _, revision := range revisions {
+		indexer.Add(revision)
+		objects = append(objects, revision)
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+	c := &Controller{
+		kfnClient:      client,
+		revisionLister: listers.NewFunctionRevisionLister(indexer),
+		recorder:       eventrecord.NewFakeRecorder(10),
+	}
+	return c, client
+}
+
+func newRevision(function *kfnv1alpha1.Function, number int64) *kfnv1alpha1.FunctionRevision {
+	config := render.NewFunctionConfig(&render.FunctionDefaultConfig{}, function)
+	configMap := render.NewConfigMap(function, config)
+
+	c := &Controller{kfnClient: fake.NewSimpleClientset()}
+	revision, _ := c.createRevision(function, configMap, revisionHash(function, configMap), number)
+	return revision
+}
+
+func TestRollbackTo(t *testing.T) {
+	old := newTestFunction("function")
+	old.Spec.Image = "dajac/kfn-examples:0.0.9"
+	old.Spec.Replicas = 1
+
+	function := newTestFunction("function")
+	function.Spec.Replicas = 4
+	function.Spec.Suspend = true
+	function.Annotations = map[string]string{kfn.RollbackToAnnotation: "1"}
+
+	c, client := newRevisionController(function, newRevision(old, 1))
+
+	if err := c.rollbackTo(function); err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := client.KfnV1alpha1().Functions("default").Get("function", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rolledBack.Spec.Image != "dajac/kfn-examples:0.0.9" {
+		t.Errorf("image = %s, want the image of the revision", rolledBack.Spec.Image)
+	}
+	if rolledBack.Spec.Replicas != 4 || !rolledBack.Spec.Suspend {
+		t.Errorf("replicas = %d, suspend = %t, want the live ones", rolledBack.Spec.Replicas, rolledBack.Spec.Suspend)
+	}
+	if _, ok := rolledBack.Annotations[kfn.RollbackToAnnotation]; ok {
+		t.Errorf("the rollback annotation must be removed")
+	}
+}
+
+func TestRollbackToUnknownRevision(t *testing.T) {
+	for _, value := range []string{"2", "previous"} {
+		function := newTestFunction("function")
+		function.Annotations = map[string]string{kfn.RollbackToAnnotation: value}
+
+		c, client := newRevisionController(function, newRevision(newTestFunction("function"), 1))
+
+		if err := c.rollbackTo(function); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case event := <-c.recorder.(*eventrecord.FakeRecorder).Events:
+			if !strings.HasPrefix(event, "Warning "+reasonRollbackFailed) {
+				t.Errorf("%s: event = %q, want a %s warning", value, event, reasonRollbackFailed)
+			}
+		default:
+			t.Errorf("%s: the failed rollback must be recorded as an event", value)
+		}
+
+		updated, err := client.KfnV1alpha1().Functions("default").Get("function", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := updated.Annotations[kfn.RollbackToAnnotation]; ok {
+			t.Errorf("%s: the rollback annotation must be removed", value)
+		}
+		if updated.Spec.Image != function.Spec.Image {
+			t.Errorf("%s: the spec must be kept", value)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package function
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSyncRuntime(t *testing.T) {
+	java := &kfnv1alpha1.FunctionRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "java"},
+		Spec:       kfnv1alpha1.FunctionRuntimeSpec{Command: []string{"/opt/java/bin/java", "-jar", "/invoker.jar"}},
+	}
+
+	function := newTestFunction("copy")
+	python := newTestFunction("python")
+	python.Spec.Runtime = "python"
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function, python, java)
+	f.sync(function)
+
+	// The FunctionRuntime overrides the default runtime
+	command := f.deployment("copy").Spec.Template.Spec.Containers[0].Command
+	if !strings.HasSuffix(command[len(command)-1], "exec '/opt/java/bin/java' '-jar' '/invoker.jar' '/var/run/kfn/function.properties'") {
+		t.Errorf("unexpected command %v", command)
+	}
+
+	f.controller.handleRuntime(java)
+	if f.controller.workqueue.NumRequeues("default/copy") != 1 || f.controller.workqueue.NumRequeues("default/python") != 0 {
+		t.Errorf("only the Functions of the default runtime must be enqueued")
+	}
+
+	// An unknown runtime is reported
+	function = f.function("copy")
+	function.Spec.Runtime = "ruby"
+	function = f.update(function)
+	f.sync(function)
+
+	if status := f.function("copy").Status; status.ConfigError == "" {
+		t.Errorf("the unknown runtime must be reported")
+	}
+}
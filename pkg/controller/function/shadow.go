@@ -0,0 +1,68 @@
+package function
+
+import (
+	"fmt"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+// syncShadow creates or updates the ConfigMap and the Deployment of the
+// shadow of the Function, or deletes them when the Function has no shadow
+// anymore.
+func (c *Controller) syncShadow(function *kfnv1alpha1.Function, defaultConfig *render.FunctionDefaultConfig, functionConfig *render.FunctionConfig, status *kfnv1alpha1.FunctionStatus) error {
+	if function.Spec.Shadow == nil {
+		status.Shadow = nil
+		return c.deleteShadow(function)
+	}
+
+	shadow := render.NewShadowFunction(function)
+	shadowConfig := render.NewFunctionConfig(defaultConfig, shadow)
+
+	status.Shadow = &kfnv1alpha1.ShadowStatus{
+		ConsumerGroup: shadowConfig.Consumer["group.id"],
+		Output:        shadowConfig.Function["output"],
+	}
+
+	if shadowConfig.Consumer["group.id"] == functionConfig.Consumer["group.id"] {
+		status.Shadow.Error = fmt.Sprintf("the shadow can't use the consumer group %q of the function", functionConfig.Consumer["group.id"])
+		glog.Infof("Shadow of %s/%s is invalid: %s", function.Namespace, function.Name, status.Shadow.Error)
+		return c.deleteShadow(function)
+	}
+
+	if shadowConfig.Function["output"] == functionConfig.Function["output"] {
+		status.Shadow.Error = fmt.Sprintf("the shadow can't write to the output %q of the function", functionConfig.Function["output"])
+		glog.Infof("Shadow of %s/%s is invalid: %s", function.Namespace, function.Name, status.Shadow.Error)
+		return c.deleteShadow(function)
+	}
+
+	// The shadow keeps running its previous image until its new image is
+	// resolved
+	if image := status.ShadowImage; function.Spec.PinImageDigest && image != nil && image.Pinned == "" {
+		status.Shadow.Error = fmt.Sprintf("the image of the shadow can't be resolved: %s", image.Error)
+		return nil
+	}
+
+	name := render.ShadowName(function)
+
+	configmap, err := c.syncConfigMap(function, render.NewNamedConfigMap(shadow, shadowConfig, name))
+	if err != nil {
+		return err
+	}
+
+	deployement, err := c.syncDeployement(function, render.NewNamedDeployement(shadow, shadowConfig, configmap, name, render.ShadowLabels(function)))
+	if err != nil {
+		return err
+	}
+
+	status.Shadow.AvailableReplicas = deployement.Status.AvailableReplicas
+
+	return nil
+}
+
+// deleteShadow deletes the ConfigMap and the Deployment of the shadow if
+// they exist.
+func (c *Controller) deleteShadow(function *kfnv1alpha1.Function) error {
+	return c.deleteResources(function, render.ShadowName(function))
+}
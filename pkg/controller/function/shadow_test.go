@@ -0,0 +1,81 @@
+package function
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSyncShadow(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{Image: "dajac/kfn-examples:0.2.0"}
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+
+	shadow := f.deployment("copy-shadow")
+	if image := shadow.Spec.Template.Spec.Containers[0].Image; image != "dajac/kfn-examples:0.2.0" {
+		t.Errorf("image = %s, want dajac/kfn-examples:0.2.0", image)
+	}
+	status := f.function("copy").Status.Shadow
+	if status == nil || status.ConsumerGroup != "default_copy_shadow" || status.Output != "copy.output.shadow" || status.Error != "" {
+		t.Errorf("unexpected shadow status %+v", status)
+	}
+
+	// The consumer group of the Function is refused
+	function = f.function("copy")
+	function.Spec.Shadow.ConsumerGroup = "copy"
+	function = f.update(function)
+	f.sync(function)
+
+	if status := f.function("copy").Status.Shadow; status == nil || status.Error == "" {
+		t.Errorf("the shared consumer group must be reported, got %+v", status)
+	}
+	if _, err := f.kubeClient.AppsV1().Deployments("default").Get("copy-shadow", metav1.GetOptions{}); err == nil {
+		t.Errorf("the invalid shadow must be deleted")
+	}
+
+	// Removing the shadow clears the status
+	function = f.function("copy")
+	function.Spec.Shadow = nil
+	function = f.update(function)
+	f.sync(function)
+
+	if f.function("copy").Status.Shadow != nil {
+		t.Errorf("the shadow status must be cleared")
+	}
+	if _, err := f.kubeClient.CoreV1().ConfigMaps("default").Get("copy-shadow", metav1.GetOptions{}); err == nil {
+		t.Errorf("the shadow ConfigMap must be deleted")
+	}
+}
+
+func TestSyncShadowPinnedImage(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.PinImageDigest = true
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{Image: "dajac/kfn-examples:0.2.0"}
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	resolver := knownResolver{testImage: testDigest}
+	f.controller.SetImageResolver(resolver)
+	f.sync(function)
+
+	// The Function runs while the image of the shadow can't be resolved
+	if image := f.deployment("copy").Spec.Template.Spec.Containers[0].Image; image != testPinned {
+		t.Errorf("image = %s, want %s", image, testPinned)
+	}
+	if status := f.function("copy").Status.Shadow; status == nil || !strings.Contains(status.Error, "can't be resolved") {
+		t.Errorf("the unresolved image must be reported, got %+v", status)
+	}
+	if _, err := f.kubeClient.AppsV1().Deployments("default").Get("copy-shadow", metav1.GetOptions{}); err == nil {
+		t.Errorf("the shadow must not run an unresolved image")
+	}
+
+	resolver["dajac/kfn-examples:0.2.0"] = testDigest
+	f.sync(f.function("copy"))
+
+	if image := f.deployment("copy-shadow").Spec.Template.Spec.Containers[0].Image; image != "docker.io/dajac/kfn-examples@"+testDigest {
+		t.Errorf("shadow image = %s, want the pinned image", image)
+	}
+}
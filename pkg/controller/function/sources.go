@@ -0,0 +1,67 @@
+package function
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// listerSources reads the ConfigMaps and the Secrets of the Functions from
+// the caches of the informers.
+type listerSources struct {
+	configMapLister corelisters.ConfigMapLister
+	secretLister    corelisters.SecretLister
+}
+
+func (s *listerSources) ConfigMap(namespace string, name string) (*corev1.ConfigMap, error) {
+	return s.configMapLister.ConfigMaps(namespace).Get(name)
+}
+
+func (s *listerSources) Secret(namespace string, name string) (*corev1.Secret, error) {
+	return s.secretLister.Secrets(namespace).Get(name)
+}
+
+func (c *Controller) sources() render.Sources {
+	return &listerSources{
+		configMapLister: c.configMapLister,
+		secretLister:    c.secretLister,
+	}
+}
+
+// handleSource enqueues the Functions reading the ConfigMap or the Secret.
+func (c *Controller) handleSource(obj interface{}) {
+	object, ok := objectFromEvent(obj)
+	if !ok {
+		return
+	}
+
+	functions, err := c.functionLister.Functions(object.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	_, isSecret := object.(*corev1.Secret)
+
+	for _, function := range functions {
+		if isSecret && render.ReadsSecret(function, object.GetName()) {
+			c.enqueueFunction(function)
+		}
+		if !isSecret && render.ReadsConfigMap(function, object.GetName()) {
+			c.enqueueFunction(function)
+		}
+	}
+}
+
+// updateSource handles the updates of the ConfigMaps and the Secrets,
+// ignoring the periodic resyncs.
+func (c *Controller) updateSource(old, new interface{}) {
+	if old.(metav1.Object).GetResourceVersion() == new.(metav1.Object).GetResourceVersion() {
+		return
+	}
+	c.handleSource(new)
+}
@@ -0,0 +1,64 @@
+package function
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSyncSources(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{{
+		Name: "threshold",
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+			Key:                  "threshold",
+		},
+	}}
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+
+	// The missing ConfigMap is reported
+	f.sync(function)
+	if status := f.function("copy").Status; status.ConfigError == "" {
+		t.Errorf("the missing ConfigMap must be reported")
+	}
+
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "settings"},
+		Data:       map[string]string{"threshold": "10"},
+	}
+	if _, err := f.kubeClient.CoreV1().ConfigMaps("default").Create(settings); err != nil {
+		t.Fatal(err)
+	}
+	f.refresh()
+
+	f.sync(f.function("copy"))
+	if status := f.function("copy").Status; status.ConfigError != "" {
+		t.Errorf("unexpected error %q", status.ConfigError)
+	}
+	hash := f.deployment("copy").Spec.Template.Annotations[render.SourcesHashAnnotation]
+	if hash == "" {
+		t.Errorf("the pods must be annotated with the hash of the sources")
+	}
+
+	// A new value enqueues the Function and rolls its pods
+	settings.Data["threshold"] = "20"
+	if _, err := f.kubeClient.CoreV1().ConfigMaps("default").Update(settings); err != nil {
+		t.Fatal(err)
+	}
+	f.refresh()
+
+	f.controller.handleSource(settings)
+	if f.controller.workqueue.NumRequeues("default/copy") != 1 {
+		t.Errorf("the Function reading the ConfigMap must be enqueued")
+	}
+
+	f.sync(f.function("copy"))
+	if f.deployment("copy").Spec.Template.Annotations[render.SourcesHashAnnotation] == hash {
+		t.Errorf("the pods must be rolled")
+	}
+}
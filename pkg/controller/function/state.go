@@ -0,0 +1,161 @@
+package function
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+// syncState resizes the PersistentVolumeClaims of the Function and applies
+// its retention policy. The claims are created by the StatefulSet and are
+// not deleted with it. With the Delete policy, the Function becomes an
+// owner of the claims so they are garbage collected with it.
+func (c *Controller) syncState(function *kfnv1alpha1.Function, status *kfnv1alpha1.FunctionStatus) error {
+	if function.Spec.State == nil {
+		status.State = nil
+		return nil
+	}
+
+	size, _ := render.StateSize(function)
+	status.State = &kfnv1alpha1.StateStatus{
+		Size: size.String(),
+	}
+
+	claims, err := c.listStateClaims(function)
+	if err != nil {
+		return err
+	}
+
+	status.State.Claims = int32(len(claims))
+
+	owned := function.Spec.State.RetentionPolicy == kfnv1alpha1.DeleteState
+
+	for _, claim := range claims {
+		newClaim := claim.DeepCopy()
+
+		current := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		switch current.Cmp(size) {
+		case -1:
+			glog.Infof("Resize PersistentVolumeClaim %s/%s from %s to %s", claim.Namespace, claim.Name, current.String(), size.String())
+			if newClaim.Spec.Resources.Requests == nil {
+				newClaim.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			newClaim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		case 1:
+			status.State.Error = fmt.Sprintf("the volumes can't be shrunk from %s to %s", current.String(), size.String())
+		}
+
+		newClaim.OwnerReferences = stateOwnerReferences(function, claim.OwnerReferences, owned)
+
+		if equality.Semantic.DeepEqual(claim, newClaim) {
+			continue
+		}
+
+		if _, err := c.kubeClient.CoreV1().PersistentVolumeClaims(claim.Namespace).Update(newClaim); err != nil {
+			if errors.IsForbidden(err) || errors.IsInvalid(err) {
+				// The storage class does not allow volume expansion
+				status.State.Error = err.Error()
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listStateClaims returns the PersistentVolumeClaims created by the
+// StatefulSet of the Function.
+func (c *Controller) listStateClaims(function *kfnv1alpha1.Function) ([]*corev1.PersistentVolumeClaim, error) {
+	all, err := c.claimLister.PersistentVolumeClaims(function.Namespace).List(labels.SelectorFromSet(labels.Set{
+		"function": function.Name,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims := []*corev1.PersistentVolumeClaim{}
+	for _, claim := range all {
+		if strings.HasPrefix(claim.Name, render.StateClaimPrefix(function)) {
+			claims = append(claims, claim)
+		}
+	}
+
+	return claims, nil
+}
+
+// stateOwnerReferences adds or removes the Function from the owners of a
+// claim. The Function is not the controller of the claim.
+func stateOwnerReferences(function *kfnv1alpha1.Function, references []metav1.OwnerReference, owned bool) []metav1.OwnerReference {
+	result := []metav1.OwnerReference{}
+	for _, reference := range references {
+		if reference.UID != function.UID {
+			result = append(result, reference)
+		}
+	}
+
+	if owned {
+		reference := metav1.NewControllerRef(function, schema.GroupVersionKind{
+			Group:   kfnv1alpha1.SchemeGroupVersion.Group,
+			Version: kfnv1alpha1.SchemeGroupVersion.Version,
+			Kind:    "Function",
+		})
+		controller := false
+		reference.Controller = &controller
+		result = append(result, *reference)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// claimTemplatesChanged returns true if the volume claim templates of the
+// StatefulSet differ. They can't be updated so the StatefulSet has to be
+// recreated.
+func claimTemplatesChanged(statefulSet *appsv1.StatefulSet, newStatefulSet *appsv1.StatefulSet) bool {
+	if len(statefulSet.Spec.VolumeClaimTemplates) != len(newStatefulSet.Spec.VolumeClaimTemplates) {
+		return true
+	}
+
+	for i, template := range statefulSet.Spec.VolumeClaimTemplates {
+		newTemplate := newStatefulSet.Spec.VolumeClaimTemplates[i]
+
+		if template.Name != newTemplate.Name ||
+			!equality.Semantic.DeepEqual(template.Spec.StorageClassName, newTemplate.Spec.StorageClassName) ||
+			!equality.Semantic.DeepEqual(template.Spec.Resources.Requests, newTemplate.Spec.Resources.Requests) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orphanStatefulSet deletes the StatefulSet but keeps its pods, which are
+// adopted by the StatefulSet created by the next sync.
+func (c *Controller) orphanStatefulSet(statefulSet *appsv1.StatefulSet) error {
+	glog.Infof("Recreate StatefulSet %s/%s", statefulSet.Namespace, statefulSet.Name)
+
+	orphan := metav1.DeletePropagationOrphan
+	err := c.kubeClient.AppsV1().StatefulSets(statefulSet.Namespace).Delete(statefulSet.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
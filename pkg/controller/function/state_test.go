@@ -0,0 +1,86 @@
+package function
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// newStateClaim returns the claim created by the StatefulSet for the pod
+// of the ordinal.
+func newStateClaim(function *kfnv1alpha1.Function, ordinal string, size string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: function.Namespace,
+			Name:      render.StateClaimPrefix(function) + ordinal,
+			Labels:    map[string]string{"function": function.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+}
+
+func (f *fixture) claim(name string) *corev1.PersistentVolumeClaim {
+	claim, err := f.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return claim
+}
+
+func TestSyncState(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.State = &kfnv1alpha1.StateSpec{Size: "2Gi"}
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function,
+		newStateClaim(function, "0", "1Gi"),
+		newStateClaim(function, "1", "2Gi"),
+	)
+	f.sync(function)
+
+	status := f.function("copy").Status.State
+	if status == nil || status.Claims != 2 || status.Size != "2Gi" || status.Error != "" {
+		t.Errorf("unexpected state status %+v", status)
+	}
+
+	// The smaller volume is resized and the claims are retained
+	for _, name := range []string{"state-copy-0", "state-copy-1"} {
+		claim := f.claim(name)
+		if size := claim.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "2Gi" {
+			t.Errorf("%s: size = %s, want 2Gi", name, size.String())
+		}
+		if len(claim.OwnerReferences) != 0 {
+			t.Errorf("%s: the retained claims must not be owned", name)
+		}
+	}
+
+	// The claims are owned by the Function with the Delete policy
+	function = f.function("copy")
+	function.Spec.State.RetentionPolicy = kfnv1alpha1.DeleteState
+	function = f.update(function)
+	f.sync(function)
+
+	references := f.claim("state-copy-0").OwnerReferences
+	if len(references) != 1 || references[0].UID != function.UID || *references[0].Controller {
+		t.Errorf("unexpected owner references %+v", references)
+	}
+
+	// The volumes can't be shrunk
+	function = f.function("copy")
+	function.Spec.State.Size = "1Gi"
+	function = f.update(function)
+	f.sync(function)
+
+	if status := f.function("copy").Status.State; status == nil || status.Error == "" {
+		t.Errorf("the shrinking must be reported, got %+v", status)
+	}
+}
@@ -0,0 +1,45 @@
+package function
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSyncWorkloadType(t *testing.T) {
+	function := newTestFunction("copy")
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+	f.deployment("copy")
+
+	// The Deployment is replaced by a StatefulSet
+	function = f.function("copy")
+	function.Spec.WorkloadType = kfnv1alpha1.StatefulSetWorkload
+	function = f.update(function)
+	f.sync(function)
+
+	statefulSet, err := f.kubeClient.AppsV1().StatefulSets("default").Get("copy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *statefulSet.Spec.Replicas != 2 || !metav1.IsControlledBy(statefulSet, function) {
+		t.Errorf("unexpected StatefulSet with %d replicas", *statefulSet.Spec.Replicas)
+	}
+	if _, err := f.kubeClient.AppsV1().Deployments("default").Get("copy", metav1.GetOptions{}); err == nil {
+		t.Errorf("the Deployment must be deleted")
+	}
+
+	// And back
+	function = f.function("copy")
+	function.Spec.WorkloadType = kfnv1alpha1.DeploymentWorkload
+	function = f.update(function)
+	f.sync(function)
+
+	f.deployment("copy")
+	if _, err := f.kubeClient.AppsV1().StatefulSets("default").Get("copy", metav1.GetOptions{}); err == nil {
+		t.Errorf("the StatefulSet must be deleted")
+	}
+}
@@ -0,0 +1,99 @@
+package function
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+	"github.com/golang/glog"
+)
+
+// suspend scales all the Deployments of the Function, including the ones of
+// a canary rollout and of the shadow, and its StatefulSet to zero. Their pod
+// templates are left untouched so the Function resumes where it was
+// suspended. The Replicas of the Function are not changed.
+func (c *Controller) suspend(function *kfnv1alpha1.Function, functionConfig *render.FunctionConfig) (int32, error) {
+	var err error
+	if render.IsStatefulSet(function) {
+		_, err = c.statefulSetLister.StatefulSets(function.Namespace).Get(function.Name)
+	} else {
+		_, err = c.deployementLister.Deployments(function.Namespace).Get(function.Name)
+	}
+
+	if errors.IsNotFound(err) {
+		// The Function is created suspended
+		suspended := function.DeepCopy()
+		suspended.Spec.Replicas = 0
+		return c.syncRollingUpdate(suspended, functionConfig)
+	}
+
+	available, err := c.suspendStatefulSet(function)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, name := range []string{function.Name, canaryName(function), render.ShadowName(function)} {
+		deployement, err := c.deployementLister.Deployments(function.Namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return 0, err
+		}
+
+		if !metav1.IsControlledBy(deployement, function) {
+			continue
+		}
+
+		available += deployement.Status.AvailableReplicas
+
+		if *deployement.Spec.Replicas == 0 {
+			continue
+		}
+
+		glog.Infof("Suspend Deployement %s/%s", deployement.Namespace, deployement.Name)
+
+		deployement = deployement.DeepCopy()
+		replicas := int32(0)
+		deployement.Spec.Replicas = &replicas
+
+		if _, err := c.kubeClient.AppsV1().Deployments(deployement.Namespace).Update(deployement); err != nil {
+			return 0, err
+		}
+	}
+
+	return available, nil
+}
+
+func (c *Controller) suspendStatefulSet(function *kfnv1alpha1.Function) (int32, error) {
+	statefulSet, err := c.statefulSetLister.StatefulSets(function.Namespace).Get(function.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if !metav1.IsControlledBy(statefulSet, function) {
+		return 0, nil
+	}
+
+	if *statefulSet.Spec.Replicas == 0 {
+		return statefulSet.Status.ReadyReplicas, nil
+	}
+
+	glog.Infof("Suspend StatefulSet %s/%s", statefulSet.Namespace, statefulSet.Name)
+
+	available := statefulSet.Status.ReadyReplicas
+
+	statefulSet = statefulSet.DeepCopy()
+	replicas := int32(0)
+	statefulSet.Spec.Replicas = &replicas
+
+	if _, err := c.kubeClient.AppsV1().StatefulSets(statefulSet.Namespace).Update(statefulSet); err != nil {
+		return 0, err
+	}
+
+	return available, nil
+}
@@ -0,0 +1,54 @@
+package function
+
+import (
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestSuspend(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{}
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+
+	hash := f.deployment("copy").Spec.Template.Annotations[render.ConfigHashAnnotation]
+
+	function = f.function("copy")
+	function.Spec.Suspend = true
+	function.Spec.Output = "copy.other"
+	f.sync(f.update(function))
+
+	// The Deployments are scaled to zero and keep their pod templates
+	f.checkReplicas("copy", 0)
+	f.checkReplicas("copy-shadow", 0)
+	if f.deployment("copy").Spec.Template.Annotations[render.ConfigHashAnnotation] != hash {
+		t.Errorf("the pod template must not change while suspended")
+	}
+	if function = f.function("copy"); !function.Status.Suspended || function.Spec.Replicas != 2 {
+		t.Errorf("the Function must be suspended with its replicas, got %+v", function.Status)
+	}
+
+	// The Function resumes with its replicas and its new spec
+	function.Spec.Suspend = false
+	f.sync(f.update(function))
+
+	f.checkReplicas("copy", 2)
+	f.checkReplicas("copy-shadow", 1)
+	if f.deployment("copy").Spec.Template.Annotations[render.ConfigHashAnnotation] == hash {
+		t.Errorf("the changes made while suspended must be applied")
+	}
+	if f.function("copy").Status.Suspended {
+		t.Errorf("the Function must be resumed")
+	}
+}
+
+func TestCreateSuspended(t *testing.T) {
+	function := newTestFunction("copy")
+	function.Spec.Suspend = true
+	f := newFixture(t, render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	f.sync(function)
+
+	f.checkReplicas("copy", 0)
+}
@@ -0,0 +1,315 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	clientset "github.com/dajac/kfn/pkg/client/clientset/versioned"
+	informers "github.com/dajac/kfn/pkg/client/informers/externalversions/kfn/v1alpha1"
+	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+	"github.com/golang/glog"
+)
+
+// Controller creates and owns the Functions of the Pipelines.
+type Controller struct {
+	kfnClient      clientset.Interface
+	functionLister listers.FunctionLister
+	functionSynced cache.InformerSynced
+	pipelineLister listers.PipelineLister
+	pipelineSynced cache.InformerSynced
+
+	workqueue workqueue.RateLimitingInterface
+}
+
+func NewController(
+	kfnClient clientset.Interface,
+	functionInformer informers.FunctionInformer,
+	pipelineInformer informers.PipelineInformer) *Controller {
+
+	controller := &Controller{
+		kfnClient:      kfnClient,
+		functionLister: functionInformer.Lister(),
+		functionSynced: functionInformer.Informer().HasSynced,
+		pipelineLister: pipelineInformer.Lister(),
+		pipelineSynced: pipelineInformer.Informer().HasSynced,
+		workqueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Pipelines"),
+	}
+
+	functionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+
+	pipelineInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueuePipeline,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueuePipeline(new)
+		},
+		DeleteFunc: controller.enqueuePipeline,
+	})
+
+	return controller
+}
+
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer runtime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	glog.Info("Starting Pipeline controller")
+
+	glog.Info("Waiting for informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, c.functionSynced, c.pipelineSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	glog.Info("Starting workers")
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	glog.Info("Started workers")
+	<-stopCh
+	glog.Info("Shutting down workers")
+
+	return nil
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.workqueue.Done(obj)
+		var key string
+		var ok bool
+
+		if key, ok = obj.(string); !ok {
+			c.workqueue.Forget(obj)
+			runtime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+
+		if err := c.syncHandler(key); err != nil {
+			return fmt.Errorf("error syncing '%s': %s", key, err.Error())
+		}
+
+		c.workqueue.Forget(obj)
+
+		return nil
+	}(obj)
+
+	if err != nil {
+		runtime.HandleError(err)
+		return true
+	}
+
+	return true
+}
+
+func (c *Controller) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	glog.Infof("Synching pipeline %s/%s", namespace, name)
+
+	pipeline, err := c.pipelineLister.Pipelines(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			runtime.HandleError(fmt.Errorf("Pipeline '%s' in work queue no longer exists", key))
+			return nil
+		}
+
+		return err
+	}
+
+	newPipeline := pipeline.DeepCopy()
+	newPipeline.Status.ObservedGeneration = pipeline.Generation
+
+	steps, err := resolveSteps(pipeline)
+	if err != nil {
+		// The Pipeline can't be fixed without a new generation so the
+		// current Functions are kept as they are.
+		glog.Infof("Pipeline %s/%s is invalid: %s", namespace, name, err.Error())
+		newPipeline.Status.Error = err.Error()
+		return c.updatePipelineStatus(pipeline, newPipeline)
+	}
+
+	newPipeline.Status.Error = ""
+	newPipeline.Status.Steps = int32(len(steps))
+	newPipeline.Status.ReadySteps = 0
+	newPipeline.Status.StepStatuses = make([]kfnv1alpha1.PipelineStepStatus, 0, len(steps))
+
+	desired := make(map[string]bool)
+
+	for _, st := range steps {
+		desired[st.function] = true
+
+		function, err := c.syncFunction(pipeline, st)
+		if err != nil {
+			return err
+		}
+
+		ready := function.Status.ObservedGeneration == function.Generation &&
+			function.Status.AvailableReplicas == function.Spec.Replicas
+
+		if ready {
+			newPipeline.Status.ReadySteps++
+		}
+
+		newPipeline.Status.StepStatuses = append(newPipeline.Status.StepStatuses, kfnv1alpha1.PipelineStepStatus{
+			Name:              st.Name,
+			Function:          function.Name,
+			Input:             function.Spec.Input,
+			Output:            function.Spec.Output,
+			Replicas:          function.Spec.Replicas,
+			AvailableReplicas: function.Status.AvailableReplicas,
+			Ready:             ready,
+		})
+	}
+
+	if err := c.deleteRemovedFunctions(pipeline, desired); err != nil {
+		return err
+	}
+
+	return c.updatePipelineStatus(pipeline, newPipeline)
+}
+
+// syncFunction creates or updates the Function of the step.
+func (c *Controller) syncFunction(pipeline *kfnv1alpha1.Pipeline, st *step) (*kfnv1alpha1.Function, error) {
+	newFunction := newFunction(pipeline, st)
+
+	function, err := c.functionLister.Functions(pipeline.Namespace).Get(st.function)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Infof("Create Function %s/%s for pipeline %s", pipeline.Namespace, st.function, pipeline.Name)
+			return c.kfnClient.KfnV1alpha1().Functions(pipeline.Namespace).Create(newFunction)
+		}
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(function, pipeline) {
+		return nil, fmt.Errorf("Function '%s/%s' already exists and is not owned by the pipeline", function.Namespace, function.Name)
+	}
+
+	// The Function can be suspended, and scaled when its step does not set
+	// the replicas, without the Pipeline reverting it
+	newFunction.Spec.Suspend = function.Spec.Suspend
+	if st.Replicas == nil {
+		newFunction.Spec.Replicas = function.Spec.Replicas
+	}
+
+	if equality.Semantic.DeepEqual(function.Spec, newFunction.Spec) {
+		return function, nil
+	}
+
+	glog.Infof("Update Function %s/%s for pipeline %s", pipeline.Namespace, st.function, pipeline.Name)
+
+	function = function.DeepCopy()
+	function.Spec = newFunction.Spec
+
+	return c.kfnClient.KfnV1alpha1().Functions(pipeline.Namespace).Update(function)
+}
+
+// deleteRemovedFunctions deletes the Functions of the steps which have
+// been removed from the Pipeline.
+func (c *Controller) deleteRemovedFunctions(pipeline *kfnv1alpha1.Pipeline, desired map[string]bool) error {
+	functions, err := c.functionLister.Functions(pipeline.Namespace).List(labels.SelectorFromSet(labels.Set{
+		"pipeline": pipeline.Name,
+	}))
+	if err != nil {
+		return err
+	}
+
+	for _, function := range functions {
+		if desired[function.Name] || !metav1.IsControlledBy(function, pipeline) {
+			continue
+		}
+
+		glog.Infof("Delete Function %s/%s of pipeline %s", function.Namespace, function.Name, pipeline.Name)
+
+		err := c.kfnClient.KfnV1alpha1().Functions(function.Namespace).Delete(function.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) updatePipelineStatus(pipeline *kfnv1alpha1.Pipeline, newPipeline *kfnv1alpha1.Pipeline) error {
+	// Update the status only if it has changed
+	if equality.Semantic.DeepEqual(pipeline.Status, newPipeline.Status) {
+		return nil
+	}
+
+	_, err := c.kfnClient.KfnV1alpha1().Pipelines(pipeline.Namespace).UpdateStatus(newPipeline)
+
+	return err
+}
+
+func (c *Controller) enqueuePipeline(obj interface{}) {
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.workqueue.AddRateLimited(key)
+}
+
+func (c *Controller) handleObject(obj interface{}) {
+	var object metav1.Object
+	var ok bool
+	if object, ok = obj.(metav1.Object); !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		object, ok = tombstone.Obj.(metav1.Object)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
+		glog.V(4).Infof("Recovered deleted object '%s' from tombstone", object.GetName())
+	}
+
+	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil {
+		if ownerRef.Kind != "Pipeline" {
+			return
+		}
+
+		pipeline, err := c.pipelineLister.Pipelines(object.GetNamespace()).Get(ownerRef.Name)
+		if err != nil {
+			glog.V(4).Infof("ignoring orphaned object '%s' of Pipeline '%s'", object.GetSelfLink(), ownerRef.Name)
+			return
+		}
+
+		c.enqueuePipeline(pipeline)
+		return
+	}
+}
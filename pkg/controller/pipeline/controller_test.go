@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/client/clientset/versioned/fake"
+	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+)
+
+func newTestPipeline() *kfnv1alpha1.Pipeline {
+	return &kfnv1alpha1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "copies", UID: "pipeline-uid"},
+		Spec: kfnv1alpha1.PipelineSpec{
+			Input:                  "kfn.source",
+			InputKeyDeserializer:   "string",
+			InputValueDeserializer: "string",
+			Output:                 "kfn.destination",
+			OutputKeySerializer:    "string",
+			OutputValueSerializer:  "string",
+			Steps: []kfnv1alpha1.PipelineStep{
+				{Name: "first", Image: "dajac/kfn-examples:0.1.0", Class: "io.dajac.kfn.examples.Copy"},
+			},
+		},
+	}
+}
+
+// newTestController returns a controller whose cache and API server hold
+// the Functions.
+func newTestController(functions ...*kfnv1alpha1.Function) (*Controller, *fake.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	objects := []runtime.Object{}
+	for _, function := range functions {
+		indexer.Add(function)
+		objects = append(objects, function)
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+	return &Controller{kfnClient: client, functionLister: listers.NewFunctionLister(indexer)}, client
+}
+
+func TestSyncFunctionKeepsSuspendAndReplicas(t *testing.T) {
+	pipeline := newTestPipeline()
+	steps, err := resolveSteps(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := newFunction(pipeline, steps[0])
+	live.Spec.Suspend = true
+	live.Spec.Replicas = 3
+
+	c, client := newTestController(live)
+
+	function, err := c.syncFunction(pipeline, steps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("the suspended and scaled Function must not be updated, got %v", client.Actions())
+	}
+	if !function.Spec.Suspend || function.Spec.Replicas != 3 {
+		t.Errorf("suspend = %t, replicas = %d, want the live ones", function.Spec.Suspend, function.Spec.Replicas)
+	}
+
+	// A change of the step keeps the live suspend and replicas
+	pipeline.Spec.Steps[0].Image = "dajac/kfn-examples:0.2.0"
+	steps, _ = resolveSteps(pipeline)
+
+	function, err = c.syncFunction(pipeline, steps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if function.Spec.Image != "dajac/kfn-examples:0.2.0" {
+		t.Errorf("image = %s, want the image of the step", function.Spec.Image)
+	}
+	if !function.Spec.Suspend || function.Spec.Replicas != 3 {
+		t.Errorf("suspend = %t, replicas = %d, want the live ones", function.Spec.Suspend, function.Spec.Replicas)
+	}
+
+	// The replicas of the step win
+	replicas := int32(2)
+	pipeline.Spec.Steps[0].Replicas = &replicas
+	steps, _ = resolveSteps(pipeline)
+
+	function, err = c.syncFunction(pipeline, steps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if function.Spec.Replicas != 2 {
+		t.Errorf("replicas = %d, want the replicas of the step", function.Spec.Replicas)
+	}
+}
+
+func TestSyncFunctionNotOwned(t *testing.T) {
+	pipeline := newTestPipeline()
+	steps, _ := resolveSteps(pipeline)
+
+	existing := newFunction(pipeline, steps[0])
+	existing.OwnerReferences = nil
+
+	c, _ := newTestController(existing)
+
+	if _, err := c.syncFunction(pipeline, steps[0]); err == nil {
+		t.Errorf("a Function which is not owned by the pipeline must not be updated")
+	}
+}
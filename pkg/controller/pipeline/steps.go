@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// step is a PipelineStep whose input and output have been resolved.
+type step struct {
+	kfnv1alpha1.PipelineStep
+
+	function string
+	from     []string
+
+	input                  string
+	inputKeyDeserializer   string
+	inputValueDeserializer string
+
+	output                string
+	outputKeySerializer   string
+	outputValueSerializer string
+
+	// consumer is the first step consuming this step. All the steps
+	// consuming it must read the same topic.
+	consumer string
+}
+
+// resolveSteps validates the steps of the Pipeline and resolves the topics
+// and the serdes of each step.
+func resolveSteps(pipeline *kfnv1alpha1.Pipeline) ([]*step, error) {
+	spec := &pipeline.Spec
+
+	if len(spec.Steps) == 0 {
+		return nil, fmt.Errorf("the pipeline has no steps")
+	}
+
+	keyType := defaultString(spec.IntermediateKeyType, "bytes")
+	valueType := defaultString(spec.IntermediateValueType, "bytes")
+
+	for _, t := range []string{keyType, valueType} {
+		if !render.IsSerdeType(t) {
+			return nil, fmt.Errorf("intermediate type %q is not supported", t)
+		}
+	}
+
+	prefix := defaultString(spec.TopicPrefix, pipeline.Name)
+
+	steps := make([]*step, 0, len(spec.Steps))
+	byName := make(map[string]*step)
+
+	for i, s := range spec.Steps {
+		if s.Name == "" {
+			return nil, fmt.Errorf("step %d has no name", i)
+		}
+
+		if s.Name == kfnv1alpha1.PipelineInput {
+			return nil, fmt.Errorf("step name %q is reserved", s.Name)
+		}
+
+		if _, ok := byName[s.Name]; ok {
+			return nil, fmt.Errorf("step %q is defined twice", s.Name)
+		}
+
+		from := s.From
+		if len(from) == 0 {
+			if i == 0 {
+				from = []string{kfnv1alpha1.PipelineInput}
+			} else {
+				from = []string{spec.Steps[i-1].Name}
+			}
+		}
+
+		st := &step{
+			PipelineStep: s,
+			function:     pipeline.Name + "-" + s.Name,
+			from:         from,
+		}
+
+		steps = append(steps, st)
+		byName[s.Name] = st
+	}
+
+	for _, st := range steps {
+		if len(st.from) == 1 && st.from[0] == kfnv1alpha1.PipelineInput {
+			st.input = spec.Input
+			st.inputKeyDeserializer = spec.InputKeyDeserializer
+			st.inputValueDeserializer = spec.InputValueDeserializer
+			continue
+		}
+
+		// A single upstream step writes to its own topic while the
+		// upstream steps of a fan-in write to the topic of the fan-in
+		st.input = prefix + "." + st.Name
+		if len(st.from) == 1 {
+			st.input = prefix + "." + st.from[0]
+		}
+		st.inputKeyDeserializer = keyType
+		st.inputValueDeserializer = valueType
+
+		seen := make(map[string]bool)
+
+		for _, from := range st.from {
+			if from == kfnv1alpha1.PipelineInput {
+				return nil, fmt.Errorf("step %q can't merge the input of the pipeline with other steps", st.Name)
+			}
+
+			if seen[from] {
+				return nil, fmt.Errorf("step %q consumes step %q twice", st.Name, from)
+			}
+			seen[from] = true
+
+			upstream, ok := byName[from]
+			if !ok {
+				return nil, fmt.Errorf("step %q consumes unknown step %q", st.Name, from)
+			}
+
+			if upstream.output != "" && upstream.output != st.input {
+				return nil, fmt.Errorf("step %q can't be consumed by both %q and %q as it writes to a single topic", from, upstream.consumer, st.Name)
+			}
+
+			if upstream.output == "" {
+				upstream.output = st.input
+				upstream.consumer = st.Name
+			}
+		}
+	}
+
+	for _, st := range steps {
+		if st.consumer != "" {
+			if st.Output != "" {
+				return nil, fmt.Errorf("step %q is consumed by other steps so it can't define an output", st.Name)
+			}
+
+			st.outputKeySerializer = keyType
+			st.outputValueSerializer = valueType
+			continue
+		}
+
+		st.output = defaultString(st.Output, spec.Output)
+		st.outputKeySerializer = spec.OutputKeySerializer
+		st.outputValueSerializer = spec.OutputValueSerializer
+	}
+
+	// The steps must form a DAG rooted at the input of the pipeline
+	visited := make(map[string]bool)
+	for _, st := range steps {
+		if err := checkCycle(byName, st, visited, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+
+	return steps, nil
+}
+
+// checkCycle follows the upstream steps of the step depth first. The
+// visited steps are known not to be part of a cycle.
+func checkCycle(byName map[string]*step, st *step, visited map[string]bool, path map[string]bool) error {
+	if path[st.Name] {
+		return fmt.Errorf("step %q is part of a cycle", st.Name)
+	}
+
+	if visited[st.Name] {
+		return nil
+	}
+
+	path[st.Name] = true
+	for _, from := range st.from {
+		if from == kfnv1alpha1.PipelineInput {
+			continue
+		}
+		if err := checkCycle(byName, byName[from], visited, path); err != nil {
+			return err
+		}
+	}
+	delete(path, st.Name)
+	visited[st.Name] = true
+
+	return nil
+}
+
+// newFunction returns the Function of the step.
+func newFunction(pipeline *kfnv1alpha1.Pipeline, st *step) *kfnv1alpha1.Function {
+	s := st.PipelineStep.DeepCopy()
+
+	replicas := int32(1)
+	if s.Replicas != nil {
+		replicas = *s.Replicas
+	}
+
+	return &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      st.function,
+			Namespace: pipeline.Namespace,
+			Labels: map[string]string{
+				"pipeline": pipeline.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(pipeline, schema.GroupVersionKind{
+					Group:   kfnv1alpha1.SchemeGroupVersion.Group,
+					Version: kfnv1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Pipeline",
+				}),
+			},
+		},
+		Spec: kfnv1alpha1.FunctionSpec{
+			Image:                  s.Image,
+			Replicas:               replicas,
+			Class:                  s.Class,
+			Input:                  st.input,
+			InputKeyDeserializer:   st.inputKeyDeserializer,
+			InputValueDeserializer: st.inputValueDeserializer,
+			Output:                 st.output,
+			OutputKeySerializer:    st.outputKeySerializer,
+			OutoutValueSerializer:  st.outputValueSerializer,
+			FunctionConfig:         s.FunctionConfig,
+			ConsumerConfig:         s.ConsumerConfig,
+			ProducerConfig:         s.ProducerConfig,
+		},
+	}
+}
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestResolveSteps(t *testing.T) {
+	pipeline := newTestPipeline()
+	pipeline.Spec.IntermediateValueType = "avro"
+	pipeline.Spec.Steps = []kfnv1alpha1.PipelineStep{
+		{Name: "parse"},
+		{Name: "enrich"},
+		{Name: "audit", From: []string{"parse"}, Output: "kfn.audit"},
+		{Name: "raw", From: []string{kfnv1alpha1.PipelineInput}},
+	}
+
+	steps, err := resolveSteps(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		function string
+		input    string
+		output   string
+	}{
+		{"copies-parse", "kfn.source", "copies.parse"},
+		{"copies-enrich", "copies.parse", "kfn.destination"},
+		{"copies-audit", "copies.parse", "kfn.audit"},
+		{"copies-raw", "kfn.source", "kfn.destination"},
+	}
+
+	for i, e := range expected {
+		st := steps[i]
+		if st.function != e.function || st.input != e.input || st.output != e.output {
+			t.Errorf("step %s: function = %s, input = %s, output = %s, want %s, %s, %s", st.Name, st.function, st.input, st.output, e.function, e.input, e.output)
+		}
+	}
+
+	// The serdes of the intermediate topics match
+	if steps[0].outputKeySerializer != "bytes" || steps[0].outputValueSerializer != "avro" {
+		t.Errorf("parse serializers = %s, %s", steps[0].outputKeySerializer, steps[0].outputValueSerializer)
+	}
+	for _, st := range steps[1:3] {
+		if st.inputKeyDeserializer != "bytes" || st.inputValueDeserializer != "avro" {
+			t.Errorf("%s deserializers = %s, %s", st.Name, st.inputKeyDeserializer, st.inputValueDeserializer)
+		}
+		if st.outputKeySerializer != "string" || st.outputValueSerializer != "string" {
+			t.Errorf("%s serializers = %s, %s", st.Name, st.outputKeySerializer, st.outputValueSerializer)
+		}
+	}
+	if steps[3].inputKeyDeserializer != "string" {
+		t.Errorf("raw deserializer = %s, want the one of the pipeline", steps[3].inputKeyDeserializer)
+	}
+
+	pipeline.Spec.TopicPrefix = "kfn.copies"
+	steps, _ = resolveSteps(pipeline)
+	if steps[1].input != "kfn.copies.parse" {
+		t.Errorf("input = %s, want the topic prefix", steps[1].input)
+	}
+}
+
+func TestResolveStepsFanIn(t *testing.T) {
+	pipeline := newTestPipeline()
+	pipeline.Spec.Steps = []kfnv1alpha1.PipelineStep{
+		{Name: "parse"},
+		{Name: "orders", From: []string{"parse"}},
+		{Name: "payments", From: []string{"parse"}},
+		{Name: "join", From: []string{"orders", "payments"}},
+	}
+
+	steps, err := resolveSteps(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		input  string
+		output string
+	}{
+		{"kfn.source", "copies.parse"},
+		{"copies.parse", "copies.join"},
+		{"copies.parse", "copies.join"},
+		{"copies.join", "kfn.destination"},
+	}
+
+	for i, e := range expected {
+		st := steps[i]
+		if st.input != e.input || st.output != e.output {
+			t.Errorf("step %s: input = %s, output = %s, want %s, %s", st.Name, st.input, st.output, e.input, e.output)
+		}
+	}
+
+	if steps[1].outputValueSerializer != "bytes" || steps[3].inputValueDeserializer != "bytes" {
+		t.Errorf("the merged steps must use the intermediate types")
+	}
+}
+
+func TestResolveStepsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []kfnv1alpha1.PipelineStep
+		keyType string
+		err     string
+	}{
+		{name: "no steps", err: "has no steps"},
+		{name: "no name", steps: []kfnv1alpha1.PipelineStep{{}}, err: "step 0 has no name"},
+		{name: "reserved", steps: []kfnv1alpha1.PipelineStep{{Name: kfnv1alpha1.PipelineInput}}, err: "is reserved"},
+		{name: "twice", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}, {Name: "a"}}, err: `step "a" is defined twice`},
+		{name: "unknown", steps: []kfnv1alpha1.PipelineStep{{Name: "a", From: []string{"b"}}}, err: `consumes unknown step "b"`},
+		{name: "type", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}}, keyType: "uuid", err: `intermediate type "uuid"`},
+		{name: "output", steps: []kfnv1alpha1.PipelineStep{{Name: "a", Output: "kfn.a"}, {Name: "b"}}, err: `step "a" is consumed`},
+		{name: "cycle", steps: []kfnv1alpha1.PipelineStep{{Name: "a", From: []string{"b"}}, {Name: "b"}}, err: "part of a cycle"},
+		{name: "self", steps: []kfnv1alpha1.PipelineStep{{Name: "a", From: []string{"a"}}}, err: "part of a cycle"},
+		{name: "fan-in cycle", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}, {Name: "b", From: []string{"a", "c"}}, {Name: "c", From: []string{"b"}}}, err: "part of a cycle"},
+		{name: "merge input", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}, {Name: "b", From: []string{"a", kfnv1alpha1.PipelineInput}}}, err: "can't merge the input"},
+		{name: "merge twice", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}, {Name: "b", From: []string{"a", "a"}}}, err: `consumes step "a" twice`},
+		{name: "merged and consumed", steps: []kfnv1alpha1.PipelineStep{{Name: "a"}, {Name: "b", From: []string{kfnv1alpha1.PipelineInput}}, {Name: "c", From: []string{"a", "b"}}, {Name: "d", From: []string{"a"}}}, err: `step "a" can't be consumed by both "c" and "d"`},
+	}
+
+	for _, test := range tests {
+		pipeline := newTestPipeline()
+		pipeline.Spec.Steps = test.steps
+		pipeline.Spec.IntermediateKeyType = test.keyType
+
+		_, err := resolveSteps(pipeline)
+		switch {
+		case err == nil:
+			t.Errorf("%s: expected an error", test.name)
+		case !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q does not contain %q", test.name, err, test.err)
+		}
+	}
+}
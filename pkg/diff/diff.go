@@ -0,0 +1,367 @@
+// Package diff compares the resources rendered for a Function with the live
+// resources in the cluster.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/dajac/kfn/pkg/properties"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// Difference is a field which differs between the live and the desired object.
+type Difference struct {
+	Path    string      `json:"path"`
+	Live    interface{} `json:"live,omitempty"`
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Path, format(d.Live), format(d.Desired))
+}
+
+// Report describes what would change if the desired resources were applied.
+type Report struct {
+	Function string `json:"function"`
+
+	CreateConfigMap   bool `json:"createConfigMap,omitempty"`
+	CreateDeployement bool `json:"createDeployement,omitempty"`
+	CreateStatefulSet bool `json:"createStatefulSet,omitempty"`
+
+	// DeleteDeployement and DeleteStatefulSet are true when the workload
+	// type of the Function changes.
+	DeleteDeployement bool `json:"deleteDeployement,omitempty"`
+	DeleteStatefulSet bool `json:"deleteStatefulSet,omitempty"`
+
+	// Properties are the differences in the rendered function.properties.
+	Properties  []Difference `json:"properties,omitempty"`
+	ConfigMap   []Difference `json:"configMap,omitempty"`
+	Deployement []Difference `json:"deployement,omitempty"`
+	StatefulSet []Difference `json:"statefulSet,omitempty"`
+
+	LiveHash          string `json:"liveHash,omitempty"`
+	DesiredHash       string `json:"desiredHash"`
+	ConfigHashChanged bool   `json:"configHashChanged"`
+
+	// RollingRestart is true when the pod template changes.
+	RollingRestart bool `json:"rollingRestart"`
+
+	// ConfigError is set instead of the differences when the
+	// configuration of the Function is invalid.
+	ConfigError string `json:"configError,omitempty"`
+}
+
+// Empty returns true if applying the desired resources would not change anything.
+func (r *Report) Empty() bool {
+	return !r.CreateConfigMap && !r.CreateDeployement && !r.CreateStatefulSet &&
+		!r.DeleteDeployement && !r.DeleteStatefulSet &&
+		len(r.Properties) == 0 && len(r.ConfigMap) == 0 && len(r.Deployement) == 0 && len(r.StatefulSet) == 0
+}
+
+// Lines returns a human readable summary of the report, one change per line.
+func (r *Report) Lines() []string {
+	lines := []string{}
+
+	if r.CreateConfigMap {
+		lines = append(lines, "ConfigMap will be created")
+	}
+	if r.CreateDeployement {
+		lines = append(lines, "Deployment will be created")
+	}
+	if r.CreateStatefulSet {
+		lines = append(lines, "StatefulSet will be created")
+	}
+	if r.DeleteDeployement {
+		lines = append(lines, "Deployment will be deleted")
+	}
+	if r.DeleteStatefulSet {
+		lines = append(lines, "StatefulSet will be deleted")
+	}
+	for _, d := range r.Properties {
+		lines = append(lines, "properties: "+d.String())
+	}
+	for _, d := range r.ConfigMap {
+		lines = append(lines, "configmap: "+d.String())
+	}
+	for _, d := range r.Deployement {
+		lines = append(lines, "deployment: "+d.String())
+	}
+	for _, d := range r.StatefulSet {
+		lines = append(lines, "statefulset: "+d.String())
+	}
+
+	return lines
+}
+
+// Compute compares the desired resources with the live ones. A nil live
+// resource means that it does not exist yet.
+func Compute(desired *render.Resources, liveConfigMap *corev1.ConfigMap, liveDeployement *appsv1.Deployment, liveStatefulSet *appsv1.StatefulSet) (*Report, error) {
+	report := &Report{
+		Function:    desired.ConfigMap.Namespace + "/" + desired.ConfigMap.Name,
+		DesiredHash: render.Hash(desired.ConfigMap),
+	}
+
+	if liveConfigMap == nil {
+		report.CreateConfigMap = true
+		report.ConfigHashChanged = true
+	} else {
+		report.LiveHash = render.Hash(liveConfigMap)
+		report.ConfigHashChanged = report.LiveHash != report.DesiredHash
+		props, err := Properties(liveConfigMap.Data[render.PropertiesKey], desired.ConfigMap.Data[render.PropertiesKey])
+		if err != nil {
+			return nil, err
+		}
+		report.Properties = props
+
+		differences, err := Objects(liveConfigMap, desired.ConfigMap)
+		if err != nil {
+			return nil, err
+		}
+
+		// Changes of the properties are already reported above
+		for _, d := range differences {
+			if d.Path != "data."+render.PropertiesKey {
+				report.ConfigMap = append(report.ConfigMap, d)
+			}
+		}
+	}
+
+	if desired.StatefulSet != nil {
+		report.DeleteDeployement = liveDeployement != nil
+
+		if liveStatefulSet == nil {
+			report.CreateStatefulSet = true
+			report.RollingRestart = true
+		} else {
+			differences, err := Objects(liveStatefulSet, desired.StatefulSet)
+			if err != nil {
+				return nil, err
+			}
+
+			report.StatefulSet = differences
+			report.RollingRestart = templateChanged(differences)
+		}
+
+		return report, nil
+	}
+
+	report.DeleteStatefulSet = liveStatefulSet != nil
+
+	if liveDeployement == nil {
+		report.CreateDeployement = true
+		report.RollingRestart = true
+	} else {
+		differences, err := Objects(liveDeployement, desired.Deployement)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Deployement = differences
+		report.RollingRestart = templateChanged(differences)
+	}
+
+	return report, nil
+}
+
+// Properties compares two properties files key by key.
+func Properties(live string, desired string) ([]Difference, error) {
+	liveProps, err := properties.Parse(live)
+	if err != nil {
+		return nil, fmt.Errorf("live properties: %v", err)
+	}
+
+	desiredProps, err := properties.Parse(desired)
+	if err != nil {
+		return nil, fmt.Errorf("desired properties: %v", err)
+	}
+
+	differences := []Difference{}
+
+	for key, value := range desiredProps {
+		if liveValue, ok := liveProps[key]; !ok || liveValue != value {
+			differences = append(differences, Difference{
+				Path:    key,
+				Live:    optional(liveValue, ok),
+				Desired: value,
+			})
+		}
+	}
+
+	for key, value := range liveProps {
+		if _, ok := desiredProps[key]; !ok {
+			differences = append(differences, Difference{
+				Path: key,
+				Live: value,
+			})
+		}
+	}
+
+	sortDifferences(differences)
+
+	return differences, nil
+}
+
+// Objects compares the fields set in the desired object with the live
+// object. Fields which are only present in the live object are ignored as
+// they are usually defaulted by the API server, except the entries of
+// labels, annotations, data and selectors.
+func Objects(live interface{}, desired interface{}) ([]Difference, error) {
+	liveValue, err := toUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredValue, err := toUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := []Difference{}
+	compare("", liveValue, desiredValue, &differences)
+	sortDifferences(differences)
+
+	return differences, nil
+}
+
+// ignoredPaths are set by the API server or by the controllers.
+var ignoredPaths = map[string]bool{
+	"status":                     true,
+	"metadata.creationTimestamp": true,
+	"metadata.resourceVersion":   true,
+	"metadata.uid":               true,
+	"metadata.selfLink":          true,
+	"metadata.generation":        true,
+}
+
+// strictMaps are the maps whose extra live entries are reported.
+var strictMaps = map[string]bool{
+	"labels":      true,
+	"annotations": true,
+	"data":        true,
+	"matchLabels": true,
+}
+
+func compare(path string, live interface{}, desired interface{}, differences *[]Difference) {
+	if ignoredPaths[path] || isEmpty(desired) && isEmpty(live) {
+		return
+	}
+
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			*differences = append(*differences, Difference{Path: path, Live: live, Desired: desired})
+			return
+		}
+
+		for key, value := range desiredValue {
+			if isEmpty(value) {
+				continue
+			}
+			compare(join(path, key), liveValue[key], value, differences)
+		}
+
+		if strictMaps[lastElement(path)] {
+			for key, value := range liveValue {
+				if _, ok := desiredValue[key]; !ok {
+					*differences = append(*differences, Difference{Path: join(path, key), Live: value})
+				}
+			}
+		}
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(desiredValue) {
+			*differences = append(*differences, Difference{Path: path, Live: live, Desired: desired})
+			return
+		}
+
+		for i := range desiredValue {
+			compare(fmt.Sprintf("%s[%d]", path, i), liveValue[i], desiredValue[i], differences)
+		}
+	default:
+		if desired != nil && fmt.Sprint(desired) != fmt.Sprint(live) {
+			*differences = append(*differences, Difference{Path: path, Live: live, Desired: desired})
+		}
+	}
+}
+
+func templateChanged(differences []Difference) bool {
+	for _, d := range differences {
+		if strings.HasPrefix(d.Path, "spec.template.") {
+			return true
+		}
+	}
+	return false
+}
+
+func toUnstructured(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func optional(value string, ok bool) interface{} {
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+func join(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func lastElement(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+func sortDifferences(differences []Difference) {
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestProperties(t *testing.T) {
+	live := "a=1\nb=2\nc=3\n"
+	desired := "a=1\nb=20\nd=4\n"
+
+	differences, err := Properties(live, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Difference{
+		{Path: "b", Live: "2", Desired: "20"},
+		{Path: "c", Live: "3"},
+		{Path: "d", Desired: "4"},
+	}
+	if !reflect.DeepEqual(differences, expected) {
+		t.Errorf("differences = %v, want %v", differences, expected)
+	}
+
+	if _, err := Properties(`a=\u12`, desired); err == nil {
+		t.Errorf("invalid live properties must be rejected")
+	}
+}
+
+func TestObjects(t *testing.T) {
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "copy",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"function": "copy", "extra": "true"},
+		},
+		Data: map[string]string{"a": "1"},
+	}
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "copy",
+			Labels: map[string]string{"function": "copy"},
+		},
+		Data: map[string]string{"a": "2"},
+	}
+
+	differences, err := Objects(live, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Difference{
+		{Path: "data.a", Live: "1", Desired: "2"},
+		{Path: "metadata.labels.extra", Live: "true"},
+	}
+	if !reflect.DeepEqual(differences, expected) {
+		t.Errorf("differences = %v, want %v", differences, expected)
+	}
+}
+
+func newDiffFunction() *kfnv1alpha1.Function {
+	return &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "copy", UID: "copy-uid"},
+		Spec: kfnv1alpha1.FunctionSpec{
+			Image:    "dajac/kfn-examples:0.1.0",
+			Replicas: 1,
+			Class:    "io.dajac.kfn.examples.Copy",
+			Input:    "kfn.source",
+			Output:   "kfn.destination",
+		},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	defaultConfig := &render.FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}
+	function := newDiffFunction()
+	live := render.Render(defaultConfig, function)
+
+	report, err := Compute(live, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.CreateConfigMap || !report.CreateDeployement || !report.RollingRestart {
+		t.Errorf("the resources must be created, got %+v", report)
+	}
+
+	report, err = Compute(live, live.ConfigMap, live.Deployement, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Empty() || report.RollingRestart || report.ConfigHashChanged {
+		t.Errorf("the report must be empty, got %v", report.Lines())
+	}
+
+	// Scaling does not restart the pods
+	scaled := function.DeepCopy()
+	scaled.Spec.Replicas = 3
+	report, _ = Compute(render.Render(defaultConfig, scaled), live.ConfigMap, live.Deployement, nil)
+	if len(report.Deployement) != 1 || report.Deployement[0].Path != "spec.replicas" || report.RollingRestart {
+		t.Errorf("only the replicas must change, got %v", report.Lines())
+	}
+
+	// A new property rolls the pods
+	updated := function.DeepCopy()
+	updated.Spec.Output = "kfn.other"
+	report, _ = Compute(render.Render(defaultConfig, updated), live.ConfigMap, live.Deployement, nil)
+	expected := []Difference{{Path: "function.output", Live: "kfn.destination", Desired: "kfn.other"}}
+	if !reflect.DeepEqual(report.Properties, expected) || len(report.ConfigMap) != 0 {
+		t.Errorf("properties = %v, configmap = %v, want %v", report.Properties, report.ConfigMap, expected)
+	}
+	if !report.ConfigHashChanged || !report.RollingRestart {
+		t.Errorf("the new properties must roll the pods, got %+v", report)
+	}
+
+	// A new workload type replaces the Deployment
+	stateful := function.DeepCopy()
+	stateful.Spec.WorkloadType = kfnv1alpha1.StatefulSetWorkload
+	report, _ = Compute(render.Render(defaultConfig, stateful), live.ConfigMap, live.Deployement, nil)
+	if !report.CreateStatefulSet || !report.DeleteDeployement {
+		t.Errorf("the Deployment must be replaced by a StatefulSet, got %v", report.Lines())
+	}
+}
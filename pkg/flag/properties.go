@@ -0,0 +1,28 @@
+package flag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Properties is a repeatable flag collecting key=value pairs. Unlike Config,
+// only the first '=' separates the key from the value so values may contain
+// any character (URLs, paths, etc.).
+type Properties map[string]string
+
+func (p *Properties) String() string {
+	return fmt.Sprint(*p)
+}
+
+func (p *Properties) Set(value string) error {
+	res := strings.SplitN(value, "=", 2)
+
+	if len(res) != 2 || res[0] == "" {
+		return errors.New("keyvalue flag must be key=value")
+	}
+
+	(*p)[res[0]] = res[1]
+
+	return nil
+}
@@ -0,0 +1,313 @@
+// Package kafka measures the lag of consumer groups. It only implements the
+// few requests of the Kafka protocol it needs so that the operator does not
+// depend on a Kafka client, and it only supports the PLAINTEXT listeners.
+package kafka
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LagChecker measures the lag of the consumer groups by comparing their
+// committed offsets with the end offsets of the partitions.
+type LagChecker struct {
+	timeout time.Duration
+}
+
+// NewLagChecker returns a LagChecker whose requests time out after the
+// timeout.
+func NewLagChecker(timeout time.Duration) *LagChecker {
+	return &LagChecker{timeout: timeout}
+}
+
+type partition struct {
+	topic     string
+	partition int32
+}
+
+func (p partition) String() string {
+	return fmt.Sprintf("%s-%d", p.topic, p.partition)
+}
+
+// Lag returns the number of messages of the topics which have not been
+// consumed by the group yet. bootstrap is a comma-separated list of brokers,
+// like bootstrap.servers. The partitions without committed offset are
+// ignored: the group has not consumed them yet.
+func (l *LagChecker) Lag(bootstrap string, group string, topics []string) (int64, error) {
+	bootstrapConn, err := l.dialBootstrap(bootstrap)
+	if err != nil {
+		return 0, err
+	}
+	defer bootstrapConn.Close()
+
+	brokers, leaders, err := metadata(bootstrapConn, topics)
+	if err != nil {
+		return 0, err
+	}
+
+	coordinator, err := groupCoordinator(bootstrapConn, group)
+	if err != nil {
+		return 0, err
+	}
+
+	partitions := make([]partition, 0, len(leaders))
+	for p := range leaders {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].topic != partitions[j].topic {
+			return partitions[i].topic < partitions[j].topic
+		}
+		return partitions[i].partition < partitions[j].partition
+	})
+
+	coordinatorConn, err := dial(coordinator, l.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer coordinatorConn.Close()
+
+	committed, err := offsetFetch(coordinatorConn, group, partitions)
+	if err != nil {
+		return 0, err
+	}
+
+	byLeader := make(map[int32][]partition)
+	for _, p := range partitions {
+		if _, ok := committed[p]; ok {
+			byLeader[leaders[p]] = append(byLeader[leaders[p]], p)
+		}
+	}
+
+	var lag int64
+	for leader, leaderPartitions := range byLeader {
+		address, ok := brokers[leader]
+		if !ok {
+			return 0, fmt.Errorf("unknown leader %d", leader)
+		}
+
+		leaderConn, err := dial(address, l.timeout)
+		if err != nil {
+			return 0, err
+		}
+
+		ends, err := listOffsets(leaderConn, leaderPartitions)
+		leaderConn.Close()
+		if err != nil {
+			return 0, err
+		}
+
+		for _, p := range leaderPartitions {
+			if behind := ends[p] - committed[p]; behind > 0 {
+				lag += behind
+			}
+		}
+	}
+
+	return lag, nil
+}
+
+// dialBootstrap connects to the first reachable broker of the list.
+func (l *LagChecker) dialBootstrap(bootstrap string) (*conn, error) {
+	var errors []string
+
+	for _, address := range strings.Split(bootstrap, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+
+		c, err := dial(address, l.timeout)
+		if err == nil {
+			return c, nil
+		}
+		errors = append(errors, err.Error())
+	}
+
+	if len(errors) == 0 {
+		return nil, fmt.Errorf("no bootstrap server")
+	}
+
+	return nil, fmt.Errorf("no bootstrap server is reachable: %s", strings.Join(errors, "; "))
+}
+
+// metadata returns the addresses of the brokers and the leaders of the
+// partitions of the topics.
+func metadata(c *conn, topics []string) (map[int32]string, map[partition]int32, error) {
+	e := &encoder{}
+	e.arrayLength(len(topics))
+	for _, topic := range topics {
+		e.string(topic)
+	}
+
+	d, err := c.roundTrip(metadataKey, metadataVersion, e.buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	brokers := make(map[int32]string)
+	for i, n := 0, d.arrayLength(); i < n; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+
+	leaders := make(map[partition]int32)
+	for i, n := 0, d.arrayLength(); i < n; i++ {
+		topicError := d.int16()
+		topic := d.string()
+		if topicError != 0 && d.err == nil {
+			return nil, nil, fmt.Errorf("topic %s: error code %d", topic, topicError)
+		}
+
+		for j, m := 0, d.arrayLength(); j < m; j++ {
+			partitionError := d.int16()
+			p := partition{topic: topic, partition: d.int32()}
+			leader := d.int32()
+			for k, r := 0, d.arrayLength(); k < r; k++ {
+				d.int32()
+			}
+			for k, r := 0, d.arrayLength(); k < r; k++ {
+				d.int32()
+			}
+
+			// A partition without leader has no end offset
+			if leader < 0 && d.err == nil {
+				return nil, nil, fmt.Errorf("partition %s: no leader, error code %d", p, partitionError)
+			}
+			leaders[p] = leader
+		}
+	}
+
+	if d.err != nil {
+		return nil, nil, fmt.Errorf("metadata: %s", d.err.Error())
+	}
+
+	return brokers, leaders, nil
+}
+
+// groupCoordinator returns the address of the coordinator of the group.
+func groupCoordinator(c *conn, group string) (string, error) {
+	e := &encoder{}
+	e.string(group)
+
+	d, err := c.roundTrip(groupCoordinatorKey, groupCoordinatorVersion, e.buf)
+	if err != nil {
+		return "", err
+	}
+
+	errorCode := d.int16()
+	d.int32()
+	host := d.string()
+	port := d.int32()
+
+	if d.err != nil {
+		return "", fmt.Errorf("group coordinator: %s", d.err.Error())
+	}
+	if errorCode != 0 {
+		return "", fmt.Errorf("coordinator of group %s: error code %d", group, errorCode)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// offsetFetch returns the offsets committed by the group. The partitions
+// without committed offset are left out.
+func offsetFetch(c *conn, group string, partitions []partition) (map[partition]int64, error) {
+	e := &encoder{}
+	e.string(group)
+	encodeByTopic(e, partitions, func(partition) {})
+
+	d, err := c.roundTrip(offsetFetchKey, offsetFetchVersion, e.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make(map[partition]int64)
+	for i, n := 0, d.arrayLength(); i < n; i++ {
+		topic := d.string()
+		for j, m := 0, d.arrayLength(); j < m; j++ {
+			p := partition{topic: topic, partition: d.int32()}
+			offset := d.int64()
+			d.string()
+			errorCode := d.int16()
+
+			if errorCode != 0 && d.err == nil {
+				return nil, fmt.Errorf("committed offset of %s: error code %d", p, errorCode)
+			}
+			if offset >= 0 {
+				committed[p] = offset
+			}
+		}
+	}
+
+	if d.err != nil {
+		return nil, fmt.Errorf("offset fetch: %s", d.err.Error())
+	}
+
+	return committed, nil
+}
+
+// listOffsets returns the end offsets of the partitions led by the broker.
+func listOffsets(c *conn, partitions []partition) (map[partition]int64, error) {
+	e := &encoder{}
+	e.int32(-1)
+	encodeByTopic(e, partitions, func(partition) {
+		// The latest offset
+		e.int64(-1)
+	})
+
+	d, err := c.roundTrip(listOffsetsKey, listOffsetsVersion, e.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	ends := make(map[partition]int64)
+	for i, n := 0, d.arrayLength(); i < n; i++ {
+		topic := d.string()
+		for j, m := 0, d.arrayLength(); j < m; j++ {
+			p := partition{topic: topic, partition: d.int32()}
+			errorCode := d.int16()
+			d.int64()
+			offset := d.int64()
+
+			if errorCode != 0 && d.err == nil {
+				return nil, fmt.Errorf("end offset of %s: error code %d", p, errorCode)
+			}
+			ends[p] = offset
+		}
+	}
+
+	if d.err != nil {
+		return nil, fmt.Errorf("list offsets: %s", d.err.Error())
+	}
+
+	return ends, nil
+}
+
+// encodeByTopic writes the partitions grouped by topic. They must be sorted
+// by topic.
+func encodeByTopic(e *encoder, partitions []partition, fields func(partition)) {
+	topics := [][]partition{}
+	for i, p := range partitions {
+		if i == 0 || p.topic != partitions[i-1].topic {
+			topics = append(topics, nil)
+		}
+		topics[len(topics)-1] = append(topics[len(topics)-1], p)
+	}
+
+	e.arrayLength(len(topics))
+	for _, topicPartitions := range topics {
+		e.string(topicPartitions[0].topic)
+		e.arrayLength(len(topicPartitions))
+		for _, p := range topicPartitions {
+			e.int32(p.partition)
+			fields(p)
+		}
+	}
+}
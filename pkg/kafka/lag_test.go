@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBroker is a single broker cluster answering the requests of the
+// LagChecker.
+type fakeBroker struct {
+	t        *testing.T
+	listener net.Listener
+
+	partitions int32
+	topicError int16
+	committed  map[int32]int64
+	ends       map[int32]int64
+}
+
+// start listens on a random port. The broker must not be changed once
+// started.
+func (b *fakeBroker) start(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.t = t
+	b.listener = listener
+	go b.serve()
+}
+
+func (b *fakeBroker) address() string {
+	return b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		c, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(c)
+	}
+}
+
+func (b *fakeBroker) handle(c net.Conn) {
+	defer c.Close()
+
+	host, portString, _ := net.SplitHostPort(b.address())
+	port, _ := strconv.Atoi(portString)
+
+	for {
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(c, size); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(c, request); err != nil {
+			return
+		}
+
+		d := &decoder{buf: request}
+		key := d.int16()
+		d.int16()
+		correlationID := d.int32()
+		d.string()
+
+		e := &encoder{}
+		e.int32(0)
+		e.int32(correlationID)
+
+		switch key {
+		case metadataKey:
+			e.arrayLength(1)
+			e.int32(1)
+			e.string(host)
+			e.int32(int32(port))
+
+			e.arrayLength(d.arrayLength())
+			topic := d.string()
+			e.int16(b.topicError)
+			e.string(topic)
+			e.arrayLength(int(b.partitions))
+			for p := int32(0); p < b.partitions; p++ {
+				e.int16(0)
+				e.int32(p)
+				e.int32(1)
+				e.arrayLength(0)
+				e.arrayLength(0)
+			}
+		case groupCoordinatorKey:
+			e.int16(0)
+			e.int32(1)
+			e.string(host)
+			e.int32(int32(port))
+		case offsetFetchKey:
+			d.string()
+			e.arrayLength(d.arrayLength())
+			e.string(d.string())
+			n := d.arrayLength()
+			e.arrayLength(n)
+			for i := 0; i < n; i++ {
+				p := d.int32()
+				offset, ok := b.committed[p]
+				if !ok {
+					offset = -1
+				}
+				e.int32(p)
+				e.int64(offset)
+				e.string("")
+				e.int16(0)
+			}
+		case listOffsetsKey:
+			d.int32()
+			e.arrayLength(d.arrayLength())
+			e.string(d.string())
+			n := d.arrayLength()
+			e.arrayLength(n)
+			for i := 0; i < n; i++ {
+				p := d.int32()
+				if timestamp := d.int64(); timestamp != -1 {
+					b.t.Errorf("timestamp = %d, want the latest offset", timestamp)
+				}
+				e.int32(p)
+				e.int16(0)
+				e.int64(-1)
+				e.int64(b.ends[p])
+			}
+		default:
+			b.t.Errorf("unexpected request %d", key)
+			return
+		}
+
+		binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+		if _, err := c.Write(e.buf); err != nil {
+			return
+		}
+	}
+}
+
+func TestLag(t *testing.T) {
+	broker := &fakeBroker{
+		partitions: 3,
+		committed:  map[int32]int64{0: 10, 1: 20},
+		ends:       map[int32]int64{0: 15, 1: 20, 2: 100},
+	}
+	broker.start(t)
+	defer broker.listener.Close()
+
+	checker := NewLagChecker(time.Second)
+
+	// The partition without committed offset is ignored
+	lag, err := checker.Lag("127.0.0.1:1,"+broker.address(), "copy", []string{"copy.input"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag != 5 {
+		t.Errorf("lag = %d, want 5", lag)
+	}
+
+	unknown := &fakeBroker{topicError: 3}
+	unknown.start(t)
+	defer unknown.listener.Close()
+
+	if _, err := checker.Lag(unknown.address(), "copy", []string{"copy.input"}); err == nil || !strings.Contains(err.Error(), "error code 3") {
+		t.Errorf("an unknown topic must be reported, got %v", err)
+	}
+
+	if _, err := checker.Lag("127.0.0.1:1", "copy", []string{"copy.input"}); err == nil || !strings.Contains(err.Error(), "no bootstrap server is reachable") {
+		t.Errorf("an unreachable cluster must be reported, got %v", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := &decoder{buf: []byte{0, 5, 'a'}}
+	if s := d.string(); s != "" || d.err == nil {
+		t.Errorf("a truncated string must be rejected, got %q", s)
+	}
+
+	d = &decoder{buf: []byte{0xff, 0xff}}
+	if s := d.string(); s != "" || d.err != nil {
+		t.Errorf("a null string must be read as an empty one, got %q, %v", s, d.err)
+	}
+
+	d = &decoder{buf: []byte{0, 0, 1, 0}}
+	if n := d.arrayLength(); n != 0 || d.err == nil {
+		t.Errorf("an array longer than the response must be rejected, got %d", n)
+	}
+}
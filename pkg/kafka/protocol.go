@@ -0,0 +1,186 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// The requests of the Kafka protocol used to measure the lag. The oldest
+// versions returning the needed fields are used so that any broker since
+// Kafka 0.10.1 answers them.
+const (
+	listOffsetsKey      int16 = 2
+	metadataKey         int16 = 3
+	offsetFetchKey      int16 = 9
+	groupCoordinatorKey int16 = 10
+
+	listOffsetsVersion      int16 = 1
+	metadataVersion         int16 = 0
+	offsetFetchVersion      int16 = 1
+	groupCoordinatorVersion int16 = 0
+
+	clientID = "kfn-operator"
+
+	// maxResponseSize protects the operator from a peer which is not a
+	// Kafka broker.
+	maxResponseSize = 64 * 1024 * 1024
+)
+
+// encoder writes the primitive types of the Kafka protocol.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = append(e.buf, byte(v>>8), byte(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.buf = append(e.buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.int32(int32(v >> 32))
+	e.int32(int32(v))
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) arrayLength(n int) {
+	e.int32(int32(n))
+}
+
+// decoder reads the primitive types of the Kafka protocol. The first error
+// is kept and the following reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = fmt.Errorf("truncated response")
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int16() int16 {
+	b := d.read(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.read(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.read(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+// string reads a string. A null string is read as an empty one.
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.read(int(n)))
+}
+
+func (d *decoder) arrayLength() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) {
+		d.err = fmt.Errorf("truncated response")
+		return 0
+	}
+	return int(n)
+}
+
+// conn is a connection to a broker. The requests are sent one at a time.
+type conn struct {
+	conn          net.Conn
+	address       string
+	timeout       time.Duration
+	correlationID int32
+}
+
+func dial(address string, timeout time.Duration) (*conn, error) {
+	c, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{conn: c, address: address, timeout: timeout}, nil
+}
+
+func (c *conn) Close() error {
+	return c.conn.Close()
+}
+
+// roundTrip sends the request and returns a decoder of the body of its
+// response.
+func (c *conn) roundTrip(key int16, version int16, body []byte) (*decoder, error) {
+	c.correlationID++
+
+	e := &encoder{}
+	e.int32(0)
+	e.int16(key)
+	e.int16(version)
+	e.int32(c.correlationID)
+	e.string(clientID)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(e.buf); err != nil {
+		return nil, fmt.Errorf("%s: %s", c.address, err.Error())
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, fmt.Errorf("%s: %s", c.address, err.Error())
+	}
+
+	size := int32(binary.BigEndian.Uint32(header))
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("%s: invalid response size %d", c.address, size)
+	}
+
+	if correlationID := int32(binary.BigEndian.Uint32(header[4:])); correlationID != c.correlationID {
+		return nil, fmt.Errorf("%s: unexpected correlation id %d", c.address, correlationID)
+	}
+
+	response := make([]byte, size-4)
+	if _, err := io.ReadFull(c.conn, response); err != nil {
+		return nil, fmt.Errorf("%s: %s", c.address, err.Error())
+	}
+
+	return &decoder{buf: response}, nil
+}
@@ -0,0 +1,186 @@
+// Package kafkaconfig validates the properties of the Kafka clients against
+// a catalog of the known configuration keys, with their types and their
+// allowed values.
+package kafkaconfig
+
+// Type is the type of the value of a configuration key.
+type Type int
+
+const (
+	String Type = iota
+	Boolean
+	Int
+	Long
+	Short
+	Double
+	List
+	Class
+	Password
+)
+
+// Key describes a configuration key.
+type Key struct {
+	Type Type
+
+	// Values are the allowed values, if any.
+	Values []string
+}
+
+// Client is the client a configuration applies to.
+type Client string
+
+const (
+	Consumer Client = "consumer"
+	Producer Client = "producer"
+)
+
+var commonKeys = map[string]Key{
+	"bootstrap.servers":                      {Type: List},
+	"client.dns.lookup":                      {Type: String, Values: []string{"default", "use_all_dns_ips", "resolve_canonical_bootstrap_servers_only"}},
+	"client.id":                              {Type: String},
+	"client.rack":                            {Type: String},
+	"connections.max.idle.ms":                {Type: Long},
+	"interceptor.classes":                    {Type: List},
+	"metadata.max.age.ms":                    {Type: Long},
+	"metric.reporters":                       {Type: List},
+	"metrics.num.samples":                    {Type: Int},
+	"metrics.recording.level":                {Type: String, Values: []string{"INFO", "DEBUG", "TRACE"}},
+	"metrics.sample.window.ms":               {Type: Long},
+	"receive.buffer.bytes":                   {Type: Int},
+	"reconnect.backoff.max.ms":               {Type: Long},
+	"reconnect.backoff.ms":                   {Type: Long},
+	"request.timeout.ms":                     {Type: Int},
+	"retry.backoff.ms":                       {Type: Long},
+	"security.protocol":                      {Type: String, Values: []string{"PLAINTEXT", "SSL", "SASL_PLAINTEXT", "SASL_SSL"}},
+	"security.providers":                     {Type: String},
+	"send.buffer.bytes":                      {Type: Int},
+	"socket.connection.setup.timeout.ms":     {Type: Long},
+	"socket.connection.setup.timeout.max.ms": {Type: Long},
+
+	"sasl.client.callback.handler.class":       {Type: Class},
+	"sasl.jaas.config":                         {Type: Password},
+	"sasl.kerberos.kinit.cmd":                  {Type: String},
+	"sasl.kerberos.min.time.before.relogin":    {Type: Long},
+	"sasl.kerberos.service.name":               {Type: String},
+	"sasl.kerberos.ticket.renew.jitter":        {Type: Double},
+	"sasl.kerberos.ticket.renew.window.factor": {Type: Double},
+	"sasl.login.callback.handler.class":        {Type: Class},
+	"sasl.login.class":                         {Type: Class},
+	"sasl.login.refresh.buffer.seconds":        {Type: Short},
+	"sasl.login.refresh.min.period.seconds":    {Type: Short},
+	"sasl.login.refresh.window.factor":         {Type: Double},
+	"sasl.login.refresh.window.jitter":         {Type: Double},
+	"sasl.mechanism":                           {Type: String},
+
+	"ssl.cipher.suites":                     {Type: List},
+	"ssl.enabled.protocols":                 {Type: List},
+	"ssl.endpoint.identification.algorithm": {Type: String},
+	"ssl.engine.factory.class":              {Type: Class},
+	"ssl.key.password":                      {Type: Password},
+	"ssl.keymanager.algorithm":              {Type: String},
+	"ssl.keystore.certificate.chain":        {Type: Password},
+	"ssl.keystore.key":                      {Type: Password},
+	"ssl.keystore.location":                 {Type: String},
+	"ssl.keystore.password":                 {Type: Password},
+	"ssl.keystore.type":                     {Type: String},
+	"ssl.protocol":                          {Type: String},
+	"ssl.provider":                          {Type: String},
+	"ssl.secure.random.implementation":      {Type: String},
+	"ssl.trustmanager.algorithm":            {Type: String},
+	"ssl.truststore.certificates":           {Type: Password},
+	"ssl.truststore.location":               {Type: String},
+	"ssl.truststore.password":               {Type: Password},
+	"ssl.truststore.type":                   {Type: String},
+
+	// Confluent serializers and deserializers
+	"schema.registry.url":                  {Type: List},
+	"basic.auth.credentials.source":        {Type: String, Values: []string{"URL", "USER_INFO", "SASL_INHERIT"}},
+	"basic.auth.user.info":                 {Type: Password},
+	"schema.registry.basic.auth.user.info": {Type: Password},
+	"key.subject.name.strategy":            {Type: Class},
+	"value.subject.name.strategy":          {Type: Class},
+	"auto.register.schemas":                {Type: Boolean},
+	"use.latest.version":                   {Type: Boolean},
+	"max.schemas.per.subject":              {Type: Int},
+}
+
+var consumerKeys = map[string]Key{
+	"allow.auto.create.topics":      {Type: Boolean},
+	"auto.commit.interval.ms":       {Type: Int},
+	"auto.offset.reset":             {Type: String, Values: []string{"latest", "earliest", "none"}},
+	"check.crcs":                    {Type: Boolean},
+	"default.api.timeout.ms":        {Type: Int},
+	"enable.auto.commit":            {Type: Boolean},
+	"exclude.internal.topics":       {Type: Boolean},
+	"fetch.max.bytes":               {Type: Int},
+	"fetch.max.wait.ms":             {Type: Int},
+	"fetch.min.bytes":               {Type: Int},
+	"group.id":                      {Type: String},
+	"group.instance.id":             {Type: String},
+	"heartbeat.interval.ms":         {Type: Int},
+	"isolation.level":               {Type: String, Values: []string{"read_committed", "read_uncommitted"}},
+	"key.deserializer":              {Type: Class},
+	"max.partition.fetch.bytes":     {Type: Int},
+	"max.poll.interval.ms":          {Type: Int},
+	"max.poll.records":              {Type: Int},
+	"partition.assignment.strategy": {Type: List},
+	"session.timeout.ms":            {Type: Int},
+	"value.deserializer":            {Type: Class},
+
+	// Confluent deserializers
+	"specific.avro.reader":         {Type: Boolean},
+	"specific.protobuf.key.type":   {Type: Class},
+	"specific.protobuf.value.type": {Type: Class},
+	"json.key.type":                {Type: Class},
+	"json.value.type":              {Type: Class},
+}
+
+var producerKeys = map[string]Key{
+	"acks":                                  {Type: String, Values: []string{"all", "-1", "0", "1"}},
+	"batch.size":                            {Type: Int},
+	"buffer.memory":                         {Type: Long},
+	"compression.type":                      {Type: String, Values: []string{"none", "gzip", "snappy", "lz4", "zstd"}},
+	"delivery.timeout.ms":                   {Type: Int},
+	"enable.idempotence":                    {Type: Boolean},
+	"key.serializer":                        {Type: Class},
+	"linger.ms":                             {Type: Long},
+	"max.block.ms":                          {Type: Long},
+	"max.in.flight.requests.per.connection": {Type: Int},
+	"max.request.size":                      {Type: Int},
+	"metadata.max.idle.ms":                  {Type: Long},
+	"partitioner.class":                     {Type: Class},
+	"retries":                               {Type: Int},
+	"transaction.timeout.ms":                {Type: Int},
+	"transactional.id":                      {Type: String},
+	"value.serializer":                      {Type: Class},
+}
+
+// knownPrefixes are the prefixes of the keys passed through by the clients
+// to their plugins.
+var knownPrefixes = []string{
+	"schema.registry.ssl.",
+	"confluent.monitoring.interceptor.",
+}
+
+// Lookup returns the description of the key for the client.
+func Lookup(client Client, key string) (Key, bool) {
+	if k, ok := clientKeys(client)[key]; ok {
+		return k, true
+	}
+	k, ok := commonKeys[key]
+	return k, ok
+}
+
+func clientKeys(client Client) map[string]Key {
+	if client == Consumer {
+		return consumerKeys
+	}
+	return producerKeys
+}
+
+func otherClient(client Client) Client {
+	if client == Consumer {
+		return Producer
+	}
+	return Consumer
+}
@@ -0,0 +1,201 @@
+package kafkaconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var classPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// Result is the outcome of the validation of a configuration.
+type Result struct {
+	// Errors are the values which the client would reject.
+	Errors []string
+
+	// Warnings are the unknown keys, which the client ignores.
+	Warnings []string
+}
+
+// Validate checks the configuration of the client. Unknown keys are
+// warnings with a suggestion when a known key is close. The keys matching
+// one of the custom patterns are not checked. A pattern is either a key or a
+// prefix followed by `*`.
+func Validate(client Client, config map[string]string, custom []string) Result {
+	result := Result{}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if matchesAny(key, custom) {
+			continue
+		}
+
+		k, ok := Lookup(client, key)
+		if !ok {
+			if hasKnownPrefix(key) {
+				continue
+			}
+			result.Warnings = append(result.Warnings, unknownKey(client, key))
+			continue
+		}
+
+		if err := checkValue(k, config[key]); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s property %s: %s", client, key, err.Error()))
+		}
+	}
+
+	return result
+}
+
+func unknownKey(client Client, key string) string {
+	message := fmt.Sprintf("unknown %s property %s", client, key)
+
+	if _, ok := clientKeys(otherClient(client))[key]; ok {
+		return fmt.Sprintf("%s, it is a %s property", message, otherClient(client))
+	}
+
+	if suggestion := suggest(client, key); suggestion != "" {
+		return fmt.Sprintf("%s, did you mean %s?", message, suggestion)
+	}
+
+	return message
+}
+
+func checkValue(key Key, value string) error {
+	value = strings.TrimSpace(value)
+
+	if len(key.Values) > 0 {
+		for _, allowed := range key.Values {
+			if strings.EqualFold(value, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q, must be one of %s", value, strings.Join(key.Values, ", "))
+	}
+
+	switch key.Type {
+	case Boolean:
+		if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+			return fmt.Errorf("invalid value %q, must be true or false", value)
+		}
+	case Int:
+		if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+			return fmt.Errorf("invalid value %q, must be an int", value)
+		}
+	case Long:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("invalid value %q, must be a long", value)
+		}
+	case Short:
+		if _, err := strconv.ParseInt(value, 10, 16); err != nil {
+			return fmt.Errorf("invalid value %q, must be a short", value)
+		}
+	case Double:
+		if f, err := strconv.ParseFloat(value, 64); err != nil || math.IsNaN(f) {
+			return fmt.Errorf("invalid value %q, must be a double", value)
+		}
+	case Class:
+		if !classPattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q, must be a class name", value)
+		}
+	}
+
+	return nil
+}
+
+// suggest returns the known key closest to the unknown key, if it is close
+// enough to be a typo.
+func suggest(client Client, key string) string {
+	candidates := []string{}
+	for _, keys := range []map[string]Key{clientKeys(client), commonKeys} {
+		for candidate := range keys {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.Strings(candidates)
+
+	// A typo is at most a few edits away
+	threshold := len(key)/4 + 1
+	if threshold > 3 {
+		threshold = 3
+	}
+
+	best := ""
+	bestDistance := threshold + 1
+	for _, candidate := range candidates {
+		if d := distance(key, candidate); d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+func hasKnownPrefix(key string) bool {
+	for _, prefix := range knownPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package kafkaconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	config := map[string]string{
+		"max.poll.records":                        "500",
+		"enable.auto.commit":                      "False",
+		"auto.offset.reset":                       "Earliest",
+		"bootstrap.servers":                       "kafka:9092",
+		"fetch.max.wait":                          "100",
+		"linger.ms":                               "5",
+		"session.timeout.ms":                      "ten",
+		"isolation.level":                         "read_all",
+		"key.deserializer":                        "not a class",
+		"schema.registry.ssl.truststore.location": "/etc/kafka/truststore.jks",
+		"custom.key":                              "anything",
+		"custom.plugin.key":                       "anything",
+		"unrelated":                               "value",
+	}
+
+	result := Validate(Consumer, config, []string{"custom.key", " custom.plugin.* "})
+
+	expectedErrors := []string{
+		`consumer property isolation.level: invalid value "read_all", must be one of read_committed, read_uncommitted`,
+		`consumer property key.deserializer: invalid value "not a class", must be a class name`,
+		`consumer property session.timeout.ms: invalid value "ten", must be an int`,
+	}
+	if !reflect.DeepEqual(result.Errors, expectedErrors) {
+		t.Errorf("errors = %q, want %q", result.Errors, expectedErrors)
+	}
+
+	expectedWarnings := []string{
+		"unknown consumer property fetch.max.wait, did you mean fetch.max.wait.ms?",
+		"unknown consumer property linger.ms, it is a producer property",
+		"unknown consumer property unrelated",
+	}
+	if !reflect.DeepEqual(result.Warnings, expectedWarnings) {
+		t.Errorf("warnings = %q, want %q", result.Warnings, expectedWarnings)
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		key   Key
+		value string
+		valid bool
+	}{
+		{Key{Type: Boolean}, "true", true},
+		{Key{Type: Boolean}, "yes", false},
+		{Key{Type: Int}, " 42 ", true},
+		{Key{Type: Int}, "4294967296", false},
+		{Key{Type: Long}, "4294967296", true},
+		{Key{Type: Short}, "65536", false},
+		{Key{Type: Double}, "0.5", true},
+		{Key{Type: Double}, "NaN", false},
+		{Key{Type: Class}, "org.apache.kafka.Foo$Bar", true},
+		{Key{Type: Class}, "org..Foo", false},
+		{Key{Type: List}, "a,b", true},
+		{Key{Type: String, Values: []string{"all", "1"}}, "ALL", true},
+		{Key{Type: String, Values: []string{"all", "1"}}, "2", false},
+	}
+
+	for _, test := range tests {
+		err := checkValue(test.key, test.value)
+		if test.valid && err != nil {
+			t.Errorf("%q: unexpected error %q", test.value, err.Error())
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%q: expected an error", test.value)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		client   Client
+		key      string
+		expected string
+	}{
+		{Producer, "lingr.ms", "linger.ms"},
+		{Producer, "compresion.type", "compression.type"},
+		{Consumer, "group.idd", "group.id"},
+		{Consumer, "bootstrap.server", "bootstrap.servers"},
+		{Consumer, "acks", ""},
+		{Consumer, "something.else.entirely", ""},
+	}
+
+	for _, test := range tests {
+		if actual := suggest(test.client, test.key); actual != test.expected {
+			t.Errorf("%s %s: suggestion = %q, want %q", test.client, test.key, actual, test.expected)
+		}
+	}
+
+	if d := distance("kitten", "sitting"); d != 3 {
+		t.Errorf("distance = %d, want 3", d)
+	}
+}
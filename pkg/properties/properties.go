@@ -0,0 +1,275 @@
+// Package properties encodes and parses the .properties files read by the
+// invoker with java.util.Properties#load(InputStream).
+//
+// The encoder only escapes what the Java parser requires so that the common
+// keys and values are written as they are: the backslash, the line
+// terminators, the separators and the comment characters of the keys, the
+// leading whitespace of the values and all the characters outside of the
+// printable ASCII range, which are written as \uXXXX escapes because the
+// file is read as ISO 8859-1.
+package properties
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// EscapeKey escapes a key.
+func EscapeKey(key string) string {
+	return escape(key, true)
+}
+
+// EscapeValue escapes a value.
+func EscapeValue(value string) string {
+	return escape(value, false)
+}
+
+// Line returns the line defining the property.
+func Line(key string, value string) string {
+	return EscapeKey(key) + "=" + EscapeValue(value)
+}
+
+// Encode returns the properties, one line per property ordered by key.
+func Encode(props map[string]string) string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := strings.Builder{}
+	for _, key := range keys {
+		builder.WriteString(Line(key, props[key]))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func escape(s string, isKey bool) string {
+	builder := strings.Builder{}
+	leading := true
+
+	for i, ch := range s {
+		switch {
+		case ch == '\\':
+			builder.WriteString(`\\`)
+		case ch == '\n':
+			builder.WriteString(`\n`)
+		case ch == '\r':
+			builder.WriteString(`\r`)
+		case ch == '\t':
+			writeWhitespace(&builder, `\t`, "\t", isKey || leading)
+		case ch == '\f':
+			writeWhitespace(&builder, `\f`, "\f", isKey || leading)
+		case ch == ' ':
+			writeWhitespace(&builder, `\ `, " ", isKey || leading)
+		case isKey && (ch == '=' || ch == ':'):
+			builder.WriteRune('\\')
+			builder.WriteRune(ch)
+		case (ch == '#' || ch == '!') && i == 0 && isKey:
+			// A key starting with a comment character would comment
+			// out the whole line
+			builder.WriteRune('\\')
+			builder.WriteRune(ch)
+		case ch < 0x20 || ch > 0x7e:
+			for _, unit := range utf16.Encode([]rune{ch}) {
+				fmt.Fprintf(&builder, `\u%04X`, unit)
+			}
+		default:
+			builder.WriteRune(ch)
+		}
+
+		leading = leading && (ch == ' ' || ch == '\t' || ch == '\f')
+	}
+
+	return builder.String()
+}
+
+func writeWhitespace(builder *strings.Builder, escaped string, raw string, escape bool) {
+	if escape {
+		builder.WriteString(escaped)
+	} else {
+		builder.WriteString(raw)
+	}
+}
+
+// Parse parses properties with the rules of java.util.Properties#load:
+// comments, continuation lines, the `=`, `:` and whitespace separators and
+// the escapes. The last definition of a key wins.
+func Parse(s string) (map[string]string, error) {
+	props := make(map[string]string)
+
+	lines := logicalLines(s)
+	for _, line := range lines {
+		key, value, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		props[key] = value
+	}
+
+	return props, nil
+}
+
+// logicalLines splits the input in logical lines: the comments and the
+// blank lines are dropped, the lines ending with an odd number of
+// backslashes are joined with the next line without its leading whitespace.
+func logicalLines(s string) []string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	s = strings.Replace(s, "\r", "\n", -1)
+
+	lines := []string{}
+	current := ""
+	continued := false
+
+	for _, natural := range strings.Split(s, "\n") {
+		trimmed := strings.TrimLeft(natural, " \t\f")
+
+		if !continued {
+			if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+				continue
+			}
+		}
+
+		current += trimmed
+
+		if endsWithEscape(current) {
+			current = current[:len(current)-1]
+			continued = true
+			continue
+		}
+
+		lines = append(lines, current)
+		current = ""
+		continued = false
+	}
+
+	if continued {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func endsWithEscape(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+func parseLine(line string) (string, string, error) {
+	// The key ends at the first unescaped separator or whitespace
+	end := len(line)
+	valueStart := len(line)
+	hasSeparator := false
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if ch == '\\' {
+			i++
+			continue
+		}
+		if ch == '=' || ch == ':' {
+			end = i
+			valueStart = i + 1
+			hasSeparator = true
+			break
+		}
+		if ch == ' ' || ch == '\t' || ch == '\f' {
+			end = i
+			valueStart = i + 1
+			break
+		}
+	}
+
+	// Whitespace, then at most one separator, then whitespace
+	for valueStart < len(line) {
+		ch := line[valueStart]
+		if ch == ' ' || ch == '\t' || ch == '\f' {
+			valueStart++
+			continue
+		}
+		if !hasSeparator && (ch == '=' || ch == ':') {
+			hasSeparator = true
+			valueStart++
+			continue
+		}
+		break
+	}
+
+	key, err := unescape(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	value, err := unescape(line[valueStart:])
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, value, nil
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	units := []uint16{}
+	builder := strings.Builder{}
+
+	flush := func() {
+		if len(units) > 0 {
+			builder.WriteString(string(utf16.Decode(units)))
+			units = units[:0]
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			flush()
+			builder.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			flush()
+			builder.WriteByte('\t')
+		case 'n':
+			flush()
+			builder.WriteByte('\n')
+		case 'r':
+			flush()
+			builder.WriteByte('\r')
+		case 'f':
+			flush()
+			builder.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx escape in %q", s)
+			}
+			unit, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx escape in %q", s)
+			}
+			// Surrogate pairs are decoded together
+			units = append(units, uint16(unit))
+			i += 4
+		default:
+			flush()
+			builder.WriteByte(s[i])
+		}
+	}
+
+	flush()
+
+	return builder.String(), nil
+}
@@ -0,0 +1,171 @@
+package properties
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// alphabet holds the characters with a special meaning in a properties file
+// and characters escaped as \uXXXX, including a rune outside of the Basic
+// Multilingual Plane written as a surrogate pair.
+var alphabet = []rune{
+	'\\', '=', ':', '#', '!', ' ', '\t', '\f', '\n', '\r',
+	'a', 'Z', '0', '.', 'u', 't', 'n',
+	0, 0x7f, 'é', '€', '\ufeff', '😀', 0x10ffff,
+}
+
+func randomString(r *rand.Rand) string {
+	runes := make([]rune, r.Intn(12))
+	for i := range runes {
+		runes[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(runes)
+}
+
+func TestEncodeParseRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 5000; i++ {
+		props := make(map[string]string)
+		for n := r.Intn(4); n >= 0; n-- {
+			props[randomString(r)] = randomString(r)
+		}
+
+		encoded := Encode(props)
+
+		for _, ch := range encoded {
+			if ch > 0x7e || (ch < 0x20 && ch != '\n' && ch != '\t' && ch != '\f') {
+				t.Fatalf("Encode(%q) = %q: unexpected character %U", props, encoded, ch)
+			}
+		}
+
+		parsed, err := Parse(encoded)
+		if err != nil {
+			t.Fatalf("Parse(Encode(%q)): %s", props, err)
+		}
+
+		if !reflect.DeepEqual(parsed, props) {
+			t.Fatalf("Parse(Encode(%q)) = %q\nencoded: %q", props, parsed, encoded)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		expected string
+	}{
+		{"bootstrap.servers", "kafka:9092", "bootstrap.servers=kafka:9092"},
+		{"a=b:c", "d=e:f", `a\=b\:c=d=e:f`},
+		{"#comment", "#value", `\#comment=#value`},
+		{"!bang", "!value", `\!bang=!value`},
+		{"a#b", "x", "a#b=x"},
+		{" key", "  two words", `\ key=\ \ two words`},
+		{"tab\tkey", "\tvalue\t", `tab\tkey=\tvalue` + "\t"},
+		{"path", `C:\tmp`, `path=C:\\tmp`},
+		{"lines", "a\nb\r\n", `lines=a\nb\r\n`},
+		{"unicode", "é😀", `unicode=\u00E9\uD83D\uDE00`},
+	}
+
+	for _, test := range tests {
+		if got := Line(test.key, test.value); got != test.expected {
+			t.Errorf("Line(%q, %q) = %q, want %q", test.key, test.value, got, test.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:     "separators",
+			input:    "a=1\nb:2\nc 3\nd = 4\ne\t:\t5\nf  =  =6\ng\n",
+			expected: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "=6", "g": ""},
+		},
+		{
+			name:     "comments and blank lines",
+			input:    "# comment\n! comment\n\n   \n  # indented comment\na=1\n",
+			expected: map[string]string{"a": "1"},
+		},
+		{
+			name:     "comment characters in a value",
+			input:    "a=#1\nb=x!y\n",
+			expected: map[string]string{"a": "#1", "b": "x!y"},
+		},
+		{
+			name:     "continuation",
+			input:    "a=one, \\\n    two, \\\n\tthree\nb=4\n",
+			expected: map[string]string{"a": "one, two, three", "b": "4"},
+		},
+		{
+			name:     "continuation of the key",
+			input:    "lo\\\n  ng=value\n",
+			expected: map[string]string{"long": "value"},
+		},
+		{
+			name:     "continuation line starting with a comment character",
+			input:    "a=1\\\n# not a comment\n",
+			expected: map[string]string{"a": "1# not a comment"},
+		},
+		{
+			name:     "comment line is never continued",
+			input:    "# comment \\\na=1\n",
+			expected: map[string]string{"a": "1"},
+		},
+		{
+			name:     "escaped backslash at the end of the line",
+			input:    "a=C:\\\\\nb=2\n",
+			expected: map[string]string{"a": `C:\`, "b": "2"},
+		},
+		{
+			name:     "continuation at the end of the input",
+			input:    "a=1\\",
+			expected: map[string]string{"a": "1"},
+		},
+		{
+			name:     "line terminators",
+			input:    "a=1\r\nb=2\rc=3\\\r\n  4",
+			expected: map[string]string{"a": "1", "b": "2", "c": "34"},
+		},
+		{
+			name:     "escapes",
+			input:    `a\ b\=c\:d=\ \ e\tf\ng\rh\fi\j` + "\n",
+			expected: map[string]string{"a b=c:d": "  e\tf\ng\rh\fij"},
+		},
+		{
+			name:     "unicode escapes",
+			input:    `k\u00E9y=\u20AC\uD83D\uDE00` + "\n",
+			expected: map[string]string{"kéy": "€😀"},
+		},
+		{
+			name:     "last definition wins",
+			input:    "a=1\na=2\n",
+			expected: map[string]string{"a": "2"},
+		},
+	}
+
+	for _, test := range tests {
+		props, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(props, test.expected) {
+			t.Errorf("%s: Parse(%q) = %q, want %q", test.name, test.input, props, test.expected)
+		}
+	}
+}
+
+func TestParseMalformedUnicodeEscape(t *testing.T) {
+	for _, input := range []string{`a=\u12`, `a=\uZZZZ`, `\u00=b`} {
+		if _, err := Parse(input); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("Parse(%q) = %v, want a malformed escape error", input, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Fake resolves the images without a registry. The images which are not
+// registered resolve to a digest derived from their reference so a new
+// tag always gets a new digest. It is meant for local clusters and tests.
+type Fake struct {
+	Digests map[string]string
+}
+
+// NewFake returns a Fake resolver knowing the given image digests.
+func NewFake(digests map[string]string) *Fake {
+	if digests == nil {
+		digests = map[string]string{}
+	}
+	return &Fake{Digests: digests}
+}
+
+// Resolve returns the registered digest of the image or a digest derived
+// from its reference.
+func (f *Fake) Resolve(image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	if digest, ok := f.Digests[image]; ok {
+		return digest, nil
+	}
+
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%s:%s", ref.Name(), ref.Tag)))
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
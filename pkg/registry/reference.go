@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHubDomain   = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// Reference is a parsed image reference.
+type Reference struct {
+	// Domain is the registry of the image, docker.io by default.
+	Domain string
+
+	// Repository is the path of the image in the registry.
+	Repository string
+
+	// Tag is the tag of the image, latest by default.
+	Tag string
+
+	// Digest is the digest of the image if the reference has one.
+	Digest string
+}
+
+// ParseReference parses an image reference such as `dajac/kfn-invoker:0.1.0`,
+// `gcr.io/project/image:tag` or `image@sha256:...`.
+func ParseReference(image string) (*Reference, error) {
+	if image == "" {
+		return nil, fmt.Errorf("the image is empty")
+	}
+
+	ref := &Reference{}
+	name := image
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+
+		if !strings.Contains(ref.Digest, ":") {
+			return nil, fmt.Errorf("invalid digest in image %q", image)
+		}
+	}
+
+	// A colon after the last slash separates the tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	// The first component is a domain if it looks like a host
+	if i := strings.Index(name, "/"); i >= 0 && strings.ContainsAny(name[:i], ".:") || strings.HasPrefix(name, "localhost/") {
+		ref.Domain = name[:i]
+		ref.Repository = name[i+1:]
+	} else {
+		ref.Domain = dockerHubDomain
+		ref.Repository = name
+	}
+
+	if ref.Domain == dockerHubDomain && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	if ref.Repository == "" {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+
+	return ref, nil
+}
+
+// Name returns the image without its tag and digest.
+func (r *Reference) Name() string {
+	return r.Domain + "/" + r.Repository
+}
+
+// Pinned returns the image pinned to the digest.
+func (r *Reference) Pinned(digest string) string {
+	return r.Name() + "@" + digest
+}
+
+// registry returns the host serving the registry API.
+func (r *Reference) registry() string {
+	if r.Domain == dockerHubDomain {
+		return dockerHubRegistry
+	}
+	return r.Domain
+}
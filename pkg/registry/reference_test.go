@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Reference
+	}{
+		{"busybox", Reference{Domain: "docker.io", Repository: "library/busybox", Tag: "latest"}},
+		{"dajac/kfn-invoker:0.1.0", Reference{Domain: "docker.io", Repository: "dajac/kfn-invoker", Tag: "0.1.0"}},
+		{"gcr.io/project/image:tag", Reference{Domain: "gcr.io", Repository: "project/image", Tag: "tag"}},
+		{"localhost:5000/image", Reference{Domain: "localhost:5000", Repository: "image", Tag: "latest"}},
+		{"localhost/image:1", Reference{Domain: "localhost", Repository: "image", Tag: "1"}},
+		{"image@sha256:abc", Reference{Domain: "docker.io", Repository: "library/image", Digest: "sha256:abc"}},
+		{"quay.io/org/image:1.0@sha256:abc", Reference{Domain: "quay.io", Repository: "org/image", Tag: "1.0", Digest: "sha256:abc"}},
+	}
+
+	for _, test := range tests {
+		ref, err := ParseReference(test.image)
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", test.image, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(*ref, test.expected) {
+			t.Errorf("%s: reference = %+v, want %+v", test.image, *ref, test.expected)
+		}
+	}
+
+	for _, image := range []string{"", "image@abc", "gcr.io/:tag"} {
+		if _, err := ParseReference(image); err == nil {
+			t.Errorf("%q: expected an error", image)
+		}
+	}
+
+	ref, _ := ParseReference("dajac/kfn-invoker:0.1.0")
+	if pinned := ref.Pinned("sha256:abc"); pinned != "docker.io/dajac/kfn-invoker@sha256:abc" {
+		t.Errorf("pinned = %s", pinned)
+	}
+	if ref.registry() != "registry-1.docker.io" {
+		t.Errorf("the images of Docker Hub must be resolved with registry-1.docker.io")
+	}
+}
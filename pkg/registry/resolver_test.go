@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHTTPResolver(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:org/image:pull" {
+				http.Error(w, "invalid scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret"}`)
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:org/image:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method != http.MethodHead || !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json"):
+			w.WriteHeader(http.StatusBadRequest)
+		case r.URL.Path == "/v2/org/image/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := &HTTPResolver{client: server.Client()}
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	digest, err := resolver.Resolve(host + "/org/image:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != "sha256:abc" {
+		t.Errorf("digest = %s, want sha256:abc", digest)
+	}
+
+	if _, err := resolver.Resolve(host + "/org/image:2.0"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("the unknown tag must be reported, got %v", err)
+	}
+
+	// The digest of a pinned image is not resolved again
+	if digest, _ := resolver.Resolve("unknown.example.com/image@sha256:def"); digest != "sha256:def" {
+		t.Errorf("digest = %s, want sha256:def", digest)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	params := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io", scope="repository:library/busybox:pull,push",error=invalid`)
+
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/busybox:pull,push",
+		"error":   "invalid",
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("params = %v, want %v", params, expected)
+	}
+}
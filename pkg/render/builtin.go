@@ -0,0 +1,132 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/builtin"
+)
+
+const (
+	// BuiltinFunctionClass is the Function of the invoker evaluating the
+	// expressions of a builtin Function.
+	BuiltinFunctionClass = "io.dajac.kfn.invoker.builtin.CelFunction"
+
+	// DefaultBuiltinImage is the stock invoker image running the builtin
+	// Functions unless the operator is configured with another one.
+	DefaultBuiltinImage = "dajac/kfn-invoker:0.1.0"
+)
+
+// ResolveImage returns the Function with the image it runs. A builtin
+// Function has no image of its own so it returns a copy running the
+// builtin image of the operator.
+func ResolveImage(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) *kfnv1alpha1.Function {
+	if function.Spec.Builtin == nil {
+		return function
+	}
+
+	image := defaultConfig.BuiltinImage
+	if image == "" {
+		image = DefaultBuiltinImage
+	}
+
+	resolved := function.DeepCopy()
+	resolved.Spec.Image = image
+	return resolved
+}
+
+// setBuiltin sets the expressions of a builtin Function. The class of the
+// Function is replaced by the one of the invoker.
+func (cfg *FunctionConfig) setBuiltin(function *kfnv1alpha1.Function) {
+	spec := function.Spec.Builtin
+	if spec == nil {
+		return
+	}
+
+	cfg.Function["class"] = BuiltinFunctionClass
+
+	expressions := map[string]string{
+		"builtin.filter": spec.Filter,
+		"builtin.route":  spec.Route,
+	}
+
+	if spec.Map != nil {
+		expressions["builtin.map.key"] = spec.Map.Key
+		expressions["builtin.map.value"] = spec.Map.Value
+	}
+
+	for key, expression := range expressions {
+		if expression != "" {
+			cfg.Function[key] = expression
+		}
+	}
+}
+
+type builtinExpression struct {
+	name       string
+	expression string
+	expected   *cel.Type
+}
+
+// ValidateBuiltin compiles the expressions of a builtin Function and checks
+// their types against the deserializers and the serializers of the
+// Function.
+func ValidateBuiltin(function *kfnv1alpha1.Function) error {
+	spec := function.Spec.Builtin
+	if spec == nil {
+		return nil
+	}
+
+	if function.Spec.Invocation != nil && function.Spec.Invocation.Type == kfnv1alpha1.HTTPInvocation {
+		return fmt.Errorf("builtin can't be invoked over %s", kfnv1alpha1.HTTPInvocation)
+	}
+
+	// The expressions are evaluated by the stock invoker image
+	if function.Spec.Image != "" {
+		return fmt.Errorf("image can't be set with builtin, builtin Functions run the builtin image of the operator")
+	}
+
+	if shadow := function.Spec.Shadow; shadow != nil && shadow.Image != "" {
+		return fmt.Errorf("shadow image can't be set with builtin, builtin Functions run the builtin image of the operator")
+	}
+
+	env, err := builtin.NewEnv(builtin.Record{
+		Key:   builtin.SerdeType(function.Spec.InputKeyDeserializer),
+		Value: builtin.SerdeType(function.Spec.InputValueDeserializer),
+	})
+	if err != nil {
+		return err
+	}
+
+	expressions := []builtinExpression{
+		{"filter", spec.Filter, cel.BoolType},
+		{"route", spec.Route, cel.StringType},
+	}
+
+	if spec.Map != nil {
+		expressions = append(expressions,
+			builtinExpression{"map key", spec.Map.Key, builtin.SerdeType(function.Spec.OutputKeySerializer)},
+			builtinExpression{"map value", spec.Map.Value, builtin.SerdeType(function.Spec.OutoutValueSerializer)},
+		)
+	}
+
+	empty := true
+	for _, e := range expressions {
+		if e.expression == "" {
+			continue
+		}
+		empty = false
+
+		if err := builtin.Check(env, e.expression, e.expected); err != nil {
+			return fmt.Errorf("invalid builtin %s: %s", e.name, err.Error())
+		}
+	}
+
+	if empty {
+		return fmt.Errorf("builtin requires a filter, a map or a route")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func newBuiltinFunction(builtin *kfnv1alpha1.BuiltinSpec) *kfnv1alpha1.Function {
+	function := newRenderFunction()
+	function.Spec.Image = ""
+	function.Spec.OutoutValueSerializer = "string"
+	function.Spec.Builtin = builtin
+	return function
+}
+
+func TestResolveImage(t *testing.T) {
+	function := newBuiltinFunction(&kfnv1alpha1.BuiltinSpec{Filter: "value > 10"})
+
+	if got := ResolveImage(&FunctionDefaultConfig{}, function).Spec.Image; got != DefaultBuiltinImage {
+		t.Errorf("image = %q, want %q", got, DefaultBuiltinImage)
+	}
+
+	resolved := ResolveImage(&FunctionDefaultConfig{BuiltinImage: "registry.example.com/invoker:1.0.0"}, function)
+	if got := resolved.Spec.Image; got != "registry.example.com/invoker:1.0.0" {
+		t.Errorf("image = %q, want the builtin image of the operator", got)
+	}
+	if function.Spec.Image != "" {
+		t.Errorf("the Function must not be modified")
+	}
+
+	pod := newPodTemplate(resolved, NewFunctionConfig(&FunctionDefaultConfig{}, resolved), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "function"}}, nil)
+	if got := pod.Spec.Containers[0].Image; got != "registry.example.com/invoker:1.0.0" {
+		t.Errorf("container image = %q, want the builtin image of the operator", got)
+	}
+
+	function.Spec.Builtin = nil
+	function.Spec.Image = "dajac/kfn-examples:0.1.0"
+	if got := ResolveImage(&FunctionDefaultConfig{}, function); got != function {
+		t.Errorf("the Function without builtin must be returned as it is")
+	}
+}
+
+func TestValidateBuiltin(t *testing.T) {
+	tests := []struct {
+		name    string
+		builtin kfnv1alpha1.BuiltinSpec
+		image   string
+		err     string
+	}{
+		{name: "filter", builtin: kfnv1alpha1.BuiltinSpec{Filter: "value > 10 && key.startsWith(\"a\")"}},
+		{name: "route", builtin: kfnv1alpha1.BuiltinSpec{Route: "headers[\"region\"] == \"eu\" ? \"eu\" : \"other\""}},
+		{name: "map", builtin: kfnv1alpha1.BuiltinSpec{Map: &kfnv1alpha1.BuiltinMap{Key: "topic", Value: "string(value)"}}},
+		{name: "empty", err: "requires a filter"},
+		{name: "filter type", builtin: kfnv1alpha1.BuiltinSpec{Filter: "value + 1"}, err: "invalid builtin filter: returns int, must return bool"},
+		{name: "map type", builtin: kfnv1alpha1.BuiltinSpec{Map: &kfnv1alpha1.BuiltinMap{Value: "value"}}, err: "invalid builtin map value"},
+		{name: "syntax", builtin: kfnv1alpha1.BuiltinSpec{Filter: "value >"}, err: "invalid builtin filter: 1:"},
+		{name: "unknown variable", builtin: kfnv1alpha1.BuiltinSpec{Filter: "record.value > 1"}, err: "undeclared reference"},
+		{name: "image", builtin: kfnv1alpha1.BuiltinSpec{Filter: "true"}, image: "dajac/kfn-invoker:0.1.0", err: "image can't be set with builtin"},
+	}
+
+	for _, test := range tests {
+		function := newBuiltinFunction(&test.builtin)
+		function.Spec.Image = test.image
+
+		err := ValidateBuiltin(function)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: expected an error", test.name)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q does not contain %q", test.name, err, test.err)
+		}
+	}
+}
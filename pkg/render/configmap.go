@@ -1,4 +1,4 @@
-package function
+package render
 
 import (
 	"crypto/sha256"
@@ -10,10 +10,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func newConfigMap(function *kfnv1alpha1.Function, config *FunctionConfig) *corev1.ConfigMap {
+// NewConfigMap returns the ConfigMap holding the properties file of the Function.
+func NewConfigMap(function *kfnv1alpha1.Function, config *FunctionConfig) *corev1.ConfigMap {
+	return NewNamedConfigMap(function, config, function.Name)
+}
+
+// NewNamedConfigMap returns a ConfigMap of the Function with the given name.
+// It is used for the workloads running next to the main one.
+func NewNamedConfigMap(function *kfnv1alpha1.Function, config *FunctionConfig, name string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      function.Name,
+			Name:      name,
 			Namespace: function.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(function, schema.GroupVersionKind{
@@ -24,7 +31,7 @@ func newConfigMap(function *kfnv1alpha1.Function, config *FunctionConfig) *corev
 			},
 		},
 		Data: map[string]string{
-			"function.properties": config.SerializeAsProperties(),
+			config.Runtime.ConfigKey(): config.Serialize(),
 		},
 	}
 }
@@ -43,8 +50,18 @@ func mergeMap(a map[string]string, b map[string]string) map[string]string {
 	return result
 }
 
-func hash(configMap *corev1.ConfigMap) string {
-	props := configMap.Data["function.properties"]
+// ConfigData returns the configuration file stored in the ConfigMap,
+// whatever its format.
+func ConfigData(configMap *corev1.ConfigMap) string {
+	if data, ok := configMap.Data[PropertiesKey]; ok {
+		return data
+	}
+	return configMap.Data[JSONKey]
+}
+
+// Hash returns the hash of the configuration file stored in the ConfigMap.
+func Hash(configMap *corev1.ConfigMap) string {
+	props := ConfigData(configMap)
 	h := sha256.New()
 	h.Write([]byte(props))
 	return hex.EncodeToString(h.Sum(nil))
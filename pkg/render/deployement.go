@@ -0,0 +1,44 @@
+package render
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// NewDeployement returns the Deployment running the Function.
+func NewDeployement(function *kfnv1alpha1.Function, config *FunctionConfig, configMap *corev1.ConfigMap) *appsv1.Deployment {
+	return NewNamedDeployement(function, config, configMap, function.Name, map[string]string{
+		"function": function.Name,
+	})
+}
+
+// NewNamedDeployement returns a Deployment of the Function with the given
+// name and pod labels. It mounts the configuration file of the ConfigMap. The
+// labels are used as selector so they must not overlap with the ones of the
+// other Deployments of the Function.
+func NewNamedDeployement(function *kfnv1alpha1.Function, config *FunctionConfig, configMap *corev1.ConfigMap, name string, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: function.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(function, schema.GroupVersionKind{
+					Group:   kfnv1alpha1.SchemeGroupVersion.Group,
+					Version: kfnv1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Function",
+				}),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &function.Spec.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: newPodTemplate(function, config, configMap, labels),
+		},
+	}
+}
@@ -1,12 +1,11 @@
-package function
+package render
 
 import (
-	"bytes"
-	"fmt"
-	"sort"
+	"encoding/json"
 	"strings"
 
 	"github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/properties"
 )
 
 // FunctionDefaultConfig contains the default configuration used for each Function.
@@ -16,6 +15,20 @@ type FunctionDefaultConfig struct {
 	Function      map[string]string
 	Consumer      map[string]string
 	Producer      map[string]string
+
+	// Profiles are the user-defined profiles, by name.
+	Profiles map[string]Profile
+
+	// Variables are the values of the placeholders defined by the
+	// operator, by name.
+	Variables map[string]string
+
+	// Runtimes are the user-defined runtimes, by name.
+	Runtimes map[string]Runtime
+
+	// BuiltinImage is the image running the builtin Functions. Defaults to
+	// DefaultBuiltinImage.
+	BuiltinImage string
 }
 
 // FunctionConfig represents the configuration passed to the Function runtime.
@@ -23,9 +36,17 @@ type FunctionConfig struct {
 	Function map[string]string
 	Consumer map[string]string
 	Producer map[string]string
+
+	// Runtime starts the invoker of the Function.
+	Runtime Runtime
+
+	// unresolved are the placeholders which can't be resolved.
+	unresolved []string
 }
 
-func newFunctionConfig(
+// NewFunctionConfig returns the configuration of the Function. The default
+// configuration is overridden by the configuration of the Function.
+func NewFunctionConfig(
 	defaultConfig *FunctionDefaultConfig,
 	function *v1alpha1.Function) *FunctionConfig {
 
@@ -41,9 +62,19 @@ func newFunctionConfig(
 	cfg.overrideConsumerProperties(defaultConfig.Consumer)
 	cfg.overrideProducerProperties(defaultConfig.Producer)
 
+	cfg.setRuntime(defaultConfig, function)
+
+	// Profile, before the configuration of the Function
+	cfg.setProfile(defaultConfig, function)
+
 	// Function config
 	cfg.setFunctionProperties(function)
+	cfg.setInvocation(function)
+	cfg.setBuiltin(function)
+	cfg.setRoutes(function)
 	cfg.setSerializerDeserializer(function)
+	cfg.setSchemaRegistry(function)
+	cfg.setProcessingGuarantee(function)
 
 	if function.Spec.FunctionConfig != nil {
 		cfg.overrideFunctionProperties(*function.Spec.FunctionConfig)
@@ -57,6 +88,9 @@ func newFunctionConfig(
 		cfg.overrideProducerProperties(*function.Spec.ProducerConfig)
 	}
 
+	// Placeholders, in all the properties
+	cfg.interpolate(placeholderVariables(defaultConfig, function))
+
 	return cfg
 }
 
@@ -73,6 +107,12 @@ func (cfg *FunctionConfig) setSerializerDeserializer(function *v1alpha1.Function
 	cfg.Producer["value.serializer"] = getSerializer(function.Spec.OutoutValueSerializer)
 }
 
+// IsSerdeType returns true if name is one of the types which can be used
+// instead of the class name of a serializer or a deserializer.
+func IsSerdeType(name string) bool {
+	return getSerializer(name) != name
+}
+
 func getSerializer(name string) string {
 	switch name {
 	case "bytes":
@@ -89,6 +129,12 @@ func getSerializer(name string) string {
 		return "org.apache.kafka.common.serialization.LongSerializer"
 	case "short":
 		return "org.apache.kafka.common.serialization.ShortSerializer"
+	case "avro":
+		return "io.confluent.kafka.serializers.KafkaAvroSerializer"
+	case "json-schema":
+		return "io.confluent.kafka.serializers.json.KafkaJsonSchemaSerializer"
+	case "protobuf":
+		return "io.confluent.kafka.serializers.protobuf.KafkaProtobufSerializer"
 	default:
 		return name
 	}
@@ -110,6 +156,12 @@ func getDeserializer(name string) string {
 		return "org.apache.kafka.common.serialization.LongDeserializer"
 	case "short":
 		return "org.apache.kafka.common.serialization.ShortDeserializer"
+	case "avro":
+		return "io.confluent.kafka.serializers.KafkaAvroDeserializer"
+	case "json-schema":
+		return "io.confluent.kafka.serializers.json.KafkaJsonSchemaDeserializer"
+	case "protobuf":
+		return "io.confluent.kafka.serializers.protobuf.KafkaProtobufDeserializer"
 	default:
 		return name
 	}
@@ -121,6 +173,10 @@ func (cfg *FunctionConfig) setFunctionProperties(function *v1alpha1.Function) {
 	cfg.Function["input"] = function.Spec.Input
 	cfg.Function["output"] = function.Spec.Output
 
+	if function.Spec.State != nil {
+		cfg.Function["state.dir"] = StateMountPath(function)
+	}
+
 	cfg.Consumer["group.id"] = function.Name
 }
 
@@ -136,48 +192,49 @@ func (cfg *FunctionConfig) overrideProducerProperties(src map[string]string) {
 	copyWithPrefix(src, cfg.Producer)
 }
 
-// SerializeAsProperties serializes the FunctionConfig as properties
+// SerializeAsProperties serializes the FunctionConfig as properties. The
+// keys and the values are escaped so they are read back as they are by
+// java.util.Properties, even when they span several lines or contain
+// backslashes.
 func (cfg *FunctionConfig) SerializeAsProperties() string {
 	builder := strings.Builder{}
 
-	serializeMap(&builder, cfg.Function, "function")
+	builder.WriteString(properties.Encode(withPrefix(cfg.Function, "function")))
 	builder.WriteString("\n")
-	serializeMap(&builder, cfg.Consumer, "consumer")
+	builder.WriteString(properties.Encode(withPrefix(cfg.Consumer, "consumer")))
 	builder.WriteString("\n")
-	serializeMap(&builder, cfg.Producer, "producer")
+	builder.WriteString(properties.Encode(withPrefix(cfg.Producer, "producer")))
 
 	return builder.String()
 }
 
-func serializeMap(builder *strings.Builder, props map[string]string, prefix string) {
-	sortedKeys := make([]string, 0, len(props))
-
-	for key := range props {
-		sortedKeys = append(sortedKeys, key)
-	}
-
-	sort.Strings(sortedKeys)
-
-	for _, key := range sortedKeys {
-		builder.WriteString(fmt.Sprintf("%s.%s=%s\n", prefix, key, escape(props[key])))
+// Serialize serializes the FunctionConfig in the format of its runtime.
+func (cfg *FunctionConfig) Serialize() string {
+	if cfg.Runtime.ConfigFormat == JSONFormat {
+		return cfg.SerializeAsJSON()
 	}
+	return cfg.SerializeAsProperties()
 }
 
-func escape(s string) string {
-	buffer := bytes.Buffer{}
+// SerializeAsJSON serializes the FunctionConfig as a JSON object with the
+// function, consumer and producer objects.
+func (cfg *FunctionConfig) SerializeAsJSON() string {
+	// Maps of strings can always be marshalled
+	data, _ := json.MarshalIndent(map[string]map[string]string{
+		"function": cfg.Function,
+		"consumer": cfg.Consumer,
+		"producer": cfg.Producer,
+	}, "", "  ")
+
+	return string(data) + "\n"
+}
 
-	for _, ch := range s {
-		switch ch {
-		case '\n':
-			buffer.WriteString("\\n")
-		case '\r':
-			buffer.WriteString("\\r")
-		default:
-			buffer.WriteRune(ch)
-		}
+func withPrefix(props map[string]string, prefix string) map[string]string {
+	result := make(map[string]string, len(props))
+	for key, value := range props {
+		result[prefix+"."+key] = value
 	}
-
-	return buffer.String()
+	return result
 }
 
 func copyWithPrefix(src map[string]string, dst map[string]string) {
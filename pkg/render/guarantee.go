@@ -0,0 +1,109 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// exactlyOnceConsumer and exactlyOnceProducer are the properties required
+// by exactly-once processing. The transactional id is a pod property.
+var (
+	exactlyOnceConsumer = map[string]string{
+		"isolation.level":    "read_committed",
+		"enable.auto.commit": "false",
+	}
+
+	exactlyOnceProducer = map[string]string{
+		"enable.idempotence": "true",
+		"acks":               "all",
+	}
+)
+
+// TransactionalIDPrefix returns the prefix of the transactional ids of the
+// producers of the Function. It is followed by the name of the pod.
+func TransactionalIDPrefix(function *kfnv1alpha1.Function) string {
+	return function.Namespace + "." + function.Name + "."
+}
+
+// setProcessingGuarantee sets the properties of the processing guarantee.
+// They override the default configuration but conflicting user overrides
+// are rejected by ValidateProcessingGuarantee.
+func (cfg *FunctionConfig) setProcessingGuarantee(function *kfnv1alpha1.Function) {
+	guarantee := function.Spec.ProcessingGuarantee
+	if guarantee == "" {
+		return
+	}
+
+	cfg.Function["processing.guarantee"] = guarantee
+
+	if guarantee == kfnv1alpha1.ExactlyOnce {
+		copyWithPrefix(exactlyOnceConsumer, cfg.Consumer)
+		copyWithPrefix(exactlyOnceProducer, cfg.Producer)
+	}
+}
+
+// ValidateProcessingGuarantee checks the processing guarantee of the
+// Function and rejects the consumer and producer properties, and the
+// workload type, which conflict with it.
+func ValidateProcessingGuarantee(function *kfnv1alpha1.Function) error {
+	switch function.Spec.ProcessingGuarantee {
+	case "", kfnv1alpha1.AtLeastOnce:
+		return nil
+	case kfnv1alpha1.ExactlyOnce:
+	default:
+		return fmt.Errorf("invalid processing guarantee %q: must be %s or %s", function.Spec.ProcessingGuarantee, kfnv1alpha1.AtLeastOnce, kfnv1alpha1.ExactlyOnce)
+	}
+
+	if function.Spec.WorkloadType == kfnv1alpha1.DeploymentWorkload {
+		return fmt.Errorf("exactly-once processing requires the %s workload type", kfnv1alpha1.StatefulSetWorkload)
+	}
+
+	if hasConfig(function.Spec.ProducerConfig, "transactional.id") {
+		return fmt.Errorf("producer property transactional.id can't be set with exactly-once processing, it is set for each pod")
+	}
+
+	if err := validateOverrides("consumer", function.Spec.ConsumerConfig, exactlyOnceConsumer); err != nil {
+		return err
+	}
+
+	if err := validateOverrides("producer", function.Spec.ProducerConfig, exactlyOnceProducer); err != nil {
+		return err
+	}
+
+	if function.Spec.ProducerConfig != nil {
+		if value, ok := (*function.Spec.ProducerConfig)["max.in.flight.requests.per.connection"]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 5 {
+				return fmt.Errorf("producer property max.in.flight.requests.per.connection=%s conflicts with exactly-once processing, it must be between 1 and 5", value)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateOverrides(name string, config *map[string]string, required map[string]string) error {
+	if config == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(required))
+	for key := range required {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := (*config)[key]
+		expected := required[key]
+		if !ok || value == expected || (key == "acks" && value == "-1") {
+			continue
+		}
+
+		return fmt.Errorf("%s property %s=%s conflicts with exactly-once processing, it must be %s", name, key, value, expected)
+	}
+
+	return nil
+}
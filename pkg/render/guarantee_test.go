@@ -0,0 +1,85 @@
+package render
+
+import (
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func newExactlyOnceFunction() *kfnv1alpha1.Function {
+	function := newRenderFunction()
+	function.Spec.ProcessingGuarantee = kfnv1alpha1.ExactlyOnce
+	return function
+}
+
+func TestExactlyOnceRunsAsStatefulSet(t *testing.T) {
+	function := newExactlyOnceFunction()
+
+	if !IsStatefulSet(function) {
+		t.Fatalf("exactly-once Function must run as a StatefulSet")
+	}
+
+	props := PodProperties(function)
+	if got, want := props["producer.transactional.id"], "default.copy.${POD_NAME}"; got != want {
+		t.Errorf("transactional.id = %q, want %q", got, want)
+	}
+	if got := props["consumer.group.instance.id"]; got != "${POD_NAME}" {
+		t.Errorf("group.instance.id = %q, want ${POD_NAME}", got)
+	}
+}
+
+func TestValidateProcessingGuarantee(t *testing.T) {
+	tests := []struct {
+		name         string
+		workloadType string
+		consumer     map[string]string
+		producer     map[string]string
+		valid        bool
+	}{
+		{name: "default workload", valid: true},
+		{name: "statefulset", workloadType: kfnv1alpha1.StatefulSetWorkload, valid: true},
+		{name: "deployment", workloadType: kfnv1alpha1.DeploymentWorkload},
+		{name: "transactional id", producer: map[string]string{"transactional.id": "tx"}},
+		{name: "read uncommitted", consumer: map[string]string{"isolation.level": "read_uncommitted"}},
+		{name: "acks -1", producer: map[string]string{"acks": "-1"}, valid: true},
+		{name: "acks 1", producer: map[string]string{"acks": "1"}},
+		{name: "max in flight", producer: map[string]string{"max.in.flight.requests.per.connection": "5"}, valid: true},
+		{name: "too many in flight", producer: map[string]string{"max.in.flight.requests.per.connection": "6"}},
+	}
+
+	for _, test := range tests {
+		function := newExactlyOnceFunction()
+		function.Spec.WorkloadType = test.workloadType
+		if test.consumer != nil {
+			function.Spec.ConsumerConfig = &test.consumer
+		}
+		if test.producer != nil {
+			function.Spec.ProducerConfig = &test.producer
+		}
+
+		err := ValidateProcessingGuarantee(function)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestExactlyOnceShadow(t *testing.T) {
+	function := newExactlyOnceFunction()
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{}
+
+	shadow := NewShadowFunction(function)
+	if IsStatefulSet(shadow) {
+		t.Fatalf("the shadow must run as a Deployment")
+	}
+
+	props := PodProperties(shadow)
+	for _, key := range []string{"producer.transactional.id", "consumer.group.instance.id"} {
+		if value, ok := props[key]; ok {
+			t.Errorf("unexpected pod property %s=%s in the shadow", key, value)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// PinnedFunction returns a copy of the Function running the pinned images
+// if they have been resolved for the current images, e.g. in the status of
+// the live Function. shadowImage is the pinned image of the shadow.
+func PinnedFunction(function *kfnv1alpha1.Function, image *kfnv1alpha1.ImageStatus, shadowImage *kfnv1alpha1.ImageStatus) *kfnv1alpha1.Function {
+	if !function.Spec.PinImageDigest {
+		return function
+	}
+
+	pinImage := isPinned(image, function.Spec.Image)
+	pinShadow := function.Spec.Shadow != nil && isPinned(shadowImage, function.Spec.Shadow.Image)
+	if !pinImage && !pinShadow {
+		return function
+	}
+
+	pinned := function.DeepCopy()
+	if pinImage {
+		pinned.Spec.Image = image.Pinned
+	}
+	if pinShadow {
+		pinned.Spec.Shadow.Image = shadowImage.Pinned
+	}
+	return pinned
+}
+
+func isPinned(status *kfnv1alpha1.ImageStatus, image string) bool {
+	return image != "" && status != nil && status.Image == image && status.Pinned != ""
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestPinnedFunction(t *testing.T) {
+	pinned := "docker.io/dajac/kfn-examples@sha256:0123"
+
+	tests := []struct {
+		name     string
+		pin      bool
+		image    *kfnv1alpha1.ImageStatus
+		expected string
+	}{
+		{"pinned", true, &kfnv1alpha1.ImageStatus{Image: "dajac/kfn-examples:0.1.0", Pinned: pinned}, pinned},
+		{"not pinning", false, &kfnv1alpha1.ImageStatus{Image: "dajac/kfn-examples:0.1.0", Pinned: pinned}, "dajac/kfn-examples:0.1.0"},
+		{"never resolved", true, nil, "dajac/kfn-examples:0.1.0"},
+		{"other image", true, &kfnv1alpha1.ImageStatus{Image: "dajac/kfn-examples:0.0.9", Pinned: pinned}, "dajac/kfn-examples:0.1.0"},
+		{"resolution failed", true, &kfnv1alpha1.ImageStatus{Image: "dajac/kfn-examples:0.1.0", Error: "unavailable"}, "dajac/kfn-examples:0.1.0"},
+	}
+
+	for _, test := range tests {
+		function := &kfnv1alpha1.Function{
+			Spec: kfnv1alpha1.FunctionSpec{
+				Image:          "dajac/kfn-examples:0.1.0",
+				PinImageDigest: test.pin,
+			},
+		}
+
+		if got := PinnedFunction(function, test.image, nil).Spec.Image; got != test.expected {
+			t.Errorf("%s: image = %s, want %s", test.name, got, test.expected)
+		}
+		if function.Spec.Image != "dajac/kfn-examples:0.1.0" {
+			t.Errorf("%s: the Function must not be modified", test.name)
+		}
+	}
+}
+
+func TestPinnedShadow(t *testing.T) {
+	pinned := "docker.io/dajac/kfn-examples@sha256:4567"
+	function := &kfnv1alpha1.Function{
+		Spec: kfnv1alpha1.FunctionSpec{
+			Image:          "dajac/kfn-examples:0.1.0",
+			PinImageDigest: true,
+			Shadow:         &kfnv1alpha1.ShadowSpec{Image: "dajac/kfn-examples:0.2.0"},
+		},
+	}
+
+	shadowImage := &kfnv1alpha1.ImageStatus{Image: "dajac/kfn-examples:0.2.0", Pinned: pinned}
+	if got := PinnedFunction(function, nil, shadowImage); got.Spec.Shadow.Image != pinned || got.Spec.Image != "dajac/kfn-examples:0.1.0" {
+		t.Errorf("image = %s, shadow image = %s, want only the shadow pinned", got.Spec.Image, got.Spec.Shadow.Image)
+	}
+	if function.Spec.Shadow.Image != "dajac/kfn-examples:0.2.0" {
+		t.Errorf("the Function must not be modified")
+	}
+
+	function.Spec.Shadow.Image = "dajac/kfn-examples:0.3.0"
+	if got := PinnedFunction(function, nil, shadowImage); got != function {
+		t.Errorf("the digest of another image of the shadow must not be used")
+	}
+}
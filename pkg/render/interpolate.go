@@ -0,0 +1,261 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/properties"
+)
+
+// placeholderPattern matches the placeholders of the configuration values,
+// e.g. ${function.name}, and the escaped dollar signs, $$.
+var placeholderPattern = regexp.MustCompile(`\$\$|\$\{([^}]*)\}`)
+
+const secretPlaceholderPrefix = "secret:"
+
+// isRuntimePlaceholder returns true if the placeholder is only known in the
+// pod: the pod name, the pod namespace and the keys of Secrets, which are
+// never written in the ConfigMap of the Function.
+func isRuntimePlaceholder(name string) bool {
+	return name == "pod.name" || name == "pod.namespace" || strings.HasPrefix(name, secretPlaceholderPrefix)
+}
+
+// hasRuntimePlaceholder returns true if the value contains a placeholder
+// resolved in the pod.
+func hasRuntimePlaceholder(value string) bool {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+		if match[0] != "$$" && isRuntimePlaceholder(match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSecretPlaceholder returns the Secret and the key of a
+// ${secret:<name>/<key>} placeholder.
+func parseSecretPlaceholder(name string) (string, string, bool) {
+	if !strings.HasPrefix(name, secretPlaceholderPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(name, secretPlaceholderPrefix), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// placeholderVariables returns the variables known when the Function is
+// rendered: its metadata and the variables of the operator.
+func placeholderVariables(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) map[string]string {
+	variables := make(map[string]string)
+
+	for name, value := range defaultConfig.Variables {
+		variables[name] = value
+	}
+
+	for key, value := range function.Labels {
+		variables["function.labels."+key] = value
+	}
+
+	variables["function.name"] = function.Name
+	variables["function.namespace"] = function.Namespace
+	variables["namespace"] = function.Namespace
+
+	return variables
+}
+
+// interpolate replaces the placeholders of the variables in the value. The
+// placeholders resolved in the pod are kept, with the escaped dollar signs,
+// as the whole value is resolved again when the container starts. The
+// other placeholders are returned.
+func interpolate(value string, variables map[string]string) (string, []string) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	runtime := hasRuntimePlaceholder(value)
+	unresolved := []string{}
+
+	result := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			if runtime {
+				return match
+			}
+			return "$"
+		}
+
+		name := match[2 : len(match)-1]
+		if isRuntimePlaceholder(name) {
+			return match
+		}
+
+		if value, ok := variables[name]; ok {
+			return value
+		}
+
+		unresolved = append(unresolved, match)
+		return match
+	})
+
+	return result, unresolved
+}
+
+// interpolate replaces the placeholders of all the properties and records
+// the ones which can't be resolved.
+func (cfg *FunctionConfig) interpolate(variables map[string]string) {
+	configs := []struct {
+		prefix string
+		props  map[string]string
+	}{
+		{"function", cfg.Function},
+		{"consumer", cfg.Consumer},
+		{"producer", cfg.Producer},
+	}
+
+	for _, c := range configs {
+		for key, value := range c.props {
+			result, unresolved := interpolate(value, variables)
+			c.props[key] = result
+
+			for _, placeholder := range unresolved {
+				cfg.unresolved = append(cfg.unresolved, fmt.Sprintf("%s property %s: unknown placeholder %s", c.prefix, key, placeholder))
+			}
+
+			for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+				if !strings.HasPrefix(match[1], secretPlaceholderPrefix) {
+					continue
+				}
+				if _, _, ok := parseSecretPlaceholder(match[1]); !ok {
+					cfg.unresolved = append(cfg.unresolved, fmt.Sprintf("%s property %s: %s must be ${secret:<name>/<key>}", c.prefix, key, match[0]))
+				}
+			}
+		}
+	}
+
+	sort.Strings(cfg.unresolved)
+}
+
+// ValidatePlaceholders checks that all the placeholders of the
+// configuration of the Function, and of its shadow, can be resolved.
+func ValidatePlaceholders(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	errors := NewFunctionConfig(defaultConfig, function).unresolved
+
+	if function.Spec.Shadow != nil {
+		for _, e := range NewFunctionConfig(defaultConfig, NewShadowFunction(function)).unresolved {
+			errors = append(errors, "shadow "+e)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+
+	return nil
+}
+
+// runtimeProperties returns the properties of the configuration whose
+// value has placeholders resolved in the pod, as pod properties, and the
+// environment variables holding the keys of the Secrets.
+func runtimeProperties(config *FunctionConfig) (map[string]string, []corev1.EnvVar) {
+	props := make(map[string]string)
+	env := []corev1.EnvVar{}
+
+	all := mergeMap(withPrefix(config.Function, "function"), withPrefix(config.Consumer, "consumer"))
+	all = mergeMap(all, withPrefix(config.Producer, "producer"))
+
+	keys := make([]string, 0, len(all))
+	for key, value := range all {
+		if hasRuntimePlaceholder(value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	secrets := make(map[string]string)
+
+	secretEnv := func(secret string, key string) string {
+		name := secret + "/" + key
+		if envName, ok := secrets[name]; ok {
+			return envName
+		}
+
+		envName := fmt.Sprintf("KFN_SECRET_%d", len(secrets))
+		secrets[name] = envName
+		env = append(env, corev1.EnvVar{
+			Name: envName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+					Key:                  key,
+				},
+			},
+		})
+		return envName
+	}
+
+	for _, key := range keys {
+		props[shellEscape(properties.EscapeKey(key))] = runtimeValue(all[key], secretEnv)
+	}
+
+	return props, env
+}
+
+// runtimeValue returns the value as a shell template: the placeholders
+// are replaced by the environment variables of the pod and the rest of the
+// value is escaped for the properties file and the shell.
+func runtimeValue(value string, secretEnv func(string, string) string) string {
+	builder := strings.Builder{}
+	literal := strings.Builder{}
+
+	flush := func() {
+		builder.WriteString(shellEscape(properties.EscapeValue(literal.String())))
+		literal.Reset()
+	}
+
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(value, -1) {
+		literal.WriteString(value[last:match[0]])
+		last = match[1]
+
+		placeholder := value[match[0]:match[1]]
+		if placeholder == "$$" {
+			literal.WriteString("$")
+			continue
+		}
+
+		name := value[match[2]:match[3]]
+		secret, secretKey, isSecret := parseSecretPlaceholder(name)
+
+		switch {
+		case name == "pod.name":
+			flush()
+			builder.WriteString("${POD_NAME}")
+		case name == "pod.namespace":
+			flush()
+			builder.WriteString("${POD_NAMESPACE}")
+		case isSecret:
+			flush()
+			builder.WriteString("${" + secretEnv(secret, secretKey) + "}")
+		default:
+			literal.WriteString(placeholder)
+		}
+	}
+	literal.WriteString(value[last:])
+	flush()
+
+	return builder.String()
+}
+
+// shellEscape escapes the characters interpreted by the shell in a double
+// quoted string.
+func shellEscape(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return replacer.Replace(s)
+}
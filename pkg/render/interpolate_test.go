@@ -0,0 +1,125 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string]string{"function.name": "copy", "cluster": "eu"}
+
+	tests := []struct {
+		value      string
+		expected   string
+		unresolved []string
+	}{
+		{"plain", "plain", nil},
+		{"${function.name}-group", "copy-group", []string{}},
+		{"${cluster}/${function.name}", "eu/copy", []string{}},
+		{"price: $$5", "price: $5", []string{}},
+		{"$${function.name}", "${function.name}", []string{}},
+		{"${unknown}-${cluster}", "${unknown}-eu", []string{"${unknown}"}},
+		// The value is resolved again in the pod so $$ is kept
+		{"${pod.name}-$$-${cluster}", "${pod.name}-$$-eu", []string{}},
+		{"${secret:credentials/password}", "${secret:credentials/password}", []string{}},
+	}
+
+	for _, test := range tests {
+		actual, unresolved := interpolate(test.value, variables)
+		if actual != test.expected {
+			t.Errorf("%q: value = %q, want %q", test.value, actual, test.expected)
+		}
+		if !reflect.DeepEqual(unresolved, test.unresolved) {
+			t.Errorf("%q: unresolved = %q, want %q", test.value, unresolved, test.unresolved)
+		}
+	}
+}
+
+func TestInterpolateFunctionConfig(t *testing.T) {
+	consumer := map[string]string{"group.id": "${namespace}.${function.labels.team}.${function.name}"}
+	producer := map[string]string{"client.id": "${pod.name}"}
+
+	function := newRenderFunction()
+	function.Labels = map[string]string{"team": "payments"}
+	function.Spec.ConsumerConfig = &consumer
+	function.Spec.ProducerConfig = &producer
+
+	props := renderedProperties(t, &FunctionDefaultConfig{}, function)
+	if props["consumer.group.id"] != "default.payments.copy" {
+		t.Errorf("group.id = %q, want default.payments.copy", props["consumer.group.id"])
+	}
+
+	// The placeholders of the pod are resolved when the container starts
+	runtimeProps, env := runtimeProperties(NewFunctionConfig(&FunctionDefaultConfig{}, function))
+	if value := runtimeProps["producer.client.id"]; value != "${POD_NAME}" || len(env) != 0 {
+		t.Errorf("client.id = %q, env = %v", value, env)
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"known", "${function.name}", ""},
+		{"variable", "${environment}", ""},
+		{"secret", "${secret:credentials/password}", ""},
+		{"unknown", "${function.labels.missing}", "consumer property group.id: unknown placeholder ${function.labels.missing}"},
+		{"invalid secret", "${secret:credentials}", "${secret:credentials} must be ${secret:<name>/<key>}"},
+	}
+
+	defaultConfig := &FunctionDefaultConfig{Variables: map[string]string{"environment": "production"}}
+
+	for _, test := range tests {
+		consumer := map[string]string{"group.id": test.value}
+		function := newRenderFunction()
+		function.Spec.ConsumerConfig = &consumer
+
+		err := ValidatePlaceholders(defaultConfig, function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q does not contain %q", test.name, err.Error(), test.err)
+		}
+	}
+
+	// The placeholders of the shadow are checked too
+	function := newRenderFunction()
+	shadowConfig := map[string]string{"mode": "${unknown}"}
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{FunctionConfig: &shadowConfig}
+	if err := ValidatePlaceholders(defaultConfig, function); err == nil || !strings.HasPrefix(err.Error(), "shadow function property mode") {
+		t.Errorf("the placeholder of the shadow must be rejected, got %v", err)
+	}
+}
+
+func TestRuntimeValue(t *testing.T) {
+	secretEnv := func(secret string, key string) string {
+		return "KFN_SECRET_" + secret + "_" + key
+	}
+
+	// The literal parts are escaped on their own so their leading spaces
+	// are escaped too
+	tests := map[string]string{
+		"${pod.namespace}/${pod.name}":       "${POD_NAMESPACE}/${POD_NAME}",
+		"${secret:a/b} and $$":               `${KFN_SECRET_a_b}\\ and \$`,
+		`"quoted" ${pod.name}`:               `\"quoted\" ${POD_NAME}`,
+		"${pod.name} ${function.name}":       `${POD_NAME}\\ \${function.name}`,
+		"${pod.name}\nnext line with = sign": `${POD_NAME}\\nnext line with = sign`,
+	}
+
+	for value, expected := range tests {
+		if actual := runtimeValue(value, secretEnv); actual != expected {
+			t.Errorf("%q: value = %q, want %q", value, actual, expected)
+		}
+	}
+}
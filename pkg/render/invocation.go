@@ -0,0 +1,157 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	// HTTPFunctionClass is the Function of the invoker forwarding the
+	// records to the container of a Function invoked over HTTP.
+	HTTPFunctionClass = "io.dajac.kfn.invoker.http.HttpFunction"
+
+	httpContainerName = "kfn-function"
+
+	defaultHTTPPort          = 8080
+	defaultHTTPPath          = "/"
+	defaultHTTPTimeoutMillis = 30000
+	defaultHTTPConcurrency   = 1
+	defaultHTTPBatchSize     = 1
+)
+
+// IsHTTPInvocation returns true if the records are forwarded to a container
+// of the pod over HTTP.
+func IsHTTPInvocation(function *kfnv1alpha1.Function) bool {
+	invocation := function.Spec.Invocation
+	return invocation != nil && invocation.Type == kfnv1alpha1.HTTPInvocation && invocation.HTTP != nil
+}
+
+// HTTPURL returns the URL the invoker posts the records to.
+func HTTPURL(function *kfnv1alpha1.Function) string {
+	return fmt.Sprintf("http://localhost:%d%s", httpPort(function), httpPath(function))
+}
+
+func httpPort(function *kfnv1alpha1.Function) int32 {
+	if port := function.Spec.Invocation.HTTP.Port; port != 0 {
+		return port
+	}
+	return defaultHTTPPort
+}
+
+func httpPath(function *kfnv1alpha1.Function) string {
+	if path := function.Spec.Invocation.HTTP.Path; path != "" {
+		return path
+	}
+	return defaultHTTPPath
+}
+
+func int32OrDefault(value *int32, defaultValue int32) int32 {
+	if value != nil {
+		return *value
+	}
+	return defaultValue
+}
+
+// setInvocation sets the properties of the invoker calling the container of
+// a Function invoked over HTTP. The class of the Function is replaced by the
+// one of the invoker.
+func (cfg *FunctionConfig) setInvocation(function *kfnv1alpha1.Function) {
+	if !IsHTTPInvocation(function) {
+		return
+	}
+
+	http := function.Spec.Invocation.HTTP
+
+	cfg.Function["class"] = HTTPFunctionClass
+	cfg.Function["invocation"] = kfnv1alpha1.HTTPInvocation
+	cfg.Function["http.url"] = HTTPURL(function)
+	cfg.Function["http.timeout.ms"] = strconv.Itoa(int(int32OrDefault(http.TimeoutMillis, defaultHTTPTimeoutMillis)))
+	cfg.Function["http.concurrency"] = strconv.Itoa(int(int32OrDefault(http.Concurrency, defaultHTTPConcurrency)))
+	cfg.Function["http.batch.size"] = strconv.Itoa(int(int32OrDefault(http.BatchSize, defaultHTTPBatchSize)))
+}
+
+// newHTTPContainer returns the container of a Function invoked over HTTP.
+// The pod is ready once the container accepts connections.
+func newHTTPContainer(function *kfnv1alpha1.Function) corev1.Container {
+	http := function.Spec.Invocation.HTTP
+
+	return corev1.Container{
+		Name:            httpContainerName,
+		Image:           http.Image,
+		ImagePullPolicy: imagePullPolicy(http.Image),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: httpPort(function),
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Env: append([]corev1.EnvVar{}, http.Env...),
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(httpPort(function))),
+				},
+			},
+		},
+	}
+}
+
+// ValidateInvocation checks the invocation of the Function.
+func ValidateInvocation(function *kfnv1alpha1.Function) error {
+	invocation := function.Spec.Invocation
+	if invocation == nil {
+		return nil
+	}
+
+	switch invocation.Type {
+	case "", kfnv1alpha1.ClassInvocation:
+		if invocation.HTTP != nil {
+			return fmt.Errorf("invocation http requires the %s invocation type", kfnv1alpha1.HTTPInvocation)
+		}
+		return nil
+	case kfnv1alpha1.HTTPInvocation:
+	default:
+		return fmt.Errorf("invalid invocation type %q: must be %s or %s", invocation.Type, kfnv1alpha1.ClassInvocation, kfnv1alpha1.HTTPInvocation)
+	}
+
+	http := invocation.HTTP
+	if http == nil {
+		return fmt.Errorf("the %s invocation type requires invocation http", kfnv1alpha1.HTTPInvocation)
+	}
+
+	if http.Image == "" {
+		return fmt.Errorf("invocation http requires an image")
+	}
+
+	if http.Port < 0 || http.Port > 65535 {
+		return fmt.Errorf("invalid invocation http port %d: must be between 1 and 65535", http.Port)
+	}
+
+	if http.Path != "" && !strings.HasPrefix(http.Path, "/") {
+		return fmt.Errorf("invalid invocation http path %q: must start with /", http.Path)
+	}
+
+	values := []struct {
+		name  string
+		value *int32
+	}{
+		{"timeoutMillis", http.TimeoutMillis},
+		{"concurrency", http.Concurrency},
+		{"batchSize", http.BatchSize},
+	}
+
+	for _, v := range values {
+		if v.value != nil && *v.value <= 0 {
+			return fmt.Errorf("invalid invocation http %s %d: must be positive", v.name, *v.value)
+		}
+	}
+
+	return nil
+}
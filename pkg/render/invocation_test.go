@@ -0,0 +1,91 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestHTTPInvocation(t *testing.T) {
+	concurrency := int32(4)
+	function := newRenderFunction()
+	function.Spec.Invocation = &kfnv1alpha1.InvocationSpec{
+		Type: kfnv1alpha1.HTTPInvocation,
+		HTTP: &kfnv1alpha1.HTTPInvocationSpec{
+			Image:       "example/handler:latest",
+			Path:        "/records",
+			Concurrency: &concurrency,
+			Env:         []corev1.EnvVar{{Name: "MODE", Value: "fast"}},
+		},
+	}
+
+	props := renderedProperties(t, &FunctionDefaultConfig{}, function)
+
+	expected := map[string]string{
+		"function.class":            HTTPFunctionClass,
+		"function.invocation":       "http",
+		"function.http.url":         "http://localhost:8080/records",
+		"function.http.timeout.ms":  "30000",
+		"function.http.concurrency": "4",
+		"function.http.batch.size":  "1",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("%s = %q, want %q", key, props[key], value)
+		}
+	}
+
+	containers := Render(&FunctionDefaultConfig{}, function).Deployement.Spec.Template.Spec.Containers
+	if len(containers) != 2 {
+		t.Fatalf("got %d containers, want 2", len(containers))
+	}
+	container := containers[1]
+	if container.Image != "example/handler:latest" || container.ImagePullPolicy != corev1.PullAlways || container.Ports[0].ContainerPort != 8080 {
+		t.Errorf("unexpected container %+v", container)
+	}
+	if container.ReadinessProbe.TCPSocket.Port.IntValue() != 8080 || container.Env[0].Name != "MODE" {
+		t.Errorf("unexpected probe or environment of the container %+v", container)
+	}
+}
+
+func TestValidateInvocation(t *testing.T) {
+	zero := int32(0)
+
+	tests := []struct {
+		name       string
+		invocation *kfnv1alpha1.InvocationSpec
+		err        string
+	}{
+		{"none", nil, ""},
+		{"class", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.ClassInvocation}, ""},
+		{"http", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation, HTTP: &kfnv1alpha1.HTTPInvocationSpec{Image: "handler", Port: 9000, Path: "/"}}, ""},
+		{"unknown type", &kfnv1alpha1.InvocationSpec{Type: "grpc"}, `invalid invocation type "grpc"`},
+		{"class with http", &kfnv1alpha1.InvocationSpec{HTTP: &kfnv1alpha1.HTTPInvocationSpec{Image: "handler"}}, "requires the http invocation type"},
+		{"http without http", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation}, "requires invocation http"},
+		{"no image", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation, HTTP: &kfnv1alpha1.HTTPInvocationSpec{}}, "requires an image"},
+		{"invalid port", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation, HTTP: &kfnv1alpha1.HTTPInvocationSpec{Image: "handler", Port: 70000}}, "invalid invocation http port 70000"},
+		{"relative path", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation, HTTP: &kfnv1alpha1.HTTPInvocationSpec{Image: "handler", Path: "records"}}, "must start with /"},
+		{"zero batch", &kfnv1alpha1.InvocationSpec{Type: kfnv1alpha1.HTTPInvocation, HTTP: &kfnv1alpha1.HTTPInvocationSpec{Image: "handler", BatchSize: &zero}}, "invalid invocation http batchSize 0"},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.Invocation = test.invocation
+
+		err := ValidateInvocation(function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q does not contain %q", test.name, err.Error(), test.err)
+		}
+	}
+}
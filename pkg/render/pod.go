@@ -0,0 +1,290 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	configurationPath = "/etc/kfn"
+
+	// runtimePath holds the properties file completed with the pod
+	// properties when the container starts.
+	runtimePath = "/var/run/kfn"
+)
+
+// podEnv are the environment variables which can be used by the pod
+// properties.
+var podEnv = []corev1.EnvVar{
+	{
+		Name: "POD_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		},
+	},
+	{
+		Name: "POD_NAMESPACE",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		},
+	},
+}
+
+// IsStatefulSet returns true if the Function runs as a StatefulSet. A
+// Function with state always does, and so does an exactly-once Function as
+// its transactional ids are derived from the stable names of the pods.
+func IsStatefulSet(function *kfnv1alpha1.Function) bool {
+	return function.Spec.WorkloadType == kfnv1alpha1.StatefulSetWorkload || function.Spec.State != nil || function.Spec.ProcessingGuarantee == kfnv1alpha1.ExactlyOnce
+}
+
+// PodProperties returns the properties whose value is only known in the pod,
+// by full property name. The values reference the environment variables of
+// the pod, e.g. ${POD_NAME}, and are expanded by the shell when the
+// container starts.
+func PodProperties(function *kfnv1alpha1.Function) map[string]string {
+	props := make(map[string]string)
+
+	// The name of a pod of a StatefulSet is stable so it identifies the
+	// pod as a static member of the consumer group
+	if IsStatefulSet(function) && !hasConfig(function.Spec.ConsumerConfig, "group.instance.id") {
+		props["consumer.group.instance.id"] = "${POD_NAME}"
+	}
+
+	// Each pod needs its own transactional id, which survives a restart of
+	// the pod so the transactions of the previous instance are fenced
+	if function.Spec.ProcessingGuarantee == kfnv1alpha1.ExactlyOnce {
+		props["producer.transactional.id"] = TransactionalIDPrefix(function) + "${POD_NAME}"
+	}
+
+	clientIDPodProperties(function, props)
+	rackPodProperties(function, props)
+	schemaRegistryPodProperties(function, props)
+	configFromPodProperties(function, props)
+
+	return props
+}
+
+// newPodTemplate returns the pod template running the Function with the
+// configuration file of the ConfigMap and the runtime of the configuration.
+func newPodTemplate(function *kfnv1alpha1.Function, config *FunctionConfig, configMap *corev1.ConfigMap, labels map[string]string) corev1.PodTemplateSpec {
+	runtime := &config.Runtime
+
+	annotations := map[string]string{
+		ConfigHashAnnotation: Hash(configMap),
+	}
+
+	if restartedAt, ok := function.Annotations[kfn.RestartedAtAnnotation]; ok {
+		annotations[kfn.RestartedAtAnnotation] = restartedAt
+	}
+
+	if sourcesHash, ok := function.Annotations[SourcesHashAnnotation]; ok {
+		annotations[SourcesHashAnnotation] = sourcesHash
+	}
+
+	if function.Spec.RackAwareness != nil {
+		annotations[ZoneLabelsAnnotation] = zoneLabelsAnnotation(function)
+	}
+
+	container := corev1.Container{
+		Name:            "kfn-invoker",
+		Image:           function.Spec.Image,
+		ImagePullPolicy: imagePullPolicy(function.Spec.Image),
+		Command:         invokerCommand(runtime, runtime.ConfigPath+"/"+runtime.ConfigKey()),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "configuration",
+				MountPath: runtime.ConfigPath,
+			},
+		},
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: "configuration",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMap.Name,
+					},
+					Items: []corev1.KeyToPath{
+						{
+							Key:  runtime.ConfigKey(),
+							Path: runtime.ConfigKey(),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	props := PodProperties(function)
+
+	// The properties with placeholders resolved in the pod are rendered
+	// again when the container starts
+	runtimeProps, runtimeEnv := runtimeProperties(config)
+	for key, value := range runtimeProps {
+		props[key] = value
+	}
+
+	// The Functions without pod properties keep the original command so
+	// they are not rolled
+	if len(props) > 0 {
+		container.Env = append(append([]corev1.EnvVar{}, podEnv...), schemaRegistryEnv(function)...)
+		container.Env = append(container.Env, configFromEnv(function)...)
+		container.Env = append(container.Env, runtimeEnv...)
+		container.Command = podPropertiesCommand(function, runtime, props, container.Env)
+		if runtime.ConfigFormat != PropertiesFormat {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  PodPropertiesEnv,
+				Value: podPropertiesFile(runtime),
+			})
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "runtime",
+			MountPath: runtime.WorkPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "runtime",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
+	if function.Spec.RackAwareness != nil {
+		volume, mount := rackVolume()
+		volumes = append(volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+	}
+
+	fileVolumes, fileMounts := filesVolumes(function)
+	volumes = append(volumes, fileVolumes...)
+	container.VolumeMounts = append(container.VolumeMounts, fileMounts...)
+
+	containers := []corev1.Container{container}
+
+	// The Functions invoked over HTTP run next to the invoker
+	if IsHTTPInvocation(function) {
+		containers = append(containers, newHTTPContainer(function))
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: containers,
+			Volumes:    volumes,
+		},
+	}
+}
+
+func invokerCommand(runtime *Runtime, configFile string) []string {
+	return append(append([]string{}, runtime.Command...), configFile)
+}
+
+// podPropertiesFile returns the file holding the pod properties of the
+// runtimes whose configuration is not a properties file.
+func podPropertiesFile(runtime *Runtime) string {
+	return runtime.WorkPath + "/pod.properties"
+}
+
+// podPropertiesCommand copies the configuration file, appends the pod
+// properties and starts the invoker. The last value of a property wins
+// when the file is loaded. The pod properties of the other formats are
+// written in their own file, see PodPropertiesEnv. printf is used rather
+// than echo as some shells interpret the backslashes of the escaped
+// values. The environment variables read from Secrets and ConfigMaps are
+// escaped before being expanded in the properties, see escapeScript. The
+// command runs with /bin/sh so the image of the Function must provide a
+// shell: every pod has pod properties, at least its client.id.
+func podPropertiesCommand(function *kfnv1alpha1.Function, runtime *Runtime, props map[string]string, env []corev1.EnvVar) []string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file := runtime.WorkPath + "/" + runtime.ConfigKey()
+
+	propsFile := file
+	if runtime.ConfigFormat != PropertiesFormat {
+		propsFile = podPropertiesFile(runtime)
+	}
+
+	script := []string{
+		"set -e",
+		fmt.Sprintf("cp %s %s", shellQuote(runtime.ConfigPath+"/"+runtime.ConfigKey()), shellQuote(file)),
+	}
+	script = append(script, rackScript(function)...)
+	script = append(script, escapeScript(env)...)
+	for _, key := range keys {
+		script = append(script, fmt.Sprintf("printf '%%s\\n' \"%s=%s\" >> %s", key, props[key], shellQuote(propsFile)))
+	}
+	script = append(script, "exec "+strings.Join(quote(invokerCommand(runtime, file)), " "))
+
+	return []string{"/bin/sh", "-c", strings.Join(script, "\n")}
+}
+
+// escapeFunction escapes its argument as the value of a property: the
+// backslashes, the whitespaces and the line terminators are escaped and
+// the lines are joined. The characters outside of ASCII are written as
+// they are.
+const escapeFunction = `kfn_escape() { printf '%s\n' "$1" | sed -e 's/\\/\\\\/g' -e 's/ /\\ /g' -e "s/$(printf '\t')/\\\\t/g" -e "s/$(printf '\r')/\\\\r/g" -e "s/$(printf '\f')/\\\\f/g" -e '$!s/$/\\n/' | tr -d '\n'; }`
+
+// escapeScript escapes the environment variables read from Secrets and
+// ConfigMaps, whose values are only known when the container starts, so
+// they can be expanded in the pod properties as they are.
+func escapeScript(env []corev1.EnvVar) []string {
+	script := []string{}
+	for _, variable := range env {
+		if variable.ValueFrom == nil || (variable.ValueFrom.SecretKeyRef == nil && variable.ValueFrom.ConfigMapKeyRef == nil) {
+			continue
+		}
+		script = append(script, fmt.Sprintf("%s=$(kfn_escape \"$%s\")", variable.Name, variable.Name))
+	}
+	if len(script) == 0 {
+		return nil
+	}
+	return append([]string{escapeFunction}, script...)
+}
+
+func quote(args []string) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		result[i] = shellQuote(arg)
+	}
+	return result
+}
+
+// shellQuote quotes the argument for the shell. A single quote can't be
+// escaped within single quotes so it closes the quoted string, is escaped
+// and opens a new one.
+func shellQuote(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+func hasConfig(config *map[string]string, key string) bool {
+	if config == nil {
+		return false
+	}
+	_, ok := (*config)[key]
+	return ok
+}
+
+// imagePullPolicy pulls the mutable tags each time a pod starts. An image
+// pinned by digest can't change.
+func imagePullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+	return corev1.PullAlways
+}
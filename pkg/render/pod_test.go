@@ -0,0 +1,153 @@
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/dajac/kfn/pkg/properties"
+)
+
+// TestPodPropertiesCommandEscapesSecrets runs the command of the container
+// with a Secret which is not a valid properties value and reads the
+// properties file back.
+func TestPodPropertiesCommandEscapesSecrets(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir, err := ioutil.TempDir("", "kfn-pod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runtime := &Runtime{
+		Command:      []string{"true"},
+		ConfigFormat: PropertiesFormat,
+		ConfigPath:   filepath.Join(dir, "config"),
+		WorkPath:     filepath.Join(dir, "work"),
+	}
+	for _, path := range []string{runtime.ConfigPath, runtime.WorkPath} {
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(runtime.ConfigPath, runtime.ConfigKey()), []byte("function.class=Copy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer := map[string]string{
+		"sasl.jaas.config": `PlainLoginModule required password="${secret:credentials/password}";`,
+		"ssl.key.password": "${secret:credentials/password}",
+	}
+	function := newRenderFunction()
+	function.Spec.ConsumerConfig = &consumer
+	props, env := runtimeProperties(NewFunctionConfig(&FunctionDefaultConfig{}, function))
+	if len(env) != 1 {
+		t.Fatalf("env = %v, want one Secret", env)
+	}
+
+	secrets := []string{
+		"secret",
+		"  leading spaces",
+		`back\slash and $dollar "quotes" 'single' ` + "`tick`",
+		"several\nlines\r\nand\ttabs\f\n",
+		"=:#!",
+	}
+
+	for _, secret := range secrets {
+		command := podPropertiesCommand(function, runtime, props, env)
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Env = append(os.Environ(), env[0].Name+"="+secret)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%q: %s: %s", secret, err, output)
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(runtime.WorkPath, runtime.ConfigKey()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := properties.Parse(string(content))
+		if err != nil {
+			t.Fatalf("%q: %s", secret, err)
+		}
+
+		if got := parsed["consumer.ssl.key.password"]; got != secret {
+			t.Errorf("ssl.key.password = %q, want %q", got, secret)
+		}
+		if got, want := parsed["consumer.sasl.jaas.config"], `PlainLoginModule required password="`+secret+`";`; got != want {
+			t.Errorf("sasl.jaas.config = %q, want %q", got, want)
+		}
+		if parsed["function.class"] != "Copy" {
+			t.Errorf("the properties of the ConfigMap must be kept, got %v", parsed)
+		}
+	}
+}
+
+// TestPodPropertiesCommandQuotesPaths runs the command of the container with
+// paths and arguments holding spaces and single quotes.
+func TestPodPropertiesCommandQuotesPaths(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir, err := ioutil.TempDir("", "kfn-pod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "it's the output")
+	runtime := &Runtime{
+		Command:      []string{"sh", "-c", `cat "$1" > "$0"`, output},
+		ConfigFormat: PropertiesFormat,
+		ConfigPath:   filepath.Join(dir, "the function's config"),
+		WorkPath:     filepath.Join(dir, "work $HOME"),
+	}
+	for _, path := range []string{runtime.ConfigPath, runtime.WorkPath} {
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(runtime.ConfigPath, runtime.ConfigKey()), []byte("function.class=Copy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	function := newRenderFunction()
+	command := podPropertiesCommand(function, runtime, PodProperties(function), nil)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "POD_NAME=copy-0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("unexpected error %q: %s", err, out)
+	}
+
+	content, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := properties.Parse(string(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["function.class"] != "Copy" || props["consumer.client.id"] != "copy-0" {
+		t.Errorf("unexpected properties %v", props)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":           "''",
+		"/etc/kfn":   "'/etc/kfn'",
+		"it's":       `'it'\''s'`,
+		"$HOME `ls`": "'$HOME `ls`'",
+	}
+
+	for arg, expected := range tests {
+		if got := shellQuote(arg); got != expected {
+			t.Errorf("shellQuote(%q) = %s, want %s", arg, got, expected)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// Profile is a set of consumer and producer properties tuned for a
+// workload.
+type Profile struct {
+	Consumer map[string]string
+	Producer map[string]string
+}
+
+// builtinProfiles are the profiles available without FunctionProfile.
+var builtinProfiles = map[string]Profile{
+	"low-latency": {
+		Consumer: map[string]string{
+			"fetch.min.bytes":   "1",
+			"fetch.max.wait.ms": "10",
+			"max.poll.records":  "100",
+		},
+		Producer: map[string]string{
+			"linger.ms":        "0",
+			"batch.size":       "16384",
+			"compression.type": "none",
+		},
+	},
+	"balanced": {
+		Consumer: map[string]string{
+			"fetch.min.bytes":   "1024",
+			"fetch.max.wait.ms": "100",
+			"max.poll.records":  "500",
+		},
+		Producer: map[string]string{
+			"linger.ms":        "5",
+			"batch.size":       "65536",
+			"compression.type": "snappy",
+		},
+	},
+	"high-throughput": {
+		Consumer: map[string]string{
+			"fetch.min.bytes":           "65536",
+			"fetch.max.wait.ms":         "500",
+			"max.poll.records":          "2000",
+			"max.partition.fetch.bytes": "4194304",
+		},
+		Producer: map[string]string{
+			"linger.ms":        "50",
+			"batch.size":       "262144",
+			"compression.type": "lz4",
+			"buffer.memory":    "67108864",
+		},
+	},
+}
+
+// NewProfiles returns the profiles defined by the FunctionProfiles.
+func NewProfiles(functionProfiles []*kfnv1alpha1.FunctionProfile) map[string]Profile {
+	profiles := make(map[string]Profile)
+
+	for _, functionProfile := range functionProfiles {
+		profile := Profile{
+			Consumer: make(map[string]string),
+			Producer: make(map[string]string),
+		}
+
+		if functionProfile.Spec.ConsumerConfig != nil {
+			copyWithPrefix(*functionProfile.Spec.ConsumerConfig, profile.Consumer)
+		}
+
+		if functionProfile.Spec.ProducerConfig != nil {
+			copyWithPrefix(*functionProfile.Spec.ProducerConfig, profile.Producer)
+		}
+
+		profiles[functionProfile.Name] = profile
+	}
+
+	return profiles
+}
+
+// ResolveProfile returns the profile with the given name. The profiles of
+// the default configuration take precedence over the built-in ones.
+func ResolveProfile(defaultConfig *FunctionDefaultConfig, name string) (*Profile, error) {
+	if profile, ok := defaultConfig.Profiles[name]; ok {
+		return &profile, nil
+	}
+
+	if profile, ok := builtinProfiles[name]; ok {
+		return &profile, nil
+	}
+
+	names := make([]string, 0, len(builtinProfiles)+len(defaultConfig.Profiles))
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	for name := range defaultConfig.Profiles {
+		if _, ok := builtinProfiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return nil, fmt.Errorf("unknown profile %q: must be one of %s", name, strings.Join(names, ", "))
+}
+
+// ValidateProfile checks that the profile of the Function exists.
+func ValidateProfile(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	if function.Spec.Profile == "" {
+		return nil
+	}
+
+	_, err := ResolveProfile(defaultConfig, function.Spec.Profile)
+	return err
+}
+
+// setProfile sets the properties of the profile of the Function. An
+// unknown profile is rejected by ValidateProfile.
+func (cfg *FunctionConfig) setProfile(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) {
+	if function.Spec.Profile == "" {
+		return
+	}
+
+	profile, err := ResolveProfile(defaultConfig, function.Spec.Profile)
+	if err != nil {
+		return
+	}
+
+	cfg.overrideConsumerProperties(profile.Consumer)
+	cfg.overrideProducerProperties(profile.Producer)
+}
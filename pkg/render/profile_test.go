@@ -0,0 +1,99 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestProfile(t *testing.T) {
+	consumer := map[string]string{"max.poll.records": "50"}
+	function := newRenderFunction()
+	function.Spec.Profile = "high-throughput"
+	function.Spec.ConsumerConfig = &consumer
+
+	defaultConfig := &FunctionDefaultConfig{
+		Producer: map[string]string{"linger.ms": "1", "acks": "all"},
+	}
+	props := renderedProperties(t, defaultConfig, function)
+
+	// The profile overrides the default configuration and is overridden
+	// by the configuration of the Function
+	expected := map[string]string{
+		"producer.linger.ms":        "50",
+		"producer.acks":             "all",
+		"consumer.fetch.min.bytes":  "65536",
+		"consumer.max.poll.records": "50",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("%s = %q, want %q", key, props[key], value)
+		}
+	}
+}
+
+func TestNewProfiles(t *testing.T) {
+	consumer := map[string]string{"max.poll.records": "1"}
+	defaultConfig := &FunctionDefaultConfig{
+		Profiles: NewProfiles([]*kfnv1alpha1.FunctionProfile{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "balanced"},
+				Spec:       kfnv1alpha1.FunctionProfileSpec{ConsumerConfig: &consumer},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+			},
+		}),
+	}
+
+	// A FunctionProfile overrides the built-in profile of the same name
+	profile, err := ResolveProfile(defaultConfig, "balanced")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Consumer["max.poll.records"] != "1" || len(profile.Producer) != 0 {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if _, err := ResolveProfile(defaultConfig, "custom"); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+
+	_, err = ResolveProfile(defaultConfig, "fast")
+	expected := `unknown profile "fast": must be one of balanced, custom, high-throughput, low-latency`
+	if err == nil || err.Error() != expected {
+		t.Errorf("error = %v, want %q", err, expected)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		profile string
+		err     string
+	}{
+		{"", ""},
+		{"low-latency", ""},
+		{"unknown", `unknown profile "unknown"`},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.Profile = test.profile
+
+		err := ValidateProfile(&FunctionDefaultConfig{}, function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %q", test.profile, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: expected an error", test.profile)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: error %q does not contain %q", test.profile, err.Error(), test.err)
+		}
+	}
+}
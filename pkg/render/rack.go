@@ -0,0 +1,127 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	// ZoneAnnotation is the annotation of the pods holding the zone of
+	// their node. It is set by the operator once the pod is scheduled,
+	// to NoZone if the node has no zone.
+	ZoneAnnotation = "kfn.dajac.io/zone"
+
+	// NoZone is the zone of the pods whose node has none of the zone
+	// labels.
+	NoZone = "none"
+
+	// ZoneLabelsAnnotation is the annotation of the pods holding the
+	// comma separated labels of the nodes giving their zone. The operator
+	// only sets the zone of the pods having it.
+	ZoneLabelsAnnotation = "kfn.dajac.io/zone-labels"
+
+	// podInfoPath holds the zone of the pod, read from its annotation
+	// with the downward API.
+	podInfoPath = "/etc/kfn/pod"
+
+	// zoneWaitSeconds is how long the container waits for the zone of
+	// its pod before starting without client.rack.
+	zoneWaitSeconds = 60
+)
+
+// DefaultZoneLabels are the labels of the nodes giving their zone, by
+// order of preference.
+var DefaultZoneLabels = []string{
+	"topology.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/zone",
+}
+
+// ZoneLabels returns the labels of the nodes giving the zone of the pods
+// of the Function.
+func ZoneLabels(function *kfnv1alpha1.Function) []string {
+	if rack := function.Spec.RackAwareness; rack != nil && rack.NodeLabel != "" {
+		return []string{rack.NodeLabel}
+	}
+	return DefaultZoneLabels
+}
+
+// NodeZone returns the zone of the node or NoZone.
+func NodeZone(node *corev1.Node, labels []string) string {
+	for _, label := range labels {
+		if zone := node.Labels[label]; zone != "" {
+			return zone
+		}
+	}
+	return NoZone
+}
+
+// clientIDPodProperties names the clients of each pod after the pod so the
+// brokers can tell the pods apart in their metrics and quotas.
+func clientIDPodProperties(function *kfnv1alpha1.Function, props map[string]string) {
+	if !hasConfig(function.Spec.ConsumerConfig, "client.id") {
+		props["consumer.client.id"] = "${POD_NAME}"
+	}
+
+	if !hasConfig(function.Spec.ProducerConfig, "client.id") {
+		props["producer.client.id"] = "${POD_NAME}"
+	}
+}
+
+// rackPodProperties sets the rack of the consumer to the zone read by
+// rackScript.
+func rackPodProperties(function *kfnv1alpha1.Function, props map[string]string) {
+	if function.Spec.RackAwareness != nil && !hasConfig(function.Spec.ConsumerConfig, "client.rack") {
+		props["consumer.client.rack"] = "${ZONE}"
+	}
+}
+
+// rackScript waits for the zone of the pod, which is only known once the
+// pod is scheduled, and reads it in ZONE.
+func rackScript(function *kfnv1alpha1.Function) []string {
+	if function.Spec.RackAwareness == nil {
+		return nil
+	}
+
+	file := podInfoPath + "/zone"
+
+	return []string{
+		fmt.Sprintf("i=0; while [ ! -s %s ] && [ $i -lt %d ]; do sleep 1; i=$((i+1)); done", file, zoneWaitSeconds),
+		fmt.Sprintf("ZONE=$(cat %s 2>/dev/null || true)", file),
+		fmt.Sprintf("if [ \"$ZONE\" = \"%s\" ]; then ZONE=; fi", NoZone),
+	}
+}
+
+// rackVolume returns the volume exposing the zone annotation of the pod.
+func rackVolume() (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: "pod-info",
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{
+						Path: "zone",
+						FieldRef: &corev1.ObjectFieldSelector{
+							FieldPath: fmt.Sprintf("metadata.annotations['%s']", ZoneAnnotation),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mount := corev1.VolumeMount{
+		Name:      "pod-info",
+		MountPath: podInfoPath,
+		ReadOnly:  true,
+	}
+
+	return volume, mount
+}
+
+func zoneLabelsAnnotation(function *kfnv1alpha1.Function) string {
+	return strings.Join(ZoneLabels(function), ",")
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestClientIDPodProperties(t *testing.T) {
+	function := newRenderFunction()
+	props := PodProperties(function)
+	if props["consumer.client.id"] != "${POD_NAME}" || props["producer.client.id"] != "${POD_NAME}" {
+		t.Errorf("the clients must be named after the pod, got %v", props)
+	}
+
+	// The client id of the configuration wins
+	producer := map[string]string{"client.id": "copy"}
+	function.Spec.ProducerConfig = &producer
+	props = PodProperties(function)
+	if _, ok := props["producer.client.id"]; ok || props["consumer.client.id"] != "${POD_NAME}" {
+		t.Errorf("only the consumer must be named after the pod, got %v", props)
+	}
+}
+
+func TestRackAwareness(t *testing.T) {
+	function := newRenderFunction()
+	if _, ok := PodProperties(function)["consumer.client.rack"]; ok {
+		t.Errorf("client.rack must only be set with rack awareness")
+	}
+
+	function.Spec.RackAwareness = &kfnv1alpha1.RackAwarenessSpec{}
+	if rack := PodProperties(function)["consumer.client.rack"]; rack != "${ZONE}" {
+		t.Errorf("client.rack = %q, want ${ZONE}", rack)
+	}
+
+	template := Render(&FunctionDefaultConfig{}, function).Deployement.Spec.Template
+	if labels := template.Annotations[ZoneLabelsAnnotation]; labels != "topology.kubernetes.io/zone,failure-domain.beta.kubernetes.io/zone" {
+		t.Errorf("zone labels = %q", labels)
+	}
+
+	found := false
+	for _, volume := range template.Spec.Volumes {
+		if volume.DownwardAPI != nil && volume.DownwardAPI.Items[0].FieldRef.FieldPath == "metadata.annotations['kfn.dajac.io/zone']" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the zone annotation must be mounted, got %+v", template.Spec.Volumes)
+	}
+
+	function.Spec.RackAwareness.NodeLabel = "example.com/zone"
+	if labels := ZoneLabels(function); len(labels) != 1 || labels[0] != "example.com/zone" {
+		t.Errorf("zone labels = %v, want [example.com/zone]", labels)
+	}
+}
+
+func TestNodeZone(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node",
+			Labels: map[string]string{
+				"failure-domain.beta.kubernetes.io/zone": "eu-west-1a",
+			},
+		},
+	}
+
+	if zone := NodeZone(node, DefaultZoneLabels); zone != "eu-west-1a" {
+		t.Errorf("zone = %s, want eu-west-1a", zone)
+	}
+
+	node.Labels["topology.kubernetes.io/zone"] = "eu-west-1b"
+	if zone := NodeZone(node, DefaultZoneLabels); zone != "eu-west-1b" {
+		t.Errorf("zone = %s, want eu-west-1b", zone)
+	}
+
+	if zone := NodeZone(node, []string{"example.com/zone"}); zone != NoZone {
+		t.Errorf("zone = %s, want %s", zone, NoZone)
+	}
+}
@@ -0,0 +1,95 @@
+// Package render renders the Kubernetes resources created by the operator for
+// a Function. It does not need a cluster so it can be used to review the
+// resources before applying a Function.
+package render
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	// PropertiesKey is the key of the properties file in the ConfigMap.
+	PropertiesKey = "function.properties"
+
+	// ConfigHashAnnotation is the pod template annotation holding the hash
+	// of the properties file. A change of the hash rolls the Deployment.
+	ConfigHashAnnotation = "kfn.dajac.io/config-hash"
+)
+
+// Resources are the resources created by the operator for a Function.
+type Resources struct {
+	Config    *FunctionConfig
+	ConfigMap *corev1.ConfigMap
+
+	// Deployement or StatefulSet runs the Function depending on its
+	// workload type. The other one is nil.
+	Deployement *appsv1.Deployment
+	StatefulSet *appsv1.StatefulSet
+
+	// Shadow are the resources of the shadow of the Function, if any.
+	Shadow *Resources
+}
+
+// Objects returns the Kubernetes objects, including the ones of the shadow.
+func (r *Resources) Objects() []interface{} {
+	objects := []interface{}{r.ConfigMap}
+	if r.Deployement != nil {
+		objects = append(objects, r.Deployement)
+	}
+	if r.StatefulSet != nil {
+		objects = append(objects, r.StatefulSet)
+	}
+	if r.Shadow != nil {
+		objects = append(objects, r.Shadow.Objects()...)
+	}
+	return objects
+}
+
+// Render renders all the resources of the Function.
+func Render(defaultConfig *FunctionDefaultConfig, function *v1alpha1.Function) *Resources {
+	resources := renderNamed(NewFunctionConfig(defaultConfig, function), function, function.Name, map[string]string{
+		"function": function.Name,
+	})
+
+	if IsStatefulSet(function) {
+		statefulSet := NewStatefulSet(function, resources.Config, resources.ConfigMap)
+		statefulSet.TypeMeta = metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+		}
+
+		resources.Deployement = nil
+		resources.StatefulSet = statefulSet
+	}
+
+	if function.Spec.Shadow != nil {
+		shadow := NewShadowFunction(function)
+		resources.Shadow = renderNamed(NewFunctionConfig(defaultConfig, shadow), shadow, ShadowName(function), ShadowLabels(function))
+	}
+
+	return resources
+}
+
+func renderNamed(config *FunctionConfig, function *v1alpha1.Function, name string, labels map[string]string) *Resources {
+	configMap := NewNamedConfigMap(function, config, name)
+	configMap.TypeMeta = metav1.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+	}
+
+	deployement := NewNamedDeployement(function, config, configMap, name, labels)
+	deployement.TypeMeta = metav1.TypeMeta{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+	}
+
+	return &Resources{
+		Config:      config,
+		ConfigMap:   configMap,
+		Deployement: deployement,
+	}
+}
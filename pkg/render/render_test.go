@@ -0,0 +1,132 @@
+package render
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/properties"
+)
+
+func newRenderFunction() *kfnv1alpha1.Function {
+	return &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "copy", UID: "copy-uid"},
+		Spec: kfnv1alpha1.FunctionSpec{
+			Image:                  "dajac/kfn-examples:0.1.0",
+			Replicas:               2,
+			Class:                  "io.dajac.kfn.examples.Copy",
+			Input:                  "kfn.source",
+			InputKeyDeserializer:   "string",
+			InputValueDeserializer: "long",
+			Output:                 "kfn.destination",
+			OutputKeySerializer:    "string",
+			OutoutValueSerializer:  "bytes",
+		},
+	}
+}
+
+// renderedProperties renders the Function and parses its properties file.
+func renderedProperties(t *testing.T, defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) map[string]string {
+	props, err := properties.Parse(ConfigData(Render(defaultConfig, function).ConfigMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
+
+func TestRender(t *testing.T) {
+	function := newRenderFunction()
+	resources := Render(&FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+
+	if resources.StatefulSet != nil || resources.Shadow != nil {
+		t.Errorf("only a ConfigMap and a Deployment are expected")
+	}
+	if len(resources.Objects()) != 2 {
+		t.Errorf("got %d objects, want 2", len(resources.Objects()))
+	}
+
+	configMap := resources.ConfigMap
+	if configMap.Name != "copy" || configMap.Namespace != "default" || configMap.Kind != "ConfigMap" {
+		t.Errorf("unexpected ConfigMap %s/%s of kind %s", configMap.Namespace, configMap.Name, configMap.Kind)
+	}
+	if !metav1.IsControlledBy(configMap, function) {
+		t.Errorf("the ConfigMap must be owned by the Function")
+	}
+
+	deployment := resources.Deployement
+	if deployment.Name != "copy" || *deployment.Spec.Replicas != 2 || deployment.Kind != "Deployment" {
+		t.Errorf("unexpected Deployment %s with %d replicas", deployment.Name, *deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Selector.MatchLabels["function"] != "copy" || deployment.Spec.Template.Labels["function"] != "copy" {
+		t.Errorf("the pods must be selected by the name of the Function")
+	}
+
+	template := deployment.Spec.Template
+	if template.Annotations[ConfigHashAnnotation] != Hash(configMap) {
+		t.Errorf("the pods must be annotated with the hash of the properties")
+	}
+	container := template.Spec.Containers[0]
+	if container.Image != "dajac/kfn-examples:0.1.0" {
+		t.Errorf("image = %s", container.Image)
+	}
+
+	props, err := properties.Parse(ConfigData(configMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"function.name":               "copy",
+		"function.class":              "io.dajac.kfn.examples.Copy",
+		"function.input":              "kfn.source",
+		"function.output":             "kfn.destination",
+		"consumer.bootstrap.servers":  "kafka:9092",
+		"consumer.group.id":           "copy",
+		"consumer.key.deserializer":   "org.apache.kafka.common.serialization.StringDeserializer",
+		"consumer.value.deserializer": "org.apache.kafka.common.serialization.LongDeserializer",
+		"producer.bootstrap.servers":  "kafka:9092",
+		"producer.key.serializer":     "org.apache.kafka.common.serialization.StringSerializer",
+		"producer.value.serializer":   "org.apache.kafka.common.serialization.ByteArraySerializer",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("%s = %q, want %q", key, props[key], value)
+		}
+	}
+}
+
+func TestRenderConfigPrecedence(t *testing.T) {
+	function := newRenderFunction()
+	consumer := map[string]string{"max.poll.records": "10", "group.id": "custom"}
+	function.Spec.ConsumerConfig = &consumer
+
+	defaultConfig := &FunctionDefaultConfig{
+		KafkaBoostrap: "kafka:9092",
+		Consumer:      map[string]string{"max.poll.records": "500", "fetch.min.bytes": "1"},
+	}
+
+	props := renderedProperties(t, defaultConfig, function)
+	if props["consumer.max.poll.records"] != "10" || props["consumer.group.id"] != "custom" {
+		t.Errorf("the configuration of the Function must override the defaults, got %v", props)
+	}
+	if props["consumer.fetch.min.bytes"] != "1" {
+		t.Errorf("the defaults must be kept, got %v", props)
+	}
+}
+
+func TestRenderHash(t *testing.T) {
+	function := newRenderFunction()
+	hash := Hash(Render(&FunctionDefaultConfig{}, function).ConfigMap)
+
+	scaled := function.DeepCopy()
+	scaled.Spec.Replicas = 5
+	if Hash(Render(&FunctionDefaultConfig{}, scaled).ConfigMap) != hash {
+		t.Errorf("scaling the Function must not change the hash of its properties")
+	}
+
+	updated := function.DeepCopy()
+	updated.Spec.Output = "kfn.other"
+	if Hash(Render(&FunctionDefaultConfig{}, updated).ConfigMap) == hash {
+		t.Errorf("a new output must change the hash of the properties")
+	}
+}
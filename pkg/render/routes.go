@@ -0,0 +1,260 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/builtin"
+)
+
+const maxTopicLength = 249
+
+var (
+	topicPattern     = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	routeNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// Outputs returns the topics the Function produces to: its output, which
+// is the default route, and the outputs of its routes.
+func Outputs(function *kfnv1alpha1.Function) []string {
+	outputs := []string{}
+	seen := make(map[string]bool)
+
+	add := func(topic string) {
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			outputs = append(outputs, topic)
+		}
+	}
+
+	add(function.Spec.Output)
+	for _, route := range function.Spec.Routes {
+		add(route.Output)
+	}
+
+	return outputs
+}
+
+// setRoutes sets the properties of the routes. They are listed by name in
+// function.routes, in order, and each route is described by the
+// function.route.<name>.* properties.
+func (cfg *FunctionConfig) setRoutes(function *kfnv1alpha1.Function) {
+	if len(function.Spec.Routes) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(function.Spec.Routes))
+
+	for _, route := range function.Spec.Routes {
+		names = append(names, route.Name)
+		prefix := "route." + route.Name + "."
+
+		if route.Header != nil {
+			cfg.Function[prefix+"header.name"] = route.Header.Name
+			if route.Header.Value != "" {
+				cfg.Function[prefix+"header.value"] = route.Header.Value
+			}
+		}
+
+		if route.KeyPattern != "" {
+			cfg.Function[prefix+"key.pattern"] = route.KeyPattern
+		}
+
+		if route.Expression != "" {
+			cfg.Function[prefix+"expression"] = route.Expression
+		}
+
+		keySerializer := route.OutputKeySerializer
+		if keySerializer == "" {
+			keySerializer = function.Spec.OutputKeySerializer
+		}
+
+		valueSerializer := route.OutputValueSerializer
+		if valueSerializer == "" {
+			valueSerializer = function.Spec.OutoutValueSerializer
+		}
+
+		cfg.Function[prefix+"output"] = route.Output
+		cfg.Function[prefix+"key.serializer"] = getSerializer(keySerializer)
+		cfg.Function[prefix+"value.serializer"] = getSerializer(valueSerializer)
+	}
+
+	cfg.Function["routes"] = strings.Join(names, ",")
+}
+
+// ValidateRoutes checks the routes of the Function: each route has a single
+// valid condition and a topic, and no route is shadowed by a previous one,
+// i.e. never matches a record.
+// The topics are only checked to be valid topic names: the operator does
+// not connect to Kafka so it can't check that they exist.
+func ValidateRoutes(function *kfnv1alpha1.Function) error {
+	routes := function.Spec.Routes
+	if len(routes) == 0 {
+		return nil
+	}
+
+	if spec := function.Spec.Builtin; spec != nil && spec.Route != "" {
+		return fmt.Errorf("routes can't be used with a builtin route")
+	}
+
+	if err := validateTopic("output", function.Spec.Output); err != nil {
+		return fmt.Errorf("routes require the default route: %s", err.Error())
+	}
+
+	env, err := builtin.NewEnv(builtin.Record{
+		Key:   builtin.SerdeType(function.Spec.InputKeyDeserializer),
+		Value: builtin.SerdeType(function.Spec.InputValueDeserializer),
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+
+	for i, route := range routes {
+		if !routeNamePattern.MatchString(route.Name) {
+			return fmt.Errorf("invalid name %q of route %d: must consist of lower case alphanumeric characters or '-'", route.Name, i)
+		}
+
+		if names[route.Name] {
+			return fmt.Errorf("duplicate route %s", route.Name)
+		}
+		names[route.Name] = true
+
+		if err := validateRouteCondition(env, &route); err != nil {
+			return fmt.Errorf("invalid route %s: %s", route.Name, err.Error())
+		}
+
+		if err := validateTopic("output", route.Output); err != nil {
+			return fmt.Errorf("invalid route %s: %s", route.Name, err.Error())
+		}
+
+		for _, previous := range routes[:i] {
+			if shadows(&previous, &route) {
+				return fmt.Errorf("route %s is shadowed by route %s", route.Name, previous.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateRouteCondition(env *cel.Env, route *kfnv1alpha1.RouteSpec) error {
+	conditions := 0
+
+	if route.Header != nil {
+		conditions++
+		if route.Header.Name == "" {
+			return fmt.Errorf("header requires a name")
+		}
+	}
+
+	if route.KeyPattern != "" {
+		conditions++
+		if _, err := regexp.Compile(route.KeyPattern); err != nil {
+			return fmt.Errorf("invalid key pattern %q: %s", route.KeyPattern, err.Error())
+		}
+	}
+
+	if route.Expression != "" {
+		conditions++
+		if err := builtin.Check(env, route.Expression, cel.BoolType); err != nil {
+			return fmt.Errorf("invalid expression: %s", err.Error())
+		}
+	}
+
+	if conditions != 1 {
+		return fmt.Errorf("exactly one of header, keyPattern and expression must be set")
+	}
+
+	return nil
+}
+
+func validateTopic(field string, topic string) error {
+	if topic == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+
+	if len(topic) > maxTopicLength || !topicPattern.MatchString(topic) || topic == "." || topic == ".." {
+		return fmt.Errorf("invalid %s topic %q", field, topic)
+	}
+
+	return nil
+}
+
+// shadows returns true if all the records matched by the route are matched
+// by the previous one. Only the conditions which are equal, or which match
+// all the records, are detected.
+func shadows(previous *kfnv1alpha1.RouteSpec, route *kfnv1alpha1.RouteSpec) bool {
+	if matchesAll(previous) {
+		return true
+	}
+
+	switch {
+	case previous.Header != nil && route.Header != nil:
+		return previous.Header.Name == route.Header.Name && (previous.Header.Value == "" || previous.Header.Value == route.Header.Value)
+	case previous.KeyPattern != "" && route.KeyPattern != "":
+		return previous.KeyPattern == route.KeyPattern
+	case previous.Expression != "" && route.Expression != "":
+		return normalizeExpression(previous.Expression) == normalizeExpression(route.Expression)
+	default:
+		return false
+	}
+}
+
+// matchesAll returns true if the condition of the route matches all the
+// records, e.g. the .* key pattern or the true expression.
+func matchesAll(route *kfnv1alpha1.RouteSpec) bool {
+	if route.Expression != "" {
+		return normalizeExpression(route.Expression) == "true"
+	}
+
+	if route.KeyPattern == "" {
+		return false
+	}
+
+	re, err := syntax.Parse(route.KeyPattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	re = re.Simplify()
+
+	// The anchors are implied as the pattern matches the whole key
+	if re.Op == syntax.OpConcat {
+		subs := re.Sub
+		for len(subs) > 0 && isAnchor(subs[0]) {
+			subs = subs[1:]
+		}
+		for len(subs) > 0 && isAnchor(subs[len(subs)-1]) {
+			subs = subs[:len(subs)-1]
+		}
+		if len(subs) != 1 {
+			return false
+		}
+		re = subs[0]
+	}
+
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+
+	return re.Op == syntax.OpStar && (re.Sub[0].Op == syntax.OpAnyChar || re.Sub[0].Op == syntax.OpAnyCharNotNL)
+}
+
+func isAnchor(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpEndLine, syntax.OpEndText:
+		return true
+	default:
+		return false
+	}
+}
+
+func normalizeExpression(expression string) string {
+	return strings.Join(strings.Fields(expression), "")
+}
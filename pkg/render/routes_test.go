@@ -0,0 +1,120 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestMatchesAll(t *testing.T) {
+	tests := []struct {
+		route    kfnv1alpha1.RouteSpec
+		expected bool
+	}{
+		{kfnv1alpha1.RouteSpec{KeyPattern: ".*"}, true},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "^.*$"}, true},
+		{kfnv1alpha1.RouteSpec{KeyPattern: `\A.*\z`}, true},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "(?s).*"}, true},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "(.*)"}, true},
+		{kfnv1alpha1.RouteSpec{KeyPattern: ".+"}, false},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, false},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "[a-z]*"}, false},
+		{kfnv1alpha1.RouteSpec{KeyPattern: "("}, false},
+		{kfnv1alpha1.RouteSpec{Expression: "true"}, true},
+		{kfnv1alpha1.RouteSpec{Expression: " true "}, true},
+		{kfnv1alpha1.RouteSpec{Expression: "value > 10"}, false},
+		{kfnv1alpha1.RouteSpec{Header: &kfnv1alpha1.HeaderMatch{Name: "region"}}, false},
+	}
+
+	for _, test := range tests {
+		if got := matchesAll(&test.route); got != test.expected {
+			t.Errorf("matchesAll(%+v) = %t, want %t", test.route, got, test.expected)
+		}
+	}
+}
+
+func TestShadows(t *testing.T) {
+	header := func(name string, value string) kfnv1alpha1.RouteSpec {
+		return kfnv1alpha1.RouteSpec{Header: &kfnv1alpha1.HeaderMatch{Name: name, Value: value}}
+	}
+
+	tests := []struct {
+		name     string
+		previous kfnv1alpha1.RouteSpec
+		route    kfnv1alpha1.RouteSpec
+		expected bool
+	}{
+		{"same header", header("region", "eu"), header("region", "eu"), true},
+		{"any value of the header", header("region", ""), header("region", "eu"), true},
+		{"other value of the header", header("region", "eu"), header("region", "us"), false},
+		{"specific value first", header("region", "eu"), header("region", ""), false},
+		{"other header", header("region", ""), header("zone", ""), false},
+		{"same key pattern", kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, true},
+		{"other key pattern", kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, kfnv1alpha1.RouteSpec{KeyPattern: "ab.*"}, false},
+		{"same expression", kfnv1alpha1.RouteSpec{Expression: "value > 10"}, kfnv1alpha1.RouteSpec{Expression: "value>10"}, true},
+		{"other expression", kfnv1alpha1.RouteSpec{Expression: "value > 10"}, kfnv1alpha1.RouteSpec{Expression: "value > 20"}, false},
+		{"all keys", kfnv1alpha1.RouteSpec{KeyPattern: ".*"}, header("region", "eu"), true},
+		{"true expression", kfnv1alpha1.RouteSpec{Expression: "true"}, kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, true},
+		{"other conditions", kfnv1alpha1.RouteSpec{KeyPattern: "a.*"}, header("region", "eu"), false},
+	}
+
+	for _, test := range tests {
+		if got := shadows(&test.previous, &test.route); got != test.expected {
+			t.Errorf("%s: shadows = %t, want %t", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	route := func(name string, keyPattern string) kfnv1alpha1.RouteSpec {
+		return kfnv1alpha1.RouteSpec{Name: name, KeyPattern: keyPattern, Output: "orders-" + name}
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		routes []kfnv1alpha1.RouteSpec
+		err    string
+	}{
+		{name: "no route"},
+		{name: "routes", output: "orders", routes: []kfnv1alpha1.RouteSpec{
+			route("eu", "eu-.*"),
+			{Name: "large", Expression: "value > 1000", Output: "orders-large"},
+			{Name: "priority", Header: &kfnv1alpha1.HeaderMatch{Name: "priority"}, Output: "orders-priority"},
+		}},
+		{name: "default route", routes: []kfnv1alpha1.RouteSpec{route("eu", "eu-.*")}, err: "routes require the default route"},
+		{name: "name", output: "orders", routes: []kfnv1alpha1.RouteSpec{route("EU", "eu-.*")}, err: "invalid name"},
+		{name: "duplicate", output: "orders", routes: []kfnv1alpha1.RouteSpec{route("eu", "eu-.*"), route("eu", "us-.*")}, err: "duplicate route eu"},
+		{name: "no condition", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "eu", Output: "orders-eu"}}, err: "exactly one of"},
+		{name: "two conditions", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "eu", KeyPattern: "eu-.*", Expression: "true", Output: "orders-eu"}}, err: "exactly one of"},
+		{name: "key pattern", output: "orders", routes: []kfnv1alpha1.RouteSpec{route("eu", "eu-(")}, err: "invalid key pattern"},
+		{name: "expression", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "large", Expression: "value", Output: "orders-large"}}, err: "invalid expression"},
+		{name: "header", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "eu", Header: &kfnv1alpha1.HeaderMatch{}, Output: "orders-eu"}}, err: "header requires a name"},
+		{name: "missing topic", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "eu", KeyPattern: "eu-.*"}}, err: "output is required"},
+		{name: "invalid topic", output: "orders", routes: []kfnv1alpha1.RouteSpec{{Name: "eu", KeyPattern: "eu-.*", Output: "orders/eu"}}, err: "invalid output topic"},
+		{name: "shadowed", output: "orders", routes: []kfnv1alpha1.RouteSpec{route("all", ".*"), route("eu", "eu-.*")}, err: "route eu is shadowed by route all"},
+	}
+
+	for _, test := range tests {
+		function := newBuiltinFunction(nil)
+		function.Spec.Output = test.output
+		function.Spec.Routes = test.routes
+
+		err := ValidateRoutes(function)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: expected an error", test.name)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q does not contain %q", test.name, err, test.err)
+		}
+	}
+
+	function := newBuiltinFunction(&kfnv1alpha1.BuiltinSpec{Route: `"orders"`})
+	function.Spec.Routes = []kfnv1alpha1.RouteSpec{route("eu", "eu-.*")}
+	if err := ValidateRoutes(function); err == nil {
+		t.Errorf("routes must be rejected with a builtin route")
+	}
+}
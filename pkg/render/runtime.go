@@ -0,0 +1,177 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	// DefaultRuntime is the runtime of the Functions without runtime.
+	DefaultRuntime = "java"
+
+	// PropertiesFormat writes the configuration as a Java properties file.
+	PropertiesFormat = "properties"
+
+	// JSONFormat writes the configuration as a JSON object with the
+	// function, consumer and producer objects.
+	JSONFormat = "json"
+
+	// JSONKey is the key of the JSON configuration in the ConfigMap.
+	JSONKey = "function.json"
+
+	// PodPropertiesEnv is the environment variable holding the path of the
+	// file with the properties only known in the pod, for the runtimes
+	// whose configuration is not a properties file.
+	PodPropertiesEnv = "KFN_POD_PROPERTIES"
+)
+
+// Runtime describes how the invoker of a Function is started.
+type Runtime struct {
+	// Command starts the invoker. The path of the configuration file is
+	// appended to it. It is started by /bin/sh, see podPropertiesCommand.
+	Command []string
+
+	// ConfigFormat is PropertiesFormat or JSONFormat.
+	ConfigFormat string
+
+	// ConfigPath is the directory where the ConfigMap is mounted.
+	ConfigPath string
+
+	// WorkPath is the writable directory where the configuration file is
+	// completed with the pod properties.
+	WorkPath string
+}
+
+// builtinRuntimes are the runtimes available without FunctionRuntime.
+var builtinRuntimes = map[string]Runtime{
+	"java": {
+		Command:      []string{"/usr/bin/java", "-cp", "/usr/lib/kfn/*", "io.dajac.kfn.invoker.FunctionInvoker"},
+		ConfigFormat: PropertiesFormat,
+		ConfigPath:   configurationPath,
+		WorkPath:     runtimePath,
+	},
+	"python": {
+		Command:      []string{"python3", "-m", "kfn.invoker"},
+		ConfigFormat: JSONFormat,
+		ConfigPath:   configurationPath,
+		WorkPath:     runtimePath,
+	},
+	"node": {
+		Command:      []string{"node", "/usr/lib/kfn/invoker.js"},
+		ConfigFormat: JSONFormat,
+		ConfigPath:   configurationPath,
+		WorkPath:     runtimePath,
+	},
+	"native": {
+		Command:      []string{"/usr/bin/kfn-invoker"},
+		ConfigFormat: PropertiesFormat,
+		ConfigPath:   configurationPath,
+		WorkPath:     runtimePath,
+	},
+}
+
+// ConfigKey returns the key of the configuration file in the ConfigMap.
+func (r *Runtime) ConfigKey() string {
+	if r.ConfigFormat == JSONFormat {
+		return JSONKey
+	}
+	return PropertiesKey
+}
+
+// NewRuntimes returns the runtimes defined by the FunctionRuntimes.
+func NewRuntimes(functionRuntimes []*kfnv1alpha1.FunctionRuntime) map[string]Runtime {
+	runtimes := make(map[string]Runtime)
+
+	for _, functionRuntime := range functionRuntimes {
+		runtime := Runtime{
+			Command:      append([]string{}, functionRuntime.Spec.Command...),
+			ConfigFormat: functionRuntime.Spec.ConfigFormat,
+			ConfigPath:   functionRuntime.Spec.ConfigPath,
+			WorkPath:     functionRuntime.Spec.WorkPath,
+		}
+
+		if runtime.ConfigFormat == "" {
+			runtime.ConfigFormat = PropertiesFormat
+		}
+
+		if runtime.ConfigPath == "" {
+			runtime.ConfigPath = configurationPath
+		}
+
+		if runtime.WorkPath == "" {
+			runtime.WorkPath = runtimePath
+		}
+
+		runtimes[functionRuntime.Name] = runtime
+	}
+
+	return runtimes
+}
+
+// ResolveRuntime returns the runtime of the Function. The runtimes of the
+// default configuration take precedence over the built-in ones.
+func ResolveRuntime(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) (*Runtime, error) {
+	name := function.Spec.Runtime
+	if name == "" {
+		name = DefaultRuntime
+	}
+
+	if runtime, ok := defaultConfig.Runtimes[name]; ok {
+		return &runtime, nil
+	}
+
+	if runtime, ok := builtinRuntimes[name]; ok {
+		return &runtime, nil
+	}
+
+	names := make([]string, 0, len(builtinRuntimes)+len(defaultConfig.Runtimes))
+	for name := range builtinRuntimes {
+		names = append(names, name)
+	}
+	for name := range defaultConfig.Runtimes {
+		if _, ok := builtinRuntimes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return nil, fmt.Errorf("unknown runtime %q: must be one of %s", name, strings.Join(names, ", "))
+}
+
+// ValidateRuntime checks that the runtime of the Function exists and can
+// start an invoker.
+func ValidateRuntime(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	runtime, err := ResolveRuntime(defaultConfig, function)
+	if err != nil {
+		return err
+	}
+
+	name := function.Spec.Runtime
+	if name == "" {
+		name = DefaultRuntime
+	}
+
+	if len(runtime.Command) == 0 {
+		return fmt.Errorf("runtime %q has no command", name)
+	}
+
+	if runtime.ConfigFormat != PropertiesFormat && runtime.ConfigFormat != JSONFormat {
+		return fmt.Errorf("runtime %q has an unknown config format %q: must be %s or %s", name, runtime.ConfigFormat, PropertiesFormat, JSONFormat)
+	}
+
+	return nil
+}
+
+// setRuntime sets the runtime of the Function. An unknown runtime is
+// rejected by ValidateRuntime so the default one is used meanwhile.
+func (cfg *FunctionConfig) setRuntime(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) {
+	runtime, err := ResolveRuntime(defaultConfig, function)
+	if err != nil {
+		runtime, _ = ResolveRuntime(defaultConfig, &kfnv1alpha1.Function{})
+	}
+
+	cfg.Runtime = *runtime
+}
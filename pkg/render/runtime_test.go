@@ -0,0 +1,135 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestResolveRuntime(t *testing.T) {
+	defaultConfig := &FunctionDefaultConfig{
+		Runtimes: NewRuntimes([]*kfnv1alpha1.FunctionRuntime{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "go"},
+				Spec:       kfnv1alpha1.FunctionRuntimeSpec{Command: []string{"/invoker"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "python"},
+				Spec: kfnv1alpha1.FunctionRuntimeSpec{
+					Command:      []string{"python3.12", "-m", "invoker"},
+					ConfigFormat: JSONFormat,
+					ConfigPath:   "/config",
+					WorkPath:     "/work",
+				},
+			},
+		}),
+	}
+
+	function := newRenderFunction()
+	runtime, err := ResolveRuntime(defaultConfig, function)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.Command[0] != "/usr/bin/java" || runtime.ConfigKey() != PropertiesKey {
+		t.Errorf("the default runtime must be java, got %+v", runtime)
+	}
+
+	// The defaults of a FunctionRuntime are the ones of the built-in runtimes
+	function.Spec.Runtime = "go"
+	runtime, _ = ResolveRuntime(defaultConfig, function)
+	expected := &Runtime{Command: []string{"/invoker"}, ConfigFormat: PropertiesFormat, ConfigPath: "/etc/kfn", WorkPath: "/var/run/kfn"}
+	if !reflect.DeepEqual(runtime, expected) {
+		t.Errorf("runtime = %+v, want %+v", runtime, expected)
+	}
+
+	// A FunctionRuntime overrides the built-in runtime of the same name
+	function.Spec.Runtime = "python"
+	runtime, _ = ResolveRuntime(defaultConfig, function)
+	if runtime.Command[0] != "python3.12" || runtime.ConfigKey() != JSONKey {
+		t.Errorf("unexpected runtime %+v", runtime)
+	}
+
+	function.Spec.Runtime = "ruby"
+	_, err = ResolveRuntime(defaultConfig, function)
+	if err == nil || err.Error() != `unknown runtime "ruby": must be one of go, java, native, node, python` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestValidateRuntime(t *testing.T) {
+	defaultConfig := &FunctionDefaultConfig{
+		Runtimes: map[string]Runtime{
+			"empty": {ConfigFormat: PropertiesFormat},
+			"yaml":  {Command: []string{"/invoker"}, ConfigFormat: "yaml"},
+		},
+	}
+
+	tests := []struct {
+		runtime string
+		err     string
+	}{
+		{"", ""},
+		{"node", ""},
+		{"empty", `runtime "empty" has no command`},
+		{"yaml", `unknown config format "yaml"`},
+		{"ruby", `unknown runtime "ruby"`},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.Runtime = test.runtime
+
+		err := ValidateRuntime(defaultConfig, function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %q", test.runtime, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: expected an error", test.runtime)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: error %q does not contain %q", test.runtime, err.Error(), test.err)
+		}
+	}
+}
+
+func TestRenderJSONRuntime(t *testing.T) {
+	function := newRenderFunction()
+	function.Spec.Runtime = "node"
+
+	resources := Render(&FunctionDefaultConfig{KafkaBoostrap: "kafka:9092"}, function)
+	if _, ok := resources.ConfigMap.Data[PropertiesKey]; ok {
+		t.Errorf("the configuration must only be written as JSON")
+	}
+
+	config := map[string]map[string]string{}
+	if err := json.Unmarshal([]byte(ConfigData(resources.ConfigMap)), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config["function"]["output"] != "kfn.destination" || config["consumer"]["bootstrap.servers"] != "kafka:9092" {
+		t.Errorf("unexpected configuration %v", config)
+	}
+
+	// The pod properties are written in their own file
+	container := resources.Deployement.Spec.Template.Spec.Containers[0]
+	script := container.Command[len(container.Command)-1]
+	if !strings.Contains(script, ">> '/var/run/kfn/pod.properties'") || !strings.HasSuffix(script, "exec 'node' '/usr/lib/kfn/invoker.js' '/var/run/kfn/function.json'") {
+		t.Errorf("unexpected command %s", script)
+	}
+
+	found := false
+	for _, env := range container.Env {
+		if env.Name == PodPropertiesEnv && env.Value == "/var/run/kfn/pod.properties" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the path of the pod properties must be passed to the invoker")
+	}
+}
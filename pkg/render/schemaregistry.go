@@ -0,0 +1,106 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	schemaRegistryUsernameEnv = "SCHEMA_REGISTRY_USERNAME"
+	schemaRegistryPasswordEnv = "SCHEMA_REGISTRY_PASSWORD"
+)
+
+// setSchemaRegistry passes the Schema Registry to both the consumer and
+// the producer. The credentials are pod properties.
+func (cfg *FunctionConfig) setSchemaRegistry(function *kfnv1alpha1.Function) {
+	registry := function.Spec.SchemaRegistry
+	if registry == nil {
+		return
+	}
+
+	for _, props := range []map[string]string{cfg.Consumer, cfg.Producer} {
+		props["schema.registry.url"] = registry.URL
+
+		if registry.AuthSecret != "" {
+			props["basic.auth.credentials.source"] = "USER_INFO"
+		}
+
+		if registry.SubjectNameStrategy != "" {
+			strategy := subjectNameStrategy(registry.SubjectNameStrategy)
+			props["key.subject.name.strategy"] = strategy
+			props["value.subject.name.strategy"] = strategy
+		}
+	}
+}
+
+// schemaRegistryPodProperties returns the credentials of the Schema
+// Registry, read from the Secret by the pod.
+func schemaRegistryPodProperties(function *kfnv1alpha1.Function, props map[string]string) {
+	registry := function.Spec.SchemaRegistry
+	if registry == nil || registry.AuthSecret == "" {
+		return
+	}
+
+	userInfo := fmt.Sprintf("${%s}:${%s}", schemaRegistryUsernameEnv, schemaRegistryPasswordEnv)
+	props["consumer.basic.auth.user.info"] = userInfo
+	props["producer.basic.auth.user.info"] = userInfo
+}
+
+// schemaRegistryEnv returns the environment variables holding the
+// credentials of the Schema Registry.
+func schemaRegistryEnv(function *kfnv1alpha1.Function) []corev1.EnvVar {
+	registry := function.Spec.SchemaRegistry
+	if registry == nil || registry.AuthSecret == "" {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		secretEnv(schemaRegistryUsernameEnv, registry.AuthSecret, "username"),
+		secretEnv(schemaRegistryPasswordEnv, registry.AuthSecret, "password"),
+	}
+}
+
+func secretEnv(name string, secret string, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secret,
+				},
+				Key: key,
+			},
+		},
+	}
+}
+
+func subjectNameStrategy(name string) string {
+	switch name {
+	case "TopicNameStrategy", "RecordNameStrategy", "TopicRecordNameStrategy":
+		return "io.confluent.kafka.serializers.subject." + name
+	default:
+		return name
+	}
+}
+
+// ValidateSchemaRegistry checks the Schema Registry of the Function.
+func ValidateSchemaRegistry(function *kfnv1alpha1.Function) error {
+	registry := function.Spec.SchemaRegistry
+	if registry == nil {
+		return nil
+	}
+
+	if registry.URL == "" {
+		return fmt.Errorf("the url of the schema registry is required")
+	}
+
+	if !strings.HasPrefix(registry.URL, "http://") && !strings.HasPrefix(registry.URL, "https://") {
+		return fmt.Errorf("invalid schema registry url %q: must start with http:// or https://", registry.URL)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	function := newRenderFunction()
+	function.Spec.InputValueDeserializer = "avro"
+	function.Spec.OutoutValueSerializer = "protobuf"
+	function.Spec.SchemaRegistry = &kfnv1alpha1.SchemaRegistrySpec{
+		URL:                 "http://schema-registry:8081",
+		AuthSecret:          "registry",
+		SubjectNameStrategy: "RecordNameStrategy",
+	}
+
+	props := renderedProperties(t, &FunctionDefaultConfig{}, function)
+
+	expected := map[string]string{
+		"consumer.value.deserializer":            "io.confluent.kafka.serializers.KafkaAvroDeserializer",
+		"producer.value.serializer":              "io.confluent.kafka.serializers.protobuf.KafkaProtobufSerializer",
+		"consumer.schema.registry.url":           "http://schema-registry:8081",
+		"producer.schema.registry.url":           "http://schema-registry:8081",
+		"consumer.basic.auth.credentials.source": "USER_INFO",
+		"producer.value.subject.name.strategy":   "io.confluent.kafka.serializers.subject.RecordNameStrategy",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("%s = %q, want %q", key, props[key], value)
+		}
+	}
+
+	// The credentials are only read by the pods
+	if _, ok := props["consumer.basic.auth.user.info"]; ok {
+		t.Errorf("the credentials must not be in the ConfigMap")
+	}
+	if info := PodProperties(function)["producer.basic.auth.user.info"]; info != "${SCHEMA_REGISTRY_USERNAME}:${SCHEMA_REGISTRY_PASSWORD}" {
+		t.Errorf("user info = %q", info)
+	}
+	env := schemaRegistryEnv(function)
+	if len(env) != 2 || env[0].ValueFrom.SecretKeyRef.Name != "registry" || env[1].ValueFrom.SecretKeyRef.Key != "password" {
+		t.Errorf("unexpected environment %+v", env)
+	}
+}
+
+func TestSubjectNameStrategy(t *testing.T) {
+	tests := map[string]string{
+		"TopicNameStrategy":          "io.confluent.kafka.serializers.subject.TopicNameStrategy",
+		"TopicRecordNameStrategy":    "io.confluent.kafka.serializers.subject.TopicRecordNameStrategy",
+		"com.example.CustomStrategy": "com.example.CustomStrategy",
+	}
+
+	for name, expected := range tests {
+		if actual := subjectNameStrategy(name); actual != expected {
+			t.Errorf("%s: strategy = %s, want %s", name, actual, expected)
+		}
+	}
+}
+
+func TestValidateSchemaRegistry(t *testing.T) {
+	tests := []struct {
+		url string
+		err string
+	}{
+		{"http://schema-registry:8081", ""},
+		{"https://schema-registry", ""},
+		{"", "url of the schema registry is required"},
+		{"schema-registry:8081", "must start with http:// or https://"},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.SchemaRegistry = &kfnv1alpha1.SchemaRegistrySpec{URL: test.url}
+
+		err := ValidateSchemaRegistry(function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %q", test.url, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: expected an error", test.url)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: error %q does not contain %q", test.url, err.Error(), test.err)
+		}
+	}
+
+	if !IsSerdeType("json-schema") || IsSerdeType("com.example.Serde") {
+		t.Errorf("json-schema is a serde type, a class name is not")
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// ShadowName returns the name of the ConfigMap and the Deployment of the
+// shadow of the Function.
+func ShadowName(function *v1alpha1.Function) string {
+	return function.Name + "-shadow"
+}
+
+// ShadowLabels returns the labels of the pods of the shadow. They don't
+// match the selector of the Deployment of the Function.
+func ShadowLabels(function *v1alpha1.Function) map[string]string {
+	return map[string]string{
+		"shadow": function.Name,
+	}
+}
+
+// ShadowConsumerGroup returns the consumer group of the shadow. The default
+// one contains underscores, which the names of the Functions can't, so it
+// is never the default consumer group of another Function.
+func ShadowConsumerGroup(function *v1alpha1.Function) string {
+	if shadow := function.Spec.Shadow; shadow != nil && shadow.ConsumerGroup != "" {
+		return shadow.ConsumerGroup
+	}
+	return function.Namespace + "_" + function.Name + "_shadow"
+}
+
+// ShadowOutput returns the topic written by the shadow.
+func ShadowOutput(function *v1alpha1.Function) string {
+	if shadow := function.Spec.Shadow; shadow != nil && shadow.Output != "" {
+		return shadow.Output
+	}
+	return function.Spec.Output + ".shadow"
+}
+
+// NewShadowFunction returns the Function run by the shadow: the Function
+// with the image, the class and the configuration of the shadow, its
+// consumer group and its output. It keeps the identity of the Function so
+// the resources rendered from it are owned by the Function.
+func NewShadowFunction(function *v1alpha1.Function) *v1alpha1.Function {
+	shadow := function.DeepCopy()
+	spec := function.Spec.Shadow
+
+	shadow.Spec.Shadow = nil
+	shadow.Spec.Strategy = nil
+	shadow.Spec.WorkloadType = ""
+	shadow.Spec.State = nil
+
+	// The pods of the shadow Deployment have no stable names to derive
+	// the transactional ids from
+	if shadow.Spec.ProcessingGuarantee == v1alpha1.ExactlyOnce {
+		shadow.Spec.ProcessingGuarantee = v1alpha1.AtLeastOnce
+	}
+
+	// The routes would write to the topics of the Function so all the
+	// records of the shadow go to its output
+	shadow.Spec.Routes = nil
+	if shadow.Spec.Builtin != nil {
+		shadow.Spec.Builtin.Route = ""
+	}
+
+	if spec.Image != "" {
+		shadow.Spec.Image = spec.Image
+	}
+
+	if spec.Class != "" {
+		shadow.Spec.Class = spec.Class
+	}
+
+	shadow.Spec.Replicas = 1
+	if spec.Replicas != nil {
+		shadow.Spec.Replicas = *spec.Replicas
+	}
+
+	shadow.Spec.Output = ShadowOutput(function)
+
+	shadow.Spec.FunctionConfig = mergeOptionalMap(function.Spec.FunctionConfig, spec.FunctionConfig)
+	shadow.Spec.ConsumerConfig = mergeOptionalMap(function.Spec.ConsumerConfig, spec.ConsumerConfig)
+	shadow.Spec.ProducerConfig = mergeOptionalMap(function.Spec.ProducerConfig, spec.ProducerConfig)
+
+	// The consumer group and the output are forced as sharing the ones of
+	// the Function would steal its partitions or pollute its output
+	(*shadow.Spec.ConsumerConfig)["group.id"] = ShadowConsumerGroup(function)
+	(*shadow.Spec.FunctionConfig)["output"] = shadow.Spec.Output
+
+	return shadow
+}
+
+func mergeOptionalMap(a *map[string]string, b *map[string]string) *map[string]string {
+	result := make(map[string]string)
+
+	if a != nil {
+		result = mergeMap(result, *a)
+	}
+
+	if b != nil {
+		result = mergeMap(result, *b)
+	}
+
+	return &result
+}
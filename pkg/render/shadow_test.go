@@ -0,0 +1,99 @@
+package render
+
+import (
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestNewShadowFunction(t *testing.T) {
+	replicas := int32(2)
+	function := newRenderFunction()
+	function.Spec.ProcessingGuarantee = kfnv1alpha1.ExactlyOnce
+	function.Spec.Routes = []kfnv1alpha1.RouteSpec{{Name: "eu", KeyPattern: "eu-.*", Output: "kfn.eu"}}
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{
+		Image:    "dajac/kfn-examples:0.2.0",
+		Replicas: &replicas,
+	}
+
+	shadow := NewShadowFunction(function)
+
+	if shadow.Name != "copy" || ShadowName(function) != "copy-shadow" {
+		t.Errorf("the shadow must keep the identity of the Function")
+	}
+	if shadow.Spec.Image != "dajac/kfn-examples:0.2.0" || shadow.Spec.Class != function.Spec.Class || shadow.Spec.Replicas != 2 {
+		t.Errorf("unexpected image %s, class %s and %d replicas", shadow.Spec.Image, shadow.Spec.Class, shadow.Spec.Replicas)
+	}
+	if shadow.Spec.ProcessingGuarantee != kfnv1alpha1.AtLeastOnce {
+		t.Errorf("processing guarantee = %s, want %s", shadow.Spec.ProcessingGuarantee, kfnv1alpha1.AtLeastOnce)
+	}
+	if shadow.Spec.Shadow != nil || shadow.Spec.Routes != nil {
+		t.Errorf("the shadow must have neither a shadow nor routes")
+	}
+	if shadow.Spec.Output != "kfn.destination.shadow" || (*shadow.Spec.FunctionConfig)["output"] != "kfn.destination.shadow" {
+		t.Errorf("output = %s, want kfn.destination.shadow", shadow.Spec.Output)
+	}
+	if group := (*shadow.Spec.ConsumerConfig)["group.id"]; group != "default_copy_shadow" {
+		t.Errorf("group.id = %s, want default_copy_shadow", group)
+	}
+
+	// A Function named like the shadow does not share its consumer group
+	other := newRenderFunction()
+	other.Name = ShadowName(function)
+	if group := NewFunctionConfig(&FunctionDefaultConfig{}, other).Consumer["group.id"]; group == ShadowConsumerGroup(function) {
+		t.Errorf("the Function %s must not share the consumer group of the shadow", other.Name)
+	}
+
+	// The Function is left untouched
+	if function.Spec.Image != "dajac/kfn-examples:0.1.0" || function.Spec.ConsumerConfig != nil || function.Spec.ProcessingGuarantee != kfnv1alpha1.ExactlyOnce {
+		t.Errorf("the Function must not be modified")
+	}
+}
+
+func TestNewShadowFunctionConfig(t *testing.T) {
+	consumer := map[string]string{"group.id": "copy", "max.poll.records": "10"}
+	shadowConsumer := map[string]string{"fetch.min.bytes": "1"}
+
+	function := newRenderFunction()
+	function.Spec.ConsumerConfig = &consumer
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{
+		Output:         "kfn.test",
+		ConsumerGroup:  "copy-test",
+		ConsumerConfig: &shadowConsumer,
+	}
+
+	props := renderedProperties(t, &FunctionDefaultConfig{}, NewShadowFunction(function))
+
+	expected := map[string]string{
+		"consumer.group.id":         "copy-test",
+		"consumer.max.poll.records": "10",
+		"consumer.fetch.min.bytes":  "1",
+		"function.output":           "kfn.test",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("%s = %q, want %q", key, props[key], value)
+		}
+	}
+	if consumer["group.id"] != "copy" {
+		t.Errorf("the consumer config of the Function must not be modified")
+	}
+}
+
+func TestRenderShadow(t *testing.T) {
+	function := newRenderFunction()
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{}
+
+	resources := Render(&FunctionDefaultConfig{}, function)
+	if resources.Shadow == nil || len(resources.Objects()) != 4 {
+		t.Fatalf("the resources of the shadow must be rendered")
+	}
+
+	deployment := resources.Shadow.Deployement
+	if deployment.Name != "copy-shadow" || *deployment.Spec.Replicas != 1 {
+		t.Errorf("unexpected shadow Deployment %s with %d replicas", deployment.Name, *deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Template.Labels["shadow"] != "copy" || deployment.Spec.Template.Labels["function"] == "copy" {
+		t.Errorf("the pods of the shadow must not match the selector of the Function, got %v", deployment.Spec.Template.Labels)
+	}
+}
@@ -0,0 +1,451 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// SourcesHashAnnotation is the pod template annotation holding the hash of
+// the ConfigMaps and the Secrets read by the Function. A change of the hash
+// rolls the workload.
+const SourcesHashAnnotation = "kfn.dajac.io/sources-hash"
+
+// Sources gets the ConfigMaps and the Secrets read by the Functions.
+type Sources interface {
+	ConfigMap(namespace string, name string) (*corev1.ConfigMap, error)
+	Secret(namespace string, name string) (*corev1.Secret, error)
+}
+
+type configFrom struct {
+	prefix  string
+	sources []kfnv1alpha1.ConfigFromSource
+}
+
+func configsFrom(function *kfnv1alpha1.Function) []configFrom {
+	return []configFrom{
+		{"function", function.Spec.FunctionConfigFrom},
+		{"consumer", function.Spec.ConsumerConfigFrom},
+		{"producer", function.Spec.ProducerConfigFrom},
+	}
+}
+
+// HasSources returns true if the Function reads ConfigMaps or Secrets.
+func HasSources(function *kfnv1alpha1.Function) bool {
+	return len(function.Spec.FunctionConfigFrom) > 0 ||
+		len(function.Spec.ConsumerConfigFrom) > 0 ||
+		len(function.Spec.ProducerConfigFrom) > 0 ||
+		len(function.Spec.Files) > 0 ||
+		len(secretPlaceholders(function)) > 0
+}
+
+// secretPlaceholders returns the Secrets and the keys referenced by the
+// ${secret:<name>/<key>} placeholders of the configuration of the Function,
+// sorted.
+func secretPlaceholders(function *kfnv1alpha1.Function) []corev1.SecretKeySelector {
+	configs := []*map[string]string{
+		function.Spec.FunctionConfig,
+		function.Spec.ConsumerConfig,
+		function.Spec.ProducerConfig,
+	}
+
+	if shadow := function.Spec.Shadow; shadow != nil {
+		configs = append(configs, shadow.FunctionConfig, shadow.ConsumerConfig, shadow.ProducerConfig)
+	}
+
+	seen := make(map[string]bool)
+	selectors := []corev1.SecretKeySelector{}
+
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+
+		for _, value := range *config {
+			for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+				name, key, ok := parseSecretPlaceholder(match[1])
+				if !ok || seen[name+"/"+key] {
+					continue
+				}
+				seen[name+"/"+key] = true
+
+				selectors = append(selectors, corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Key:                  key,
+				})
+			}
+		}
+	}
+
+	sort.Slice(selectors, func(i, j int) bool {
+		if selectors[i].Name != selectors[j].Name {
+			return selectors[i].Name < selectors[j].Name
+		}
+		return selectors[i].Key < selectors[j].Key
+	})
+
+	return selectors
+}
+
+// ReadsConfigMap returns true if the Function reads the ConfigMap.
+func ReadsConfigMap(function *kfnv1alpha1.Function, name string) bool {
+	for _, cfg := range configsFrom(function) {
+		for _, source := range cfg.sources {
+			if source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+
+	for _, file := range function.Spec.Files {
+		if file.ConfigMap != nil && file.ConfigMap.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadsSecret returns true if the Function reads the Secret.
+func ReadsSecret(function *kfnv1alpha1.Function, name string) bool {
+	for _, cfg := range configsFrom(function) {
+		for _, source := range cfg.sources {
+			if source.SecretKeyRef != nil && source.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+
+	for _, file := range function.Spec.Files {
+		if file.Secret != nil && file.Secret.SecretName == name {
+			return true
+		}
+	}
+
+	for _, selector := range secretPlaceholders(function) {
+		if selector.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveSources returns the Function with the properties read from
+// ConfigMaps merged into its configurations, so they are written in its
+// properties file, and with the hash of everything it reads in the
+// SourcesHashAnnotation annotation. The properties read from Secrets are
+// kept as they are added when the container starts. The missing optional
+// keys are dropped.
+func ResolveSources(function *kfnv1alpha1.Function, sources Sources) (*kfnv1alpha1.Function, error) {
+	if !HasSources(function) {
+		return function, nil
+	}
+
+	resolved := function.DeepCopy()
+	h := sha256.New()
+
+	var err error
+
+	resolved.Spec.FunctionConfig, resolved.Spec.FunctionConfigFrom, err = resolveConfigFrom(function.Namespace, "function", function.Spec.FunctionConfig, function.Spec.FunctionConfigFrom, sources, h)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved.Spec.ConsumerConfig, resolved.Spec.ConsumerConfigFrom, err = resolveConfigFrom(function.Namespace, "consumer", function.Spec.ConsumerConfig, function.Spec.ConsumerConfigFrom, sources, h)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved.Spec.ProducerConfig, resolved.Spec.ProducerConfigFrom, err = resolveConfigFrom(function.Namespace, "producer", function.Spec.ProducerConfig, function.Spec.ProducerConfigFrom, sources, h)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range function.Spec.Files {
+		if err := hashFile(function.Namespace, file, sources, h); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, selector := range secretPlaceholders(function) {
+		value, _, err := sourceValue(function.Namespace, kfnv1alpha1.ConfigFromSource{SecretKeyRef: &selector}, sources)
+		if err != nil {
+			return nil, fmt.Errorf("placeholder ${secret:%s/%s}: %v", selector.Name, selector.Key, err)
+		}
+
+		fmt.Fprintf(h, "${secret:%s/%s}\x00%s\x00", selector.Name, selector.Key, value)
+	}
+
+	if resolved.Annotations == nil {
+		resolved.Annotations = make(map[string]string)
+	}
+	resolved.Annotations[SourcesHashAnnotation] = hex.EncodeToString(h.Sum(nil))
+
+	return resolved, nil
+}
+
+func resolveConfigFrom(namespace string, prefix string, config *map[string]string, from []kfnv1alpha1.ConfigFromSource, sources Sources, h hash.Hash) (*map[string]string, []kfnv1alpha1.ConfigFromSource, error) {
+	if len(from) == 0 {
+		return config, from, nil
+	}
+
+	result := make(map[string]string)
+	if config != nil {
+		result = mergeMap(result, *config)
+	}
+
+	remaining := []kfnv1alpha1.ConfigFromSource{}
+
+	for _, source := range from {
+		value, ok, err := sourceValue(namespace, source, sources)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s property %s: %v", prefix, source.Name, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(h, "%s.%s\x00%s\x00", prefix, source.Name, value)
+
+		if source.SecretKeyRef != nil {
+			remaining = append(remaining, source)
+		} else {
+			result[source.Name] = value
+		}
+	}
+
+	return &result, remaining, nil
+}
+
+// sourceValue returns the value of the property and false if it is
+// optional and missing.
+func sourceValue(namespace string, source kfnv1alpha1.ConfigFromSource, sources Sources) (string, bool, error) {
+	if ref := source.ConfigMapKeyRef; ref != nil {
+		configMap, err := sources.ConfigMap(namespace, ref.Name)
+		if err != nil {
+			if errors.IsNotFound(err) && isOptional(ref.Optional) {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+
+		value, ok := configMap.Data[ref.Key]
+		if !ok {
+			if isOptional(ref.Optional) {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("key %s not found in configmap %s", ref.Key, ref.Name)
+		}
+
+		return value, true, nil
+	}
+
+	ref := source.SecretKeyRef
+
+	secret, err := sources.Secret(namespace, ref.Name)
+	if err != nil {
+		if errors.IsNotFound(err) && isOptional(ref.Optional) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		if isOptional(ref.Optional) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+	}
+
+	return string(value), true, nil
+}
+
+func hashFile(namespace string, file kfnv1alpha1.FileSpec, sources Sources, h hash.Hash) error {
+	var data map[string][]byte
+	var items []corev1.KeyToPath
+
+	if file.ConfigMap != nil {
+		configMap, err := sources.ConfigMap(namespace, file.ConfigMap.Name)
+		if err != nil {
+			if errors.IsNotFound(err) && isOptional(file.ConfigMap.Optional) {
+				return nil
+			}
+			return fmt.Errorf("file %s: %v", file.MountPath, err)
+		}
+
+		data = make(map[string][]byte)
+		for key, value := range configMap.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range configMap.BinaryData {
+			data[key] = value
+		}
+		items = file.ConfigMap.Items
+	} else {
+		secret, err := sources.Secret(namespace, file.Secret.SecretName)
+		if err != nil {
+			if errors.IsNotFound(err) && isOptional(file.Secret.Optional) {
+				return nil
+			}
+			return fmt.Errorf("file %s: %v", file.MountPath, err)
+		}
+
+		data = secret.Data
+		items = file.Secret.Items
+	}
+
+	// Only the selected entries are mounted
+	keys := []string{}
+	if len(items) > 0 {
+		for _, item := range items {
+			keys = append(keys, item.Key)
+		}
+	} else {
+		for key := range data {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(h, "%s\x00", file.MountPath)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", key, data[key])
+	}
+
+	return nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// configFromEnvName returns the environment variable holding the value of
+// a property read when the container starts.
+func configFromEnvName(prefix string, index int) string {
+	return fmt.Sprintf("KFN_%s_CONFIG_%d", strings.ToUpper(prefix), index)
+}
+
+// configFromPodProperties adds the properties read when the container
+// starts: the ones read from Secrets and, if the Function has not been
+// resolved, the ones read from ConfigMaps.
+func configFromPodProperties(function *kfnv1alpha1.Function, props map[string]string) {
+	for _, cfg := range configsFrom(function) {
+		for i, source := range cfg.sources {
+			props[cfg.prefix+"."+source.Name] = "${" + configFromEnvName(cfg.prefix, i) + "}"
+		}
+	}
+}
+
+// configFromEnv returns the environment variables used by
+// configFromPodProperties.
+func configFromEnv(function *kfnv1alpha1.Function) []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+
+	for _, cfg := range configsFrom(function) {
+		for i, source := range cfg.sources {
+			env = append(env, corev1.EnvVar{
+				Name: configFromEnvName(cfg.prefix, i),
+				ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: source.ConfigMapKeyRef,
+					SecretKeyRef:    source.SecretKeyRef,
+				},
+			})
+		}
+	}
+
+	return env
+}
+
+// filesVolumes returns the volumes and the mounts of the files of the
+// Function.
+func filesVolumes(function *kfnv1alpha1.Function) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+
+	for i, file := range function.Spec.Files {
+		name := fmt.Sprintf("files-%d", i)
+
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: file.ConfigMap,
+				Secret:    file.Secret,
+			},
+		})
+
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: file.MountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return volumes, mounts
+}
+
+// ValidateSources validates the properties and the files read from
+// ConfigMaps and Secrets.
+func ValidateSources(function *kfnv1alpha1.Function) error {
+	configs := []*map[string]string{
+		function.Spec.FunctionConfig,
+		function.Spec.ConsumerConfig,
+		function.Spec.ProducerConfig,
+	}
+
+	for i, cfg := range configsFrom(function) {
+		names := make(map[string]bool)
+
+		for _, source := range cfg.sources {
+			if source.Name == "" {
+				return fmt.Errorf("%sConfigFrom: name is required", cfg.prefix)
+			}
+
+			if (source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+				return fmt.Errorf("%s property %s: exactly one of configMapKeyRef and secretKeyRef must be set", cfg.prefix, source.Name)
+			}
+
+			if names[source.Name] {
+				return fmt.Errorf("%s property %s is read twice", cfg.prefix, source.Name)
+			}
+			names[source.Name] = true
+
+			if hasConfig(configs[i], source.Name) {
+				return fmt.Errorf("%s property %s is set both inline and from a source", cfg.prefix, source.Name)
+			}
+		}
+	}
+
+	paths := make(map[string]bool)
+
+	for _, file := range function.Spec.Files {
+		if !strings.HasPrefix(file.MountPath, "/") {
+			return fmt.Errorf("file %q: mountPath must be an absolute path", file.MountPath)
+		}
+
+		if (file.ConfigMap == nil) == (file.Secret == nil) {
+			return fmt.Errorf("file %s: exactly one of configMap and secret must be set", file.MountPath)
+		}
+
+		if paths[file.MountPath] {
+			return fmt.Errorf("file %s is mounted twice", file.MountPath)
+		}
+		paths[file.MountPath] = true
+
+		if file.MountPath == configurationPath || file.MountPath == runtimePath {
+			return fmt.Errorf("file %s: the path is used by the invoker", file.MountPath)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// testSources are the ConfigMaps and the Secrets of the default namespace.
+type testSources struct {
+	configMaps map[string]map[string]string
+	secrets    map[string]map[string]string
+}
+
+func (s *testSources) ConfigMap(namespace string, name string) (*corev1.ConfigMap, error) {
+	data, ok := s.configMaps[name]
+	if !ok {
+		return nil, errors.NewNotFound(corev1.Resource("configmap"), name)
+	}
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: data}, nil
+}
+
+func (s *testSources) Secret(namespace string, name string) (*corev1.Secret, error) {
+	values, ok := s.secrets[name]
+	if !ok {
+		return nil, errors.NewNotFound(corev1.Resource("secret"), name)
+	}
+	data := make(map[string][]byte)
+	for key, value := range values {
+		data[key] = []byte(value)
+	}
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: data}, nil
+}
+
+func configMapSource(name string, configMap string, key string, optional bool) kfnv1alpha1.ConfigFromSource {
+	return kfnv1alpha1.ConfigFromSource{
+		Name: name,
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+			Key:                  key,
+			Optional:             &optional,
+		},
+	}
+}
+
+func secretSource(name string, secret string, key string) kfnv1alpha1.ConfigFromSource {
+	return kfnv1alpha1.ConfigFromSource{
+		Name: name,
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+			Key:                  key,
+		},
+	}
+}
+
+func TestResolveSources(t *testing.T) {
+	sources := &testSources{
+		configMaps: map[string]map[string]string{"settings": {"threshold": "10"}},
+		secrets:    map[string]map[string]string{"credentials": {"password": "secret"}},
+	}
+
+	function := newRenderFunction()
+	if resolved, _ := ResolveSources(function, sources); resolved != function {
+		t.Errorf("a Function without sources must be returned as it is")
+	}
+
+	function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{
+		configMapSource("threshold", "settings", "threshold", false),
+		configMapSource("missing", "settings", "missing", true),
+	}
+	function.Spec.ProducerConfigFrom = []kfnv1alpha1.ConfigFromSource{
+		secretSource("sasl.password", "credentials", "password"),
+	}
+
+	resolved, err := ResolveSources(function, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The values of the ConfigMaps are written in the properties file
+	if (*resolved.Spec.FunctionConfig)["threshold"] != "10" || len(resolved.Spec.FunctionConfigFrom) != 0 {
+		t.Errorf("the value of the ConfigMap must be inlined, got %v", *resolved.Spec.FunctionConfig)
+	}
+	if _, ok := (*resolved.Spec.FunctionConfig)["missing"]; ok {
+		t.Errorf("the missing optional key must be dropped")
+	}
+
+	// The ones of the Secrets are read by the pods
+	if len(resolved.Spec.ProducerConfigFrom) != 1 || resolved.Spec.ProducerConfig == nil || len(*resolved.Spec.ProducerConfig) != 0 {
+		t.Errorf("the Secret must not be inlined")
+	}
+	if value := PodProperties(resolved)["producer.sasl.password"]; value != "${KFN_PRODUCER_CONFIG_0}" {
+		t.Errorf("sasl.password = %q, want ${KFN_PRODUCER_CONFIG_0}", value)
+	}
+	if env := configFromEnv(resolved); len(env) != 1 || env[0].ValueFrom.SecretKeyRef.Name != "credentials" {
+		t.Errorf("unexpected environment %+v", env)
+	}
+
+	// A new value of a source changes the hash
+	hash := resolved.Annotations[SourcesHashAnnotation]
+	if hash == "" || function.Annotations != nil {
+		t.Errorf("the hash must be set on the resolved Function only")
+	}
+	sources.secrets["credentials"]["password"] = "other"
+	if updated, _ := ResolveSources(function, sources); updated.Annotations[SourcesHashAnnotation] == hash {
+		t.Errorf("the hash must change with the value of the Secret")
+	}
+
+	// A missing required key is an error
+	delete(sources.configMaps["settings"], "threshold")
+	if _, err := ResolveSources(function, sources); err == nil || !strings.Contains(err.Error(), "function property threshold: key threshold not found in configmap settings") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestResolveSourcesFiles(t *testing.T) {
+	sources := &testSources{
+		configMaps: map[string]map[string]string{"files": {"a.txt": "a", "b.txt": "b"}},
+	}
+
+	function := newRenderFunction()
+	function.Spec.Files = []kfnv1alpha1.FileSpec{{
+		MountPath: "/etc/files",
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "files"},
+			Items:                []corev1.KeyToPath{{Key: "a.txt", Path: "a.txt"}},
+		},
+	}}
+
+	resolved, err := ResolveSources(function, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := resolved.Annotations[SourcesHashAnnotation]
+
+	// Only the mounted entries are hashed
+	sources.configMaps["files"]["b.txt"] = "other"
+	if resolved, _ := ResolveSources(function, sources); resolved.Annotations[SourcesHashAnnotation] != hash {
+		t.Errorf("the hash must not change with an entry which is not mounted")
+	}
+	sources.configMaps["files"]["a.txt"] = "other"
+	if resolved, _ := ResolveSources(function, sources); resolved.Annotations[SourcesHashAnnotation] == hash {
+		t.Errorf("the hash must change with a mounted entry")
+	}
+
+	volumes, mounts := filesVolumes(function)
+	if len(volumes) != 1 || volumes[0].ConfigMap.Name != "files" || mounts[0].MountPath != "/etc/files" || !mounts[0].ReadOnly {
+		t.Errorf("unexpected volumes %+v and mounts %+v", volumes, mounts)
+	}
+
+	if !ReadsConfigMap(function, "files") || ReadsConfigMap(function, "other") || ReadsSecret(function, "files") {
+		t.Errorf("the Function only reads the ConfigMap files")
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	inline := map[string]string{"threshold": "1"}
+	source := configMapSource("threshold", "settings", "threshold", false)
+	file := kfnv1alpha1.FileSpec{MountPath: "/etc/files", Secret: &corev1.SecretVolumeSource{SecretName: "files"}}
+
+	tests := []struct {
+		name   string
+		update func(*kfnv1alpha1.Function)
+		err    string
+	}{
+		{"valid", func(function *kfnv1alpha1.Function) {
+			function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{source}
+			function.Spec.Files = []kfnv1alpha1.FileSpec{file}
+		}, ""},
+		{"no name", func(function *kfnv1alpha1.Function) {
+			function.Spec.ConsumerConfigFrom = []kfnv1alpha1.ConfigFromSource{{ConfigMapKeyRef: source.ConfigMapKeyRef}}
+		}, "consumerConfigFrom: name is required"},
+		{"no source", func(function *kfnv1alpha1.Function) {
+			function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{{Name: "threshold"}}
+		}, "exactly one of configMapKeyRef and secretKeyRef must be set"},
+		{"twice", func(function *kfnv1alpha1.Function) {
+			function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{source, source}
+		}, "function property threshold is read twice"},
+		{"inline", func(function *kfnv1alpha1.Function) {
+			function.Spec.FunctionConfig = &inline
+			function.Spec.FunctionConfigFrom = []kfnv1alpha1.ConfigFromSource{source}
+		}, "set both inline and from a source"},
+		{"relative path", func(function *kfnv1alpha1.Function) {
+			function.Spec.Files = []kfnv1alpha1.FileSpec{{MountPath: "files", Secret: file.Secret}}
+		}, "must be an absolute path"},
+		{"mounted twice", func(function *kfnv1alpha1.Function) {
+			function.Spec.Files = []kfnv1alpha1.FileSpec{file, file}
+		}, "file /etc/files is mounted twice"},
+		{"invoker path", func(function *kfnv1alpha1.Function) {
+			function.Spec.Files = []kfnv1alpha1.FileSpec{{MountPath: "/etc/kfn", Secret: file.Secret}}
+		}, "the path is used by the invoker"},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		test.update(function)
+
+		err := ValidateSources(function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q does not contain %q", test.name, err.Error(), test.err)
+		}
+	}
+}
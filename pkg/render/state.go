@@ -0,0 +1,95 @@
+package render
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+const (
+	stateVolume           = "state"
+	defaultStateSize      = "1Gi"
+	defaultStateMountPath = "/var/lib/kfn/state"
+)
+
+// StateMountPath returns the path of the state volume in the container.
+func StateMountPath(function *kfnv1alpha1.Function) string {
+	if state := function.Spec.State; state != nil && state.MountPath != "" {
+		return state.MountPath
+	}
+	return defaultStateMountPath
+}
+
+// StateSize returns the size of each state volume.
+func StateSize(function *kfnv1alpha1.Function) (resource.Quantity, error) {
+	size := defaultStateSize
+	if state := function.Spec.State; state != nil && state.Size != "" {
+		size = state.Size
+	}
+	return resource.ParseQuantity(size)
+}
+
+// StateClaimPrefix returns the prefix of the names of the
+// PersistentVolumeClaims created by the StatefulSet of the Function. The
+// ordinal of the pod follows it.
+func StateClaimPrefix(function *kfnv1alpha1.Function) string {
+	return stateVolume + "-" + function.Name + "-"
+}
+
+// ValidateState checks the state settings of the Function.
+func ValidateState(function *kfnv1alpha1.Function) error {
+	state := function.Spec.State
+	if state == nil {
+		return nil
+	}
+
+	if function.Spec.WorkloadType == kfnv1alpha1.DeploymentWorkload {
+		return fmt.Errorf("state requires the %s workload type", kfnv1alpha1.StatefulSetWorkload)
+	}
+
+	size, err := StateSize(function)
+	if err != nil {
+		return fmt.Errorf("invalid state size %q: %s", state.Size, err.Error())
+	}
+	if size.Sign() <= 0 {
+		return fmt.Errorf("invalid state size %q: must be positive", state.Size)
+	}
+
+	switch state.RetentionPolicy {
+	case "", kfnv1alpha1.RetainState, kfnv1alpha1.DeleteState:
+	default:
+		return fmt.Errorf("invalid state retention policy %q: must be %s or %s", state.RetentionPolicy, kfnv1alpha1.RetainState, kfnv1alpha1.DeleteState)
+	}
+
+	return nil
+}
+
+// newStateClaimTemplate returns the template of the PersistentVolumeClaim
+// of each pod of the Function. An invalid size is rejected by
+// ValidateState before the resources are rendered.
+func newStateClaimTemplate(function *kfnv1alpha1.Function, labels map[string]string) corev1.PersistentVolumeClaim {
+	size, err := StateSize(function)
+	if err != nil {
+		size = resource.MustParse(defaultStateSize)
+	}
+
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   stateVolume,
+			Labels: labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: function.Spec.State.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestValidateState(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload string
+		state    *kfnv1alpha1.StateSpec
+		err      string
+	}{
+		{"none", kfnv1alpha1.DeploymentWorkload, nil, ""},
+		{"default", "", &kfnv1alpha1.StateSpec{}, ""},
+		{"valid", kfnv1alpha1.StatefulSetWorkload, &kfnv1alpha1.StateSpec{Size: "10Gi", RetentionPolicy: kfnv1alpha1.DeleteState}, ""},
+		{"deployment", kfnv1alpha1.DeploymentWorkload, &kfnv1alpha1.StateSpec{}, "requires the StatefulSet workload type"},
+		{"invalid size", "", &kfnv1alpha1.StateSpec{Size: "ten"}, `invalid state size "ten"`},
+		{"zero size", "", &kfnv1alpha1.StateSpec{Size: "0"}, "must be positive"},
+		{"invalid policy", "", &kfnv1alpha1.StateSpec{RetentionPolicy: "Archive"}, `invalid state retention policy "Archive"`},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.WorkloadType = test.workload
+		function.Spec.State = test.state
+
+		err := ValidateState(function)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q does not contain %q", test.name, err.Error(), test.err)
+		}
+	}
+}
+
+func TestRenderState(t *testing.T) {
+	storageClass := "fast"
+	function := newRenderFunction()
+	function.Spec.State = &kfnv1alpha1.StateSpec{
+		StorageClassName: &storageClass,
+		Size:             "5Gi",
+		MountPath:        "/data",
+	}
+
+	statefulSet := Render(&FunctionDefaultConfig{}, function).StatefulSet
+	if statefulSet == nil {
+		t.Fatalf("a Function with state must run as a StatefulSet")
+	}
+
+	mounts := statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts
+	if mount := mounts[len(mounts)-1]; mount.Name != "state" || mount.MountPath != "/data" {
+		t.Errorf("unexpected volume mount %+v", mount)
+	}
+
+	if len(statefulSet.Spec.VolumeClaimTemplates) != 1 {
+		t.Fatalf("got %d claim templates, want 1", len(statefulSet.Spec.VolumeClaimTemplates))
+	}
+	claim := statefulSet.Spec.VolumeClaimTemplates[0]
+	size := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	if claim.Name != "state" || *claim.Spec.StorageClassName != "fast" || size.String() != "5Gi" {
+		t.Errorf("unexpected claim template %s of %s", claim.Name, size.String())
+	}
+
+	if dir := renderedProperties(t, &FunctionDefaultConfig{}, function)["function.state.dir"]; dir != "/data" {
+		t.Errorf("state.dir = %q, want /data", dir)
+	}
+	if StateClaimPrefix(function) != "state-copy-" {
+		t.Errorf("claim prefix = %s, want state-copy-", StateClaimPrefix(function))
+	}
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// NewStatefulSet returns the StatefulSet running the Function when its
+// workload type is StatefulSet or when it has state. The pods are started
+// in parallel as they don't depend on each other.
+func NewStatefulSet(function *kfnv1alpha1.Function, config *FunctionConfig, configMap *corev1.ConfigMap) *appsv1.StatefulSet {
+	labels := map[string]string{
+		"function": function.Name,
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      function.Name,
+			Namespace: function.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(function, schema.GroupVersionKind{
+					Group:   kfnv1alpha1.SchemeGroupVersion.Group,
+					Version: kfnv1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Function",
+				}),
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &function.Spec.Replicas,
+			ServiceName:         function.Name,
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: newPodTemplate(function, config, configMap, labels),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+	}
+
+	if function.Spec.State != nil {
+		container := &statefulSet.Spec.Template.Spec.Containers[0]
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      stateVolume,
+			MountPath: StateMountPath(function),
+		})
+
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			newStateClaimTemplate(function, labels),
+		}
+	}
+
+	return statefulSet
+}
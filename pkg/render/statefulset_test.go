@@ -0,0 +1,69 @@
+package render
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestIsStatefulSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   func(*kfnv1alpha1.Function)
+		expected bool
+	}{
+		{"default", func(function *kfnv1alpha1.Function) {}, false},
+		{"deployment", func(function *kfnv1alpha1.Function) { function.Spec.WorkloadType = kfnv1alpha1.DeploymentWorkload }, false},
+		{"statefulset", func(function *kfnv1alpha1.Function) { function.Spec.WorkloadType = kfnv1alpha1.StatefulSetWorkload }, true},
+		{"state", func(function *kfnv1alpha1.Function) { function.Spec.State = &kfnv1alpha1.StateSpec{} }, true},
+		{"exactly-once", func(function *kfnv1alpha1.Function) { function.Spec.ProcessingGuarantee = kfnv1alpha1.ExactlyOnce }, true},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		test.update(function)
+		if actual := IsStatefulSet(function); actual != test.expected {
+			t.Errorf("%s: IsStatefulSet = %v, want %v", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestRenderStatefulSet(t *testing.T) {
+	function := newRenderFunction()
+	function.Spec.WorkloadType = kfnv1alpha1.StatefulSetWorkload
+
+	resources := Render(&FunctionDefaultConfig{}, function)
+	if resources.Deployement != nil || resources.StatefulSet == nil {
+		t.Fatalf("only a StatefulSet is expected")
+	}
+
+	statefulSet := resources.StatefulSet
+	if statefulSet.Name != "copy" || statefulSet.Kind != "StatefulSet" || *statefulSet.Spec.Replicas != 2 {
+		t.Errorf("unexpected StatefulSet %s of kind %s", statefulSet.Name, statefulSet.Kind)
+	}
+	if statefulSet.Spec.PodManagementPolicy != appsv1.ParallelPodManagement {
+		t.Errorf("the pods must be started in parallel")
+	}
+	if len(statefulSet.Spec.VolumeClaimTemplates) != 0 {
+		t.Errorf("a Function without state has no volumes")
+	}
+
+	if id := PodProperties(function)["consumer.group.instance.id"]; id != "${POD_NAME}" {
+		t.Errorf("group.instance.id = %q, want ${POD_NAME}", id)
+	}
+
+	// The group instance id of the configuration wins
+	consumer := map[string]string{"group.instance.id": "static"}
+	function.Spec.ConsumerConfig = &consumer
+	if id, ok := PodProperties(function)["consumer.group.instance.id"]; ok {
+		t.Errorf("group.instance.id = %q, want none", id)
+	}
+
+	function.Spec.WorkloadType = kfnv1alpha1.DeploymentWorkload
+	function.Spec.ConsumerConfig = nil
+	if _, ok := PodProperties(function)["consumer.group.instance.id"]; ok {
+		t.Errorf("the pods of a Deployment are not static members")
+	}
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"fmt"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+// ValidateStrategy checks the rollout strategy of the Function. A canary
+// runs in a Deployment so it is rejected for the Functions running as a
+// StatefulSet. The lag of the canary rollouts is measured by the operator
+// which only connects to the PLAINTEXT listeners of the cluster.
+func ValidateStrategy(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	strategy := function.Spec.Strategy
+	if strategy == nil {
+		return nil
+	}
+
+	switch strategy.Type {
+	case kfnv1alpha1.RollingUpdateStrategy:
+		return nil
+	case kfnv1alpha1.CanaryStrategy:
+	default:
+		return fmt.Errorf("invalid strategy %q: must be %s or %s", strategy.Type, kfnv1alpha1.RollingUpdateStrategy, kfnv1alpha1.CanaryStrategy)
+	}
+
+	if IsStatefulSet(function) {
+		return fmt.Errorf("the %s strategy is only supported by the Deployment workload, the Function runs as a StatefulSet because of its workloadType, state or exactly-once processing guarantee", kfnv1alpha1.CanaryStrategy)
+	}
+
+	if strategy.Canary == nil || strategy.Canary.MaxLag == nil {
+		return nil
+	}
+
+	if *strategy.Canary.MaxLag < 0 {
+		return fmt.Errorf("canary maxLag must not be negative")
+	}
+
+	protocol := NewFunctionConfig(defaultConfig, function).Consumer["security.protocol"]
+	if protocol != "" && protocol != "PLAINTEXT" {
+		return fmt.Errorf("canary maxLag can't be checked with security.protocol %s: the operator only measures the lag on PLAINTEXT listeners", protocol)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestValidateStrategy(t *testing.T) {
+	lag := int64(100)
+	negative := int64(-1)
+
+	tests := []struct {
+		name      string
+		strategy  *kfnv1alpha1.FunctionStrategy
+		consumer  map[string]string
+		workload  string
+		errString string
+	}{
+		{name: "none"},
+		{name: "rolling update", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.RollingUpdateStrategy}},
+		{name: "unknown", strategy: &kfnv1alpha1.FunctionStrategy{Type: "BlueGreen"}, errString: `invalid strategy "BlueGreen"`},
+		{name: "canary", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy}},
+		{name: "canary deployment", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy}, workload: kfnv1alpha1.DeploymentWorkload},
+		{name: "canary statefulset", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy}, workload: kfnv1alpha1.StatefulSetWorkload, errString: "only supported by the Deployment workload"},
+		{name: "rolling update statefulset", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.RollingUpdateStrategy}, workload: kfnv1alpha1.StatefulSetWorkload},
+		{name: "max lag", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy, Canary: &kfnv1alpha1.CanaryConfig{MaxLag: &lag}}},
+		{name: "plaintext", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy, Canary: &kfnv1alpha1.CanaryConfig{MaxLag: &lag}}, consumer: map[string]string{"security.protocol": "PLAINTEXT"}},
+		{name: "negative", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy, Canary: &kfnv1alpha1.CanaryConfig{MaxLag: &negative}}, errString: "must not be negative"},
+		{name: "ssl", strategy: &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy, Canary: &kfnv1alpha1.CanaryConfig{MaxLag: &lag}}, consumer: map[string]string{"security.protocol": "SSL"}, errString: "security.protocol SSL"},
+	}
+
+	for _, test := range tests {
+		function := newRenderFunction()
+		function.Spec.Strategy = test.strategy
+		function.Spec.WorkloadType = test.workload
+		if test.consumer != nil {
+			function.Spec.ConsumerConfig = &test.consumer
+		}
+
+		err := ValidateStrategy(&FunctionDefaultConfig{}, function)
+		if test.errString == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %q", test.name, err.Error())
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errString) {
+			t.Errorf("%s: error %v does not contain %q", test.name, err, test.errString)
+		}
+	}
+}
+
+func TestValidateStrategyStatefulSet(t *testing.T) {
+	canary := &kfnv1alpha1.FunctionStrategy{Type: kfnv1alpha1.CanaryStrategy}
+
+	state := newRenderFunction()
+	state.Spec.Strategy = canary
+	state.Spec.State = &kfnv1alpha1.StateSpec{}
+
+	exactlyOnce := newExactlyOnceFunction()
+	exactlyOnce.Spec.Strategy = canary
+
+	for _, function := range []*kfnv1alpha1.Function{state, exactlyOnce} {
+		if err := ValidateStrategy(&FunctionDefaultConfig{}, function); err == nil || !strings.Contains(err.Error(), "runs as a StatefulSet") {
+			t.Errorf("a canary must be rejected for a StatefulSet, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/kafkaconfig"
+)
+
+// ValidateConfig checks the configuration of the Function which can't be
+// rendered.
+func ValidateConfig(defaultConfig *FunctionDefaultConfig, function *kfnv1alpha1.Function) error {
+	if err := ValidateRuntime(defaultConfig, function); err != nil {
+		return err
+	}
+
+	if err := ValidateProfile(defaultConfig, function); err != nil {
+		return err
+	}
+
+	if err := ValidatePlaceholders(defaultConfig, function); err != nil {
+		return err
+	}
+
+	if err := ValidateStrategy(defaultConfig, function); err != nil {
+		return err
+	}
+
+	validators := []func(*kfnv1alpha1.Function) error{
+		ValidateInvocation,
+		ValidateBuiltin,
+		ValidateRoutes,
+		ValidateProcessingGuarantee,
+		ValidateSchemaRegistry,
+		ValidateSources,
+		validateCatalog,
+	}
+
+	for _, validate := range validators {
+		if err := validate(function); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConfigWarnings returns the consumer and producer properties of the
+// Function which are unknown to the Kafka clients.
+func ConfigWarnings(function *kfnv1alpha1.Function) []string {
+	return checkCatalog(function).Warnings
+}
+
+func validateCatalog(function *kfnv1alpha1.Function) error {
+	if errors := checkCatalog(function).Errors; len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+type clientConfig struct {
+	prefix string
+	client kafkaconfig.Client
+	config *map[string]string
+}
+
+// checkCatalog checks the consumer and producer configurations of the
+// Function and of its shadow against the catalog of the Kafka clients.
+func checkCatalog(function *kfnv1alpha1.Function) kafkaconfig.Result {
+	result := kafkaconfig.Result{}
+
+	custom := strings.Split(function.Annotations[kfn.CustomConfigAnnotation], ",")
+	for _, pattern := range custom {
+		if strings.TrimSpace(pattern) == "*" {
+			return result
+		}
+	}
+
+	configs := []clientConfig{
+		{"", kafkaconfig.Consumer, function.Spec.ConsumerConfig},
+		{"", kafkaconfig.Producer, function.Spec.ProducerConfig},
+	}
+
+	if shadow := function.Spec.Shadow; shadow != nil {
+		configs = append(configs,
+			clientConfig{"shadow ", kafkaconfig.Consumer, shadow.ConsumerConfig},
+			clientConfig{"shadow ", kafkaconfig.Producer, shadow.ProducerConfig},
+		)
+	}
+
+	for _, c := range configs {
+		if c.config == nil {
+			continue
+		}
+
+		// The values with placeholders are only known once resolved
+		r := kafkaconfig.Validate(c.client, *c.config, append(custom, placeholderKeys(*c.config)...))
+		for _, e := range r.Errors {
+			result.Errors = append(result.Errors, c.prefix+e)
+		}
+		for _, w := range r.Warnings {
+			result.Warnings = append(result.Warnings, c.prefix+w)
+		}
+	}
+
+	return result
+}
+
+func placeholderKeys(config map[string]string) []string {
+	keys := []string{}
+	for key, value := range config {
+		if placeholderPattern.MatchString(value) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
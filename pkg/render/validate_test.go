@@ -0,0 +1,46 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dajac/kfn/pkg/apis/kfn"
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func TestCheckCatalog(t *testing.T) {
+	consumer := map[string]string{"max.poll.records": "many", "group.idd": "copy"}
+	shadowProducer := map[string]string{"lingr.ms": "5"}
+
+	function := newRenderFunction()
+	function.Spec.ConsumerConfig = &consumer
+	function.Spec.Shadow = &kfnv1alpha1.ShadowSpec{ProducerConfig: &shadowProducer}
+
+	err := validateCatalog(function)
+	if err == nil || !strings.Contains(err.Error(), `consumer property max.poll.records: invalid value "many"`) {
+		t.Errorf("the invalid value must be rejected, got %v", err)
+	}
+
+	expected := []string{
+		"unknown consumer property group.idd, did you mean group.id?",
+		"shadow unknown producer property lingr.ms, did you mean linger.ms?",
+	}
+	if warnings := ConfigWarnings(function); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("warnings = %q, want %q", warnings, expected)
+	}
+
+	// The custom properties are not checked
+	function.Annotations = map[string]string{kfn.CustomConfigAnnotation: "max.poll.records, group.*"}
+	if err := validateCatalog(function); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+	if warnings := ConfigWarnings(function); len(warnings) != 1 {
+		t.Errorf("warnings = %q, want only the one of the shadow", warnings)
+	}
+
+	function.Annotations[kfn.CustomConfigAnnotation] = "*"
+	if warnings := ConfigWarnings(function); len(warnings) != 0 {
+		t.Errorf("warnings = %q, want none", warnings)
+	}
+}
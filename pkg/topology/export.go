@@ -0,0 +1,120 @@
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats are the supported export formats.
+var Formats = []string{"dot", "mermaid", "json"}
+
+// Export exports the graph in the given format.
+func (g *Graph) Export(format string) (string, error) {
+	switch format {
+	case "dot":
+		return g.DOT(), nil
+	case "mermaid":
+		return g.Mermaid(), nil
+	case "json":
+		return g.JSON()
+	default:
+		return "", fmt.Errorf("unknown format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// DOT exports the graph in the Graphviz format. Dangling inputs are orange,
+// unconsumed outputs are grey and the Functions in a cycle are red. The
+// routes are dashed.
+func (g *Graph) DOT() string {
+	b := strings.Builder{}
+
+	b.WriteString("digraph kfn {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, t := range g.Topics {
+		attrs := []string{fmt.Sprintf("label=%q", t.Name)}
+		if t.Dangling {
+			attrs = append(attrs, "style=filled", "fillcolor=orange")
+		} else if t.Unconsumed {
+			attrs = append(attrs, "style=filled", "fillcolor=lightgrey")
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", t.Name, strings.Join(attrs, ", "))
+	}
+
+	for _, f := range g.Functions {
+		attrs := []string{fmt.Sprintf("label=%q", f.Key()+"\n(group: "+f.ConsumerGroup+")")}
+		if f.InCycle {
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", f.Input, f.Output, strings.Join(attrs, ", "))
+		for _, route := range f.Routes {
+			fmt.Fprintf(&b, "  %q -> %q [%s];\n", f.Input, route, strings.Join(append(attrs, "style=dashed"), ", "))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid exports the graph as a Mermaid flowchart. See DOT for the colors.
+func (g *Graph) Mermaid() string {
+	b := strings.Builder{}
+
+	b.WriteString("flowchart LR\n")
+
+	ids := make(map[string]string)
+	for i, t := range g.Topics {
+		ids[t.Name] = fmt.Sprintf("t%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[t.Name], mermaidEscape(t.Name))
+	}
+
+	// The links are numbered in order for linkStyle
+	link := 0
+	cycleLinks := []string{}
+	for _, f := range g.Functions {
+		label := mermaidEscape(f.Key() + " (group: " + f.ConsumerGroup + ")")
+		fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", ids[f.Input], label, ids[f.Output])
+		for _, route := range f.Routes {
+			fmt.Fprintf(&b, "  %s -.->|\"%s\"| %s\n", ids[f.Input], label, ids[route])
+		}
+		if f.InCycle {
+			for i := 0; i <= len(f.Routes); i++ {
+				cycleLinks = append(cycleLinks, fmt.Sprint(link+i))
+			}
+		}
+		link += 1 + len(f.Routes)
+	}
+
+	b.WriteString("  classDef dangling fill:orange\n")
+	b.WriteString("  classDef unconsumed fill:lightgrey\n")
+
+	for _, t := range g.Topics {
+		if t.Dangling {
+			fmt.Fprintf(&b, "  class %s dangling\n", ids[t.Name])
+		} else if t.Unconsumed {
+			fmt.Fprintf(&b, "  class %s unconsumed\n", ids[t.Name])
+		}
+	}
+
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(cycleLinks, ","))
+	}
+
+	return b.String()
+}
+
+// JSON exports the graph as JSON.
+func (g *Graph) JSON() (string, error) {
+	data, err := json.MarshalIndent(g, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func mermaidEscape(s string) string {
+	return strings.Replace(s, "\"", "#quot;", -1)
+}
@@ -0,0 +1,116 @@
+package topology
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func newTopologyFunction(name string, input string, output string, routes ...string) *kfnv1alpha1.Function {
+	function := &kfnv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: kfnv1alpha1.FunctionSpec{
+			Input:  input,
+			Output: output,
+		},
+	}
+	for _, route := range routes {
+		function.Spec.Routes = append(function.Spec.Routes, kfnv1alpha1.RouteSpec{Name: route, KeyPattern: route + "-.*", Output: route})
+	}
+	return function
+}
+
+func TestBuild(t *testing.T) {
+	group := map[string]string{"group.id": "custom"}
+	enrich := newTopologyFunction("enrich", "orders", "orders.enriched", "orders.eu")
+	enrich.Spec.ConsumerConfig = &group
+
+	graph := Build(&render.FunctionDefaultConfig{}, []*kfnv1alpha1.Function{
+		newTopologyFunction("parse", "raw", "orders"),
+		enrich,
+	})
+
+	if len(graph.Functions) != 2 || graph.Functions[0].Key() != "default/enrich" {
+		t.Fatalf("unexpected Functions %v", graph.Functions)
+	}
+	if graph.Functions[0].ConsumerGroup != "custom" || graph.Functions[1].ConsumerGroup != "parse" {
+		t.Errorf("consumer groups = %s, %s", graph.Functions[0].ConsumerGroup, graph.Functions[1].ConsumerGroup)
+	}
+	if !reflect.DeepEqual(graph.Functions[0].Routes, []string{"orders.eu"}) {
+		t.Errorf("routes = %v", graph.Functions[0].Routes)
+	}
+
+	if dangling := graph.DanglingInputs(); !reflect.DeepEqual(dangling, []string{"raw"}) {
+		t.Errorf("dangling inputs = %v, want [raw]", dangling)
+	}
+	if unconsumed := graph.UnconsumedOutputs(); !reflect.DeepEqual(unconsumed, []string{"orders.enriched", "orders.eu"}) {
+		t.Errorf("unconsumed outputs = %v", unconsumed)
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("cycles = %v, want none", graph.Cycles)
+	}
+}
+
+func TestExport(t *testing.T) {
+	graph := Build(&render.FunctionDefaultConfig{}, []*kfnv1alpha1.Function{
+		newTopologyFunction("parse", "raw", "orders", "orders.eu"),
+		newTopologyFunction("loop", "orders", "raw"),
+	})
+
+	dot, err := graph.Export("dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`"raw" -> "orders" [label="default/parse\n(group: parse)", color=red, fontcolor=red];`,
+		`"raw" -> "orders.eu" [label="default/parse\n(group: parse)", color=red, fontcolor=red, style=dashed];`,
+		`"orders.eu" [label="orders.eu", style=filled, fillcolor=lightgrey];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("DOT export does not contain %s:\n%s", line, dot)
+		}
+	}
+
+	mermaid, err := graph.Export("mermaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`t0 -->|"default/loop (group: loop)"| t2`,
+		`t2 -.->|"default/parse (group: parse)"| t1`,
+		`class t1 unconsumed`,
+		`linkStyle 0,1,2 stroke:red`,
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("Mermaid export does not contain %s:\n%s", line, mermaid)
+		}
+	}
+
+	data, err := graph.Export("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Graph{}
+	if err := json.Unmarshal([]byte(data), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, graph) {
+		t.Errorf("the JSON export must decode to the graph:\n%s", data)
+	}
+
+	if _, err := graph.Export("svg"); err == nil {
+		t.Errorf("an unknown format must be rejected")
+	}
+}
+
+func TestMermaidEscape(t *testing.T) {
+	if got := mermaidEscape(`a "quoted" topic`); got != "a #quot;quoted#quot; topic" {
+		t.Errorf("mermaidEscape = %s", got)
+	}
+}
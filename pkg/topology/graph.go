@@ -0,0 +1,261 @@
+// Package topology builds the graph of the topics connected by Functions.
+package topology
+
+import (
+	"sort"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+// Topic is a node of the graph.
+type Topic struct {
+	Name string `json:"name"`
+
+	// Dangling is true when the topic is consumed but no Function
+	// produces to it.
+	Dangling bool `json:"dangling,omitempty"`
+
+	// Unconsumed is true when a Function produces to the topic but no
+	// Function consumes it.
+	Unconsumed bool `json:"unconsumed,omitempty"`
+}
+
+// Function is an edge of the graph from its input topic to its output topic.
+type Function struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	Input         string `json:"input"`
+	Output        string `json:"output"`
+	ConsumerGroup string `json:"consumerGroup"`
+
+	// Routes are the other topics the Function produces to, with
+	// content-based routing.
+	Routes []string `json:"routes,omitempty"`
+
+	// InCycle is true when the output of the Function eventually feeds
+	// its input.
+	InCycle bool `json:"inCycle,omitempty"`
+}
+
+// Outputs returns the output and the routes of the Function.
+func (f *Function) Outputs() []string {
+	return append([]string{f.Output}, f.Routes...)
+}
+
+// Key returns the namespace/name of the Function.
+func (f *Function) Key() string {
+	return f.Namespace + "/" + f.Name
+}
+
+// Graph is the graph of the topics connected by Functions.
+type Graph struct {
+	Topics    []*Topic    `json:"topics"`
+	Functions []*Function `json:"functions"`
+
+	// Cycles are the sets of Functions, by namespace/name, which feed
+	// each other.
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+// Build builds the graph of the Functions. The default configuration is
+// used to resolve the consumer groups.
+func Build(defaultConfig *render.FunctionDefaultConfig, functions []*kfnv1alpha1.Function) *Graph {
+	return build(functions, func(function *kfnv1alpha1.Function) string {
+		return render.NewFunctionConfig(defaultConfig, function).Consumer["group.id"]
+	})
+}
+
+// Cycle returns the Functions, by namespace/name, of the cycle the Function
+// is part of, see Graph.Cycle. Only the topics of the Functions are read:
+// unlike Build, it does not render the Functions to resolve their consumer
+// groups.
+func Cycle(functions []*kfnv1alpha1.Function, key string) []string {
+	return build(functions, nil).Cycle(key)
+}
+
+// build builds the graph of the Functions. The consumer groups are left
+// empty when consumerGroup is nil.
+func build(functions []*kfnv1alpha1.Function, consumerGroup func(*kfnv1alpha1.Function) string) *Graph {
+	graph := &Graph{
+		Topics:    []*Topic{},
+		Functions: []*Function{},
+	}
+
+	topics := make(map[string]*Topic)
+	produced := make(map[string]bool)
+	consumed := make(map[string]bool)
+
+	topic := func(name string) {
+		if _, ok := topics[name]; !ok {
+			topics[name] = &Topic{Name: name}
+		}
+	}
+
+	for _, function := range functions {
+		edge := &Function{
+			Namespace: function.Namespace,
+			Name:      function.Name,
+			Input:     function.Spec.Input,
+			Output:    function.Spec.Output,
+		}
+		if consumerGroup != nil {
+			edge.ConsumerGroup = consumerGroup(function)
+		}
+
+		outputs := render.Outputs(function)
+		if len(outputs) > 1 {
+			edge.Routes = outputs[1:]
+		}
+
+		topic(edge.Input)
+		consumed[edge.Input] = true
+		for _, output := range edge.Outputs() {
+			topic(output)
+			produced[output] = true
+		}
+
+		graph.Functions = append(graph.Functions, edge)
+	}
+
+	for name, t := range topics {
+		t.Dangling = consumed[name] && !produced[name]
+		t.Unconsumed = produced[name] && !consumed[name]
+		graph.Topics = append(graph.Topics, t)
+	}
+
+	sort.Slice(graph.Topics, func(i, j int) bool {
+		return graph.Topics[i].Name < graph.Topics[j].Name
+	})
+
+	sort.Slice(graph.Functions, func(i, j int) bool {
+		return graph.Functions[i].Key() < graph.Functions[j].Key()
+	})
+
+	graph.findCycles()
+
+	return graph
+}
+
+// findCycles finds the strongly connected components of the topics with
+// Tarjan's algorithm. The Functions connecting two topics of the same
+// component are part of a cycle.
+func (g *Graph) findCycles() {
+	edges := make(map[string][]*Function)
+	for _, f := range g.Functions {
+		edges[f.Input] = append(edges[f.Input], f)
+	}
+
+	index := 0
+	indexes := make(map[string]int)
+	lowlinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := []string{}
+	components := make(map[string]int)
+	component := 0
+
+	var connect func(topic string)
+	connect = func(topic string) {
+		indexes[topic] = index
+		lowlinks[topic] = index
+		index++
+		stack = append(stack, topic)
+		onStack[topic] = true
+
+		for _, f := range edges[topic] {
+			for _, output := range f.Outputs() {
+				if _, ok := indexes[output]; !ok {
+					connect(output)
+					lowlinks[topic] = minInt(lowlinks[topic], lowlinks[output])
+				} else if onStack[output] {
+					lowlinks[topic] = minInt(lowlinks[topic], indexes[output])
+				}
+			}
+		}
+
+		if lowlinks[topic] == indexes[topic] {
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[last] = false
+				components[last] = component
+				if last == topic {
+					break
+				}
+			}
+			component++
+		}
+	}
+
+	for _, t := range g.Topics {
+		if _, ok := indexes[t.Name]; !ok {
+			connect(t.Name)
+		}
+	}
+
+	cycles := make(map[int][]string)
+	for _, f := range g.Functions {
+		for _, output := range f.Outputs() {
+			if components[f.Input] == components[output] {
+				f.InCycle = true
+				cycles[components[f.Input]] = append(cycles[components[f.Input]], f.Key())
+				break
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(cycles))
+	for id := range cycles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	g.Cycles = nil
+	for _, id := range ids {
+		g.Cycles = append(g.Cycles, cycles[id])
+	}
+}
+
+// Cycle returns the Functions, by namespace/name, of the cycle the Function
+// is part of. It returns nil if the Function is not part of a cycle.
+func (g *Graph) Cycle(key string) []string {
+	for _, cycle := range g.Cycles {
+		for _, k := range cycle {
+			if k == key {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// DanglingInputs returns the topics which are consumed but not produced
+// by any Function.
+func (g *Graph) DanglingInputs() []string {
+	result := []string{}
+	for _, t := range g.Topics {
+		if t.Dangling {
+			result = append(result, t.Name)
+		}
+	}
+	return result
+}
+
+// UnconsumedOutputs returns the topics which are produced but not consumed
+// by any Function.
+func (g *Graph) UnconsumedOutputs() []string {
+	result := []string{}
+	for _, t := range g.Topics {
+		if t.Unconsumed {
+			result = append(result, t.Name)
+		}
+	}
+	return result
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
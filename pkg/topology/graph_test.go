@@ -0,0 +1,79 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions []*kfnv1alpha1.Function
+		cycles    [][]string
+	}{
+		{
+			"chain",
+			[]*kfnv1alpha1.Function{
+				newTopologyFunction("a", "t1", "t2"),
+				newTopologyFunction("b", "t2", "t3"),
+			},
+			nil,
+		},
+		{
+			"self loop",
+			[]*kfnv1alpha1.Function{
+				newTopologyFunction("a", "t1", "t1"),
+			},
+			[][]string{{"default/a"}},
+		},
+		{
+			"loop",
+			[]*kfnv1alpha1.Function{
+				newTopologyFunction("a", "t1", "t2"),
+				newTopologyFunction("b", "t2", "t3"),
+				newTopologyFunction("c", "t3", "t1"),
+				newTopologyFunction("d", "t3", "t4"),
+			},
+			[][]string{{"default/a", "default/b", "default/c"}},
+		},
+		{
+			"route",
+			[]*kfnv1alpha1.Function{
+				newTopologyFunction("a", "t1", "t2", "retry"),
+				newTopologyFunction("b", "retry", "t1"),
+			},
+			[][]string{{"default/a", "default/b"}},
+		},
+		{
+			"two loops",
+			[]*kfnv1alpha1.Function{
+				newTopologyFunction("a", "t1", "t2"),
+				newTopologyFunction("b", "t2", "t1"),
+				newTopologyFunction("c", "t3", "t4"),
+				newTopologyFunction("d", "t4", "t3"),
+			},
+			[][]string{{"default/a", "default/b"}, {"default/c", "default/d"}},
+		},
+	}
+
+	for _, test := range tests {
+		graph := Build(&render.FunctionDefaultConfig{}, test.functions)
+		if !reflect.DeepEqual(graph.Cycles, test.cycles) {
+			t.Errorf("%s: cycles = %v, want %v", test.name, graph.Cycles, test.cycles)
+		}
+
+		for _, f := range graph.Functions {
+			if inCycle := graph.Cycle(f.Key()) != nil; f.InCycle != inCycle {
+				t.Errorf("%s: %s InCycle = %v, want %v", test.name, f.Key(), f.InCycle, inCycle)
+			}
+
+			// The cycles are found without rendering the Functions
+			if cycle := Cycle(test.functions, f.Key()); !reflect.DeepEqual(cycle, graph.Cycle(f.Key())) {
+				t.Errorf("%s: cycle of %s = %v, want %v", test.name, f.Key(), cycle, graph.Cycle(f.Key()))
+			}
+		}
+	}
+}
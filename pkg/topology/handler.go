@@ -0,0 +1,43 @@
+package topology
+
+import (
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+var contentTypes = map[string]string{
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"mermaid": "text/plain; charset=utf-8",
+	"json":    "application/json",
+}
+
+// NewHandler returns an HTTP handler exporting the graph of all the
+// Functions. The format is selected with the format query parameter and
+// defaults to json.
+func NewHandler(functionLister listers.FunctionLister, defaultConfig *render.FunctionDefaultConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+
+		functions, err := functionLister.List(labels.Everything())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		output, err := Build(defaultConfig, functions).Export(format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Write([]byte(output))
+	})
+}
@@ -0,0 +1,48 @@
+package topology
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+
+	listers "github.com/dajac/kfn/pkg/client/listers/kfn/v1alpha1"
+	"github.com/dajac/kfn/pkg/render"
+)
+
+func TestHandler(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(newTopologyFunction("parse", "raw", "orders")); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewHandler(listers.NewFunctionLister(indexer), &render.FunctionDefaultConfig{})
+
+	tests := []struct {
+		query       string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"", http.StatusOK, "application/json", `"name": "parse"`},
+		{"?format=dot", http.StatusOK, "text/vnd.graphviz; charset=utf-8", `"raw" -> "orders"`},
+		{"?format=mermaid", http.StatusOK, "text/plain; charset=utf-8", "flowchart LR"},
+		{"?format=svg", http.StatusBadRequest, "", "svg"},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/topology"+test.query, nil))
+
+		if recorder.Code != test.code {
+			t.Errorf("%q: status = %d, want %d", test.query, recorder.Code, test.code)
+		}
+		if test.contentType != "" && recorder.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("%q: content type = %s, want %s", test.query, recorder.Header().Get("Content-Type"), test.contentType)
+		}
+		if !strings.Contains(recorder.Body.String(), test.body) {
+			t.Errorf("%q: body does not contain %s:\n%s", test.query, test.body, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package webhook implements the validating admission webhook of the
+// Functions. It rejects the Functions which the controller would not apply
+// so the errors are reported by kubectl instead of the status.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+	"github.com/golang/glog"
+)
+
+// Validator returns an error when the Function must be rejected.
+type Validator func(function *kfnv1alpha1.Function) error
+
+// NewHandler returns an HTTP handler reviewing the admission of the
+// Functions with the validator.
+func NewHandler(validate Validator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = reviewFunction(review.Request, validate)
+		review.Request = nil
+
+		output, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(output)
+	})
+}
+
+func reviewFunction(request *admissionv1beta1.AdmissionRequest, validate Validator) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+
+	if request.Operation == admissionv1beta1.Delete {
+		return response
+	}
+
+	function := &kfnv1alpha1.Function{}
+	if err := json.Unmarshal(request.Object.Raw, function); err != nil {
+		return deny(response, fmt.Sprintf("invalid Function: %s", err.Error()))
+	}
+
+	if function.Namespace == "" {
+		function.Namespace = request.Namespace
+	}
+
+	if err := validate(function); err != nil {
+		glog.Infof("Reject Function %s/%s: %s", function.Namespace, function.Name, err.Error())
+		return deny(response, err.Error())
+	}
+
+	return response
+}
+
+func deny(response *admissionv1beta1.AdmissionResponse, message string) *admissionv1beta1.AdmissionResponse {
+	response.Allowed = false
+	response.Result = &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  metav1.StatusReasonInvalid,
+		Message: message,
+	}
+	return response
+}
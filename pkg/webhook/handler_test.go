@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	kfnv1alpha1 "github.com/dajac/kfn/pkg/apis/kfn/v1alpha1"
+)
+
+func review(t *testing.T, handler http.Handler, request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	body, err := json.Marshal(admissionv1beta1.AdmissionReview{Request: request})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	result := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Request != nil {
+		t.Errorf("the request must not be sent back")
+	}
+	return result.Response
+}
+
+func TestHandler(t *testing.T) {
+	validated := []*kfnv1alpha1.Function{}
+	handler := NewHandler(func(function *kfnv1alpha1.Function) error {
+		validated = append(validated, function)
+		if function.Spec.Replicas < 0 {
+			return fmt.Errorf("invalid replicas %d", function.Spec.Replicas)
+		}
+		return nil
+	})
+
+	function := &kfnv1alpha1.Function{Spec: kfnv1alpha1.FunctionSpec{Replicas: 1}}
+	function.Name = "copy"
+	raw, _ := json.Marshal(function)
+
+	response := review(t, handler, &admissionv1beta1.AdmissionRequest{
+		UID:       types.UID("uid"),
+		Namespace: "default",
+		Operation: admissionv1beta1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	})
+	if !response.Allowed || response.UID != "uid" {
+		t.Errorf("the Function must be allowed, got %+v", response)
+	}
+	if len(validated) != 1 || validated[0].Namespace != "default" {
+		t.Errorf("the Function must be validated in the namespace of the request")
+	}
+
+	function.Spec.Replicas = -1
+	raw, _ = json.Marshal(function)
+	response = review(t, handler, &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Update,
+		Object:    runtime.RawExtension{Raw: raw},
+	})
+	if response.Allowed || response.Result.Message != "invalid replicas -1" {
+		t.Errorf("the Function must be denied, got %+v", response)
+	}
+
+	// The deletions are always allowed
+	response = review(t, handler, &admissionv1beta1.AdmissionRequest{Operation: admissionv1beta1.Delete})
+	if !response.Allowed || len(validated) != 2 {
+		t.Errorf("the deletion must be allowed without validation")
+	}
+
+	response = review(t, handler, &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(`{"spec": {"replicas": "one"}}`)},
+	})
+	if response.Allowed {
+		t.Errorf("an invalid Function must be denied")
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader([]byte("{}"))))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("a review without request must be rejected, got %d", recorder.Code)
+	}
+}